@@ -0,0 +1,178 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+// Package auth provides the methods by which the server determines the user
+// making a request.
+package auth
+
+import (
+	"cmp"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/wtsi-hgi/backup-plans/users"
+)
+
+// Authentication methods.
+const (
+	MethodCookie = "cookie"
+	MethodJWT    = "jwt"
+	MethodHeader = "header"
+)
+
+const (
+	defaultCookie = "nginxauth"
+	defaultClaim  = "sub"
+	defaultHeader = "X-Remote-User"
+)
+
+var ErrUnknownMethod = errors.New("unknown authentication method")
+
+// Config selects and configures the method used to authenticate users.
+//
+// Method is one of "cookie" (the default), "jwt" or "header".
+//
+// The cookie method reads the username from a base64 encoded "user:password"
+// cookie, named by Cookie (default "nginxauth"), set by an authenticating
+// reverse proxy.
+//
+// The jwt method validates a JWT, such as an OIDC ID token, given as a Bearer
+// token in the Authorization header, or in the cookie named by Cookie if set.
+// The token must be signed by a key in the JWKS file, must not have expired,
+// and must have the given Issuer and Audience, if set. The username is taken
+// from the named Claim (default "sub").
+//
+// The header method reads the username from the named Header (default
+// "X-Remote-User"), but only for requests from the IPs or CIDR ranges in
+// TrustedProxies.
+//
+// For the jwt and header methods, the user must be known to the system (see
+// users.GetIDs); if StripDomain is set, anything from an '@' is first removed
+// from the username, allowing email addresses to be used.
+//
+// On logout, the user is redirected to LogoutURL, defaulting to "/".
+type Config struct {
+	Method         string
+	Cookie         string
+	JWKS           string
+	Issuer         string
+	Audience       string
+	Claim          string
+	Header         string
+	TrustedProxies []string
+	StripDomain    bool
+	LogoutURL      string
+}
+
+// Authenticator determines the user making a request.
+type Authenticator interface {
+	// User returns the username of the user making the request, or an empty
+	// string if the user cannot be authenticated.
+	User(r *http.Request) string
+
+	// Logout removes any stored credentials and redirects the user.
+	Logout(w http.ResponseWriter, r *http.Request)
+}
+
+// New returns an Authenticator for the configured method.
+func New(c Config) (Authenticator, error) {
+	switch c.Method {
+	case "", MethodCookie:
+		return &Cookie{
+			Name:      cmp.Or(c.Cookie, defaultCookie),
+			LogoutURL: c.LogoutURL,
+		}, nil
+	case MethodJWT:
+		return NewJWT(c)
+	case MethodHeader:
+		return NewHeader(c)
+	}
+
+	return nil, ErrUnknownMethod
+}
+
+// Cookie authenticates users from a base64 encoded "user:password" cookie, as
+// set by an authenticating reverse proxy.
+type Cookie struct {
+	Name      string
+	LogoutURL string
+}
+
+// User returns the username from the cookie.
+func (c *Cookie) User(r *http.Request) string {
+	cookie, err := r.Cookie(c.Name)
+	if err != nil {
+		return ""
+	}
+
+	data, err := base64.StdEncoding.DecodeString(cookie.Value)
+	if err != nil {
+		return ""
+	}
+
+	user, _, _ := strings.Cut(string(data), ":")
+
+	return user
+}
+
+// Logout expires the cookie.
+func (c *Cookie) Logout(w http.ResponseWriter, r *http.Request) {
+	expireCookie(w, c.Name)
+	redirect(w, r, c.LogoutURL)
+}
+
+func expireCookie(w http.ResponseWriter, name string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Path:     "/",
+		Expires:  time.Unix(0, 0),
+		HttpOnly: true,
+	})
+}
+
+func redirect(w http.ResponseWriter, r *http.Request, url string) {
+	http.Redirect(w, r, cmp.Or(url, "/"), http.StatusFound)
+}
+
+// username maps an identity from a token or header to a username known to the
+// system, returning an empty string if there is no such user.
+func username(identity string, stripDomain bool) string {
+	if stripDomain {
+		identity, _, _ = strings.Cut(identity, "@")
+	}
+
+	if identity == "" {
+		return ""
+	}
+
+	if _, groups := users.GetIDs(identity); groups == nil {
+		return ""
+	}
+
+	return identity
+}
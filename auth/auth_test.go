@@ -0,0 +1,257 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/user"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCookie(t *testing.T) {
+	Convey("The cookie method reads the username from the nginxauth cookie", t, func() {
+		a, err := New(Config{})
+		So(err, ShouldBeNil)
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		So(a.User(r), ShouldEqual, "")
+
+		r.AddCookie(&http.Cookie{Name: "nginxauth", Value: base64.StdEncoding.EncodeToString([]byte("userA:pass"))})
+		So(a.User(r), ShouldEqual, "userA")
+
+		w := httptest.NewRecorder()
+
+		a.Logout(w, r)
+		So(w.Code, ShouldEqual, http.StatusFound)
+		So(w.Header().Get("Set-Cookie"), ShouldStartWith, "nginxauth=;")
+
+		_, err = New(Config{Method: "unknown"})
+		So(err, ShouldEqual, ErrUnknownMethod)
+	})
+}
+
+func TestJWT(t *testing.T) {
+	u, err := user.Current()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Convey("Given a JWKS file containing RSA, EC and Ed25519 keys", t, func() {
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		So(err, ShouldBeNil)
+
+		ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		So(err, ShouldBeNil)
+
+		edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+		So(err, ShouldBeNil)
+
+		ecPoint, err := ecKey.PublicKey.Bytes()
+		So(err, ShouldBeNil)
+
+		jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+
+		writeJWKS(t, jwksFile, map[string]string{
+			"kty": "RSA", "kid": "rsa", "use": "sig",
+			"n": encode(rsaKey.N.Bytes()), "e": encode(big.NewInt(int64(rsaKey.E)).Bytes()),
+		}, map[string]string{
+			"kty": "EC", "kid": "ec", "crv": "P-256", "x": encode(ecPoint[1:33]), "y": encode(ecPoint[33:]),
+		}, map[string]string{
+			"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": encode(edPub),
+		}, map[string]string{
+			"kty": "oct", "kid": "hmac", "k": encode([]byte("secret")),
+		})
+
+		a, err := New(Config{
+			Method:      MethodJWT,
+			JWKS:        jwksFile,
+			Issuer:      "https://issuer",
+			Audience:    "backup-plans",
+			Claim:       "email",
+			Cookie:      "token",
+			StripDomain: true,
+		})
+		So(err, ShouldBeNil)
+
+		claims := func() jwt.MapClaims {
+			return jwt.MapClaims{
+				"iss":   "https://issuer",
+				"aud":   "backup-plans",
+				"exp":   time.Now().Add(time.Hour).Unix(),
+				"email": u.Username + "@example.com",
+			}
+		}
+
+		sign := func(method jwt.SigningMethod, kid string, key crypto.Signer, claims jwt.MapClaims) string {
+			token := jwt.NewWithClaims(method, claims)
+			token.Header["kid"] = kid
+
+			signed, err := token.SignedString(key)
+			So(err, ShouldBeNil)
+
+			return signed
+		}
+
+		request := func(token string) *http.Request {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Authorization", "Bearer "+token)
+
+			return r
+		}
+
+		Convey("Tokens signed by any of the keys are accepted", func() {
+			So(a.User(request(sign(jwt.SigningMethodRS256, "rsa", rsaKey, claims()))), ShouldEqual, u.Username)
+			So(a.User(request(sign(jwt.SigningMethodES256, "ec", ecKey, claims()))), ShouldEqual, u.Username)
+			So(a.User(request(sign(jwt.SigningMethodEdDSA, "ed", edKey, claims()))), ShouldEqual, u.Username)
+			So(a.User(request(sign(jwt.SigningMethodPS256, "", rsaKey, claims()))), ShouldEqual, u.Username)
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.AddCookie(&http.Cookie{Name: "token", Value: sign(jwt.SigningMethodES256, "ec", ecKey, claims())})
+
+			So(a.User(r), ShouldEqual, u.Username)
+		})
+
+		Convey("Invalid tokens are rejected", func() {
+			otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+			So(err, ShouldBeNil)
+
+			So(a.User(request(sign(jwt.SigningMethodRS256, "rsa", otherKey, claims()))), ShouldEqual, "")
+			So(a.User(request(sign(jwt.SigningMethodRS256, "ec", rsaKey, claims()))), ShouldEqual, "")
+
+			hmac, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims()).SignedString([]byte("secret"))
+			So(err, ShouldBeNil)
+			So(a.User(request(hmac)), ShouldEqual, "")
+
+			for key, value := range map[string]any{
+				"iss":   "https://other",
+				"aud":   "other",
+				"exp":   time.Now().Add(-time.Hour).Unix(),
+				"email": "not-a-real-user@example.com",
+			} {
+				c := claims()
+				c[key] = value
+
+				So(a.User(request(sign(jwt.SigningMethodRS256, "rsa", rsaKey, c))), ShouldEqual, "")
+			}
+
+			c := claims()
+			delete(c, "exp")
+
+			So(a.User(request(sign(jwt.SigningMethodRS256, "rsa", rsaKey, c))), ShouldEqual, "")
+		})
+
+		Convey("The JWKS file is reloaded when it changes", func() {
+			token := sign(jwt.SigningMethodES256, "ec", ecKey, claims())
+
+			writeJWKS(t, jwksFile, map[string]string{
+				"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": encode(edPub),
+			})
+			So(os.Chtimes(jwksFile, time.Now(), time.Now().Add(time.Minute)), ShouldBeNil)
+
+			So(a.User(request(token)), ShouldEqual, "")
+			So(a.User(request(sign(jwt.SigningMethodEdDSA, "ed", edKey, claims()))), ShouldEqual, u.Username)
+		})
+
+		Convey("A JWKS file is required", func() {
+			_, err := New(Config{Method: MethodJWT})
+			So(err, ShouldEqual, ErrNoJWKS)
+
+			writeJWKS(t, jwksFile)
+
+			_, err = New(Config{Method: MethodJWT, JWKS: jwksFile})
+			So(err, ShouldEqual, ErrNoKeys)
+		})
+	})
+}
+
+func TestHeader(t *testing.T) {
+	u, err := user.Current()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Convey("The header method only trusts the header from the allowed IPs", t, func() {
+		_, err := New(Config{Method: MethodHeader})
+		So(err, ShouldEqual, ErrNoTrustedProxies)
+
+		_, err = New(Config{Method: MethodHeader, TrustedProxies: []string{"not-an-ip"}})
+		So(err, ShouldWrap, ErrInvalidProxy)
+
+		a, err := New(Config{Method: MethodHeader, TrustedProxies: []string{"10.0.0.0/8", "::1"}})
+		So(err, ShouldBeNil)
+
+		for remote, expected := range map[string]string{
+			"10.1.2.3:1234":          u.Username,
+			"[::ffff:10.1.2.3]:1234": u.Username,
+			"[::1]:1234":             u.Username,
+			"192.168.0.1:1234":       "",
+			"[::2]:1234":             "",
+		} {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = remote
+			r.Header.Set("X-Remote-User", u.Username)
+
+			So(a.User(r), ShouldEqual, expected)
+
+			r.Header.Set("X-Remote-User", "not-a-real-user")
+
+			So(a.User(r), ShouldEqual, "")
+		}
+	})
+}
+
+func encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func writeJWKS(t *testing.T, path string, keys ...map[string]string) {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,128 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package auth
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+var (
+	ErrNoTrustedProxies = errors.New("no trusted proxies specified")
+	ErrInvalidProxy     = errors.New("invalid trusted proxy")
+)
+
+// Header authenticates users from a header set by a trusted reverse proxy.
+type Header struct {
+	header      string
+	trusted     []netip.Prefix
+	stripDomain bool
+	logoutURL   string
+}
+
+// NewHeader returns a Header authenticator using the header, trustedproxies,
+// stripdomain and logouturl settings of the given Config.
+//
+// Each trusted proxy can be either an IP address or a CIDR range.
+func NewHeader(c Config) (*Header, error) {
+	if len(c.TrustedProxies) == 0 {
+		return nil, ErrNoTrustedProxies
+	}
+
+	trusted := make([]netip.Prefix, len(c.TrustedProxies))
+
+	for n, proxy := range c.TrustedProxies {
+		prefix, err := parseProxy(proxy)
+		if err != nil {
+			return nil, err
+		}
+
+		trusted[n] = prefix
+	}
+
+	return &Header{
+		header:      cmp.Or(c.Header, defaultHeader),
+		trusted:     trusted,
+		stripDomain: c.StripDomain,
+		logoutURL:   c.LogoutURL,
+	}, nil
+}
+
+func parseProxy(proxy string) (netip.Prefix, error) {
+	if strings.Contains(proxy, "/") {
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return prefix, fmt.Errorf("%w: %w", ErrInvalidProxy, err)
+		}
+
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(proxy)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("%w: %w", ErrInvalidProxy, err)
+	}
+
+	addr = addr.Unmap()
+
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// User returns the username from the header, if the request came from a
+// trusted proxy.
+func (h *Header) User(r *http.Request) string {
+	if !h.isTrusted(r.RemoteAddr) {
+		return ""
+	}
+
+	return username(r.Header.Get(h.header), h.stripDomain)
+}
+
+func (h *Header) isTrusted(remoteAddr string) bool {
+	addrPort, err := netip.ParseAddrPort(remoteAddr)
+	if err != nil {
+		return false
+	}
+
+	addr := addrPort.Addr().Unmap()
+
+	for _, prefix := range h.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Logout redirects the user, as the credentials are held by the proxy.
+func (h *Header) Logout(w http.ResponseWriter, r *http.Request) {
+	redirect(w, r, h.logoutURL)
+}
@@ -0,0 +1,391 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package auth
+
+import (
+	"cmp"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var (
+	ErrNoJWKS       = errors.New("no JWKS file specified")
+	ErrNoKeys       = errors.New("no usable keys in JWKS")
+	ErrInvalidKey   = errors.New("invalid key")
+	ErrNoMatchedKey = errors.New("no key matches token")
+	ErrInvalidToken = errors.New("invalid token claims")
+)
+
+// validMethods are the signing algorithms accepted; symmetric algorithms are
+// excluded so that a public key cannot be used as an HMAC secret.
+var validMethods = []string{ //nolint:gochecknoglobals
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+// JWT authenticates users with a signed JWT, such as an OIDC ID token.
+type JWT struct {
+	keys        *keySet
+	issuer      string
+	audience    string
+	claim       string
+	cookie      string
+	stripDomain bool
+	logoutURL   string
+	parser      *jwt.Parser
+}
+
+// NewJWT returns a JWT authenticator using the jwks, issuer, audience, claim,
+// cookie, stripdomain and logouturl settings of the given Config.
+//
+// The JWKS file is reloaded when its modification time changes.
+func NewJWT(c Config) (*JWT, error) {
+	if c.JWKS == "" {
+		return nil, ErrNoJWKS
+	}
+
+	keys := &keySet{path: c.JWKS}
+
+	if _, err := keys.get(); err != nil {
+		return nil, err
+	}
+
+	return &JWT{
+		keys:        keys,
+		issuer:      c.Issuer,
+		audience:    c.Audience,
+		claim:       cmp.Or(c.Claim, defaultClaim),
+		cookie:      c.Cookie,
+		stripDomain: c.StripDomain,
+		logoutURL:   c.LogoutURL,
+		parser:      jwt.NewParser(jwt.WithValidMethods(validMethods)),
+	}, nil
+}
+
+// User returns the username from the claims of a valid token.
+func (j *JWT) User(r *http.Request) string {
+	token := j.token(r)
+	if token == "" {
+		return ""
+	}
+
+	claims, err := j.Verify(token)
+	if err != nil {
+		return ""
+	}
+
+	identity, _ := claims[j.claim].(string) //nolint:errcheck
+
+	return username(identity, j.stripDomain)
+}
+
+func (j *JWT) token(r *http.Request) string {
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(bearer)
+	}
+
+	if j.cookie == "" {
+		return ""
+	}
+
+	cookie, err := r.Cookie(j.cookie)
+	if err != nil {
+		return ""
+	}
+
+	return cookie.Value
+}
+
+// Verify checks the signature, expiry, issuer and audience of the given token,
+// returning its claims.
+func (j *JWT) Verify(token string) (jwt.MapClaims, error) {
+	keys, err := j.keys.get()
+	if err != nil {
+		return nil, err
+	}
+
+	unverified, _, err := j.parser.ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		return nil, err
+	}
+
+	kid, _ := unverified.Header["kid"].(string) //nolint:errcheck
+	err = ErrNoMatchedKey
+
+	for _, key := range keys {
+		if kid != "" && key.id != kid {
+			continue
+		}
+
+		claims := jwt.MapClaims{}
+
+		if _, err = j.parser.ParseWithClaims(token, claims, key.keyFunc); err == nil {
+			return claims, j.checkClaims(claims)
+		}
+	}
+
+	return nil, err
+}
+
+func (j *JWT) checkClaims(claims jwt.MapClaims) error {
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return fmt.Errorf("%w: missing or expired exp", ErrInvalidToken)
+	}
+
+	if j.issuer != "" && !claims.VerifyIssuer(j.issuer, true) {
+		return fmt.Errorf("%w: wrong issuer", ErrInvalidToken)
+	}
+
+	if j.audience != "" && !claims.VerifyAudience(j.audience, true) {
+		return fmt.Errorf("%w: wrong audience", ErrInvalidToken)
+	}
+
+	return nil
+}
+
+// Logout expires the token cookie, if one is used.
+func (j *JWT) Logout(w http.ResponseWriter, r *http.Request) {
+	if j.cookie != "" {
+		expireCookie(w, j.cookie)
+	}
+
+	redirect(w, r, j.logoutURL)
+}
+
+type key struct {
+	id  string
+	key any
+}
+
+func (k key) keyFunc(*jwt.Token) (any, error) {
+	return k.key, nil
+}
+
+// keySet is a set of keys read from a JWKS file, which is reloaded when the
+// modification time of the file changes.
+type keySet struct {
+	path string
+
+	mu    sync.Mutex
+	mtime time.Time
+	keys  []key
+}
+
+func (k *keySet) get() ([]key, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	fi, err := os.Stat(k.path)
+	if err != nil {
+		return k.stale(err)
+	}
+
+	if k.keys != nil && fi.ModTime().Equal(k.mtime) {
+		return k.keys, nil
+	}
+
+	data, err := os.ReadFile(k.path)
+	if err != nil {
+		return k.stale(err)
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return k.stale(err)
+	}
+
+	k.keys = keys
+	k.mtime = fi.ModTime()
+
+	return keys, nil
+}
+
+// stale returns the previously loaded keys, if there are any, when the file can
+// no longer be read.
+func (k *keySet) stale(err error) ([]key, error) {
+	if k.keys == nil {
+		return nil, err
+	}
+
+	slog.Warn("error reloading JWKS, using previous keys", "path", k.path, "err", err)
+
+	return k.keys, nil
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS parses the public signing keys from a JSON Web Key Set, ignoring
+// keys of unsupported types.
+func parseJWKS(data []byte) ([]key, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	var keys []key
+
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		pub, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+		} else if pub != nil {
+			keys = append(keys, key{id: k.Kid, key: pub})
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	return keys, nil
+}
+
+func (k *jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		return k.rsa()
+	case "EC":
+		return k.ecdsa()
+	case "OKP":
+		return k.ed25519()
+	}
+
+	return nil, nil //nolint:nilnil
+}
+
+func (k *jwk) rsa() (any, error) {
+	n, err := decodeInt(k.N)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := decodeInt(k.E)
+	if err != nil {
+		return nil, err
+	}
+
+	if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 { //nolint:mnd
+		return nil, ErrInvalidKey
+	}
+
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func (k *jwk) ecdsa() (any, error) {
+	var curve elliptic.Curve
+
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, nil //nolint:nilnil
+	}
+
+	size := (curve.Params().BitSize + 7) / 8 //nolint:mnd
+
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, err
+	}
+
+	y, err := base64.RawURLEncoding.DecodeString(k.Y)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(x) != size || len(y) != size {
+		return nil, ErrInvalidKey
+	}
+
+	return ecdsa.ParseUncompressedPublicKey(curve, append(append([]byte{4}, x...), y...))
+}
+
+func (k *jwk) ed25519() (any, error) {
+	if k.Crv != "Ed25519" {
+		return nil, nil //nolint:nilnil
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(x) != ed25519.PublicKeySize {
+		return nil, ErrInvalidKey
+	}
+
+	return ed25519.PublicKey(x), nil
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) == 0 {
+		return nil, ErrInvalidKey
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,385 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package backend
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/wtsi-hgi/backup-plans/db"
+	"github.com/wtsi-hgi/backup-plans/users"
+)
+
+var (
+	ErrNotAdmin = Error{
+		Code: http.StatusForbidden,
+		Err:  errors.New("not an admin"), //nolint:err113
+	}
+	ErrNoNotifyURL = Error{
+		Code: http.StatusNotImplemented,
+		Err:  errors.New("no notification url configured"), //nolint:err113
+	}
+)
+
+// Admin actions, as recorded in the audit log.
+const (
+	AdminReassign   = "reassign"
+	AdminRemoveRule = "removerule"
+	AdminUnfreeze   = "unfreeze"
+	AdminRevoke     = "revoke"
+	AdminBulkRules  = "bulkrules"
+)
+
+const notifyTimeout = 10 * time.Second
+
+// AdminReassign is an HTTP endpoint that allows a member of the admin group to
+// pass the claim of any directory to another user, regardless of whether that
+// user could claim it themselves.
+//
+// The directory is taken from the 'dir' GET param and the new claimant from the
+// 'to' GET param. If the 'notify' GET param is "true", the previous claimant
+// will be notified.
+func (s *Server) AdminReassign(w http.ResponseWriter, r *http.Request) {
+	handle(w, r, s.adminReassign)
+}
+
+func (s *Server) adminReassign(w http.ResponseWriter, r *http.Request) error {
+	admin, dir, notify, err := s.getAdminRequest(r)
+	if err != nil {
+		return err
+	}
+
+	to := r.FormValue("to")
+
+	if _, groups := users.GetIDs(to); groups == nil {
+		return ErrInvalidUser
+	}
+
+	s.rulesMu.Lock()
+	defer s.rulesMu.Unlock()
+
+	directory, ok := s.directoryRules[dir]
+	if !ok {
+		return ErrDirectoryNotClaimed
+	}
+
+	claimant := directory.ClaimedBy
+	directory.ClaimedBy = to
+
+	if err := s.rulesDB.UpdateDirectory(directory.Directory); err != nil {
+		directory.ClaimedBy = claimant
+
+		return err
+	}
+
+	return s.audit(w, notify, &db.AuditEntry{
+		Directory: dir,
+		Action:    AdminReassign,
+		Admin:     admin,
+		Claimant:  claimant,
+		Details:   to,
+	})
+}
+
+// AdminRemoveRule is an HTTP endpoint that allows a member of the admin group to
+// remove a rule from any directory.
+//
+// The directory is taken from the 'dir' GET param and the rule is determined by
+// the 'match' GET param. If the 'notify' GET param is "true", the claimant will
+// be notified.
+func (s *Server) AdminRemoveRule(w http.ResponseWriter, r *http.Request) {
+	handle(w, r, s.adminRemoveRule)
+}
+
+func (s *Server) adminRemoveRule(w http.ResponseWriter, r *http.Request) error {
+	admin, dir, notify, err := s.getAdminRequest(r)
+	if err != nil {
+		return err
+	}
+
+	s.buildMu.Lock()
+	defer s.buildMu.Unlock()
+
+	directory, rule, claimant, err := s.getAnyRule(dir, r.FormValue("match"))
+	if err != nil {
+		return err
+	}
+
+	if err := s.removeDirRule(directory, rule); err != nil {
+		return err
+	}
+
+	return s.audit(w, notify, &db.AuditEntry{
+		Directory: dir,
+		Action:    AdminRemoveRule,
+		Admin:     admin,
+		Claimant:  claimant,
+		Details:   rule.Match,
+	})
+}
+
+// AdminUnfreeze is an HTTP endpoint that allows a member of the admin group to
+// unfreeze any directory.
+//
+// The directory is taken from the 'dir' GET param. If the 'notify' GET param is
+// "true", the claimant will be notified.
+func (s *Server) AdminUnfreeze(w http.ResponseWriter, r *http.Request) {
+	handle(w, r, s.adminUnfreeze)
+}
+
+func (s *Server) adminUnfreeze(w http.ResponseWriter, r *http.Request) error {
+	admin, dir, notify, err := s.getAdminRequest(r)
+	if err != nil {
+		return err
+	}
+
+	s.rulesMu.Lock()
+	defer s.rulesMu.Unlock()
+
+	directory, ok := s.directoryRules[dir]
+	if !ok {
+		return ErrDirectoryNotClaimed
+	}
+
+	if !directory.Frozen {
+		return ErrDirectoryNotFrozen
+	}
+
+	melt := directory.Melt
+	directory.Frozen = false
+	directory.Melt = 0
+
+	if err := s.rulesDB.UpdateDirectory(directory.Directory); err != nil {
+		directory.Frozen = true
+		directory.Melt = melt
+
+		return err
+	}
+
+	return s.audit(w, notify, &db.AuditEntry{
+		Directory: dir,
+		Action:    AdminUnfreeze,
+		Admin:     admin,
+		Claimant:  directory.ClaimedBy,
+	})
+}
+
+// AdminRevoke is an HTTP endpoint that allows a member of the admin group to
+// revoke the claim on any directory, removing all of its rules.
+//
+// The directory is taken from the 'dir' GET param. If the 'notify' GET param is
+// "true", the claimant will be notified.
+func (s *Server) AdminRevoke(w http.ResponseWriter, r *http.Request) {
+	handle(w, r, s.adminRevoke)
+}
+
+func (s *Server) adminRevoke(w http.ResponseWriter, r *http.Request) error { //nolint:funlen
+	admin, dir, notify, err := s.getAdminRequest(r)
+	if err != nil {
+		return err
+	}
+
+	s.buildMu.Lock()
+	defer s.buildMu.Unlock()
+
+	s.rulesMu.RLock()
+
+	directory, ok := s.directoryRules[dir]
+	if !ok {
+		s.rulesMu.RUnlock()
+
+		return ErrDirectoryNotClaimed
+	}
+
+	claimant := directory.ClaimedBy
+	rules := slices.Collect(maps.Values(directory.Rules))
+
+	s.rulesMu.RUnlock()
+
+	if err := s.rulesDB.RemoveDirectory(directory.Directory); err != nil {
+		return err
+	}
+
+	if len(rules) > 0 {
+		if err := s.rootDir.RemoveRules(directory.Directory, rules); err != nil {
+			return err
+		}
+	}
+
+	s.rulesMu.Lock()
+
+	for _, rule := range rules {
+		delete(s.rules, uint64(rule.ID())) //nolint:gosec
+	}
+
+	delete(s.directoryRules, dir)
+	delete(s.dirs, uint64(directory.ID())) //nolint:gosec
+
+	s.rulesMu.Unlock()
+
+	if err := s.updateDirSummaries(dir); err != nil {
+		return err
+	}
+
+	return s.audit(w, notify, &db.AuditEntry{
+		Directory: dir,
+		Action:    AdminRevoke,
+		Admin:     admin,
+		Claimant:  claimant,
+	})
+}
+
+// AdminAudit is an HTTP endpoint that returns, to members of the admin group,
+// the log of all admin actions.
+func (s *Server) AdminAudit(w http.ResponseWriter, r *http.Request) {
+	handle(w, r, s.adminAudit)
+}
+
+func (s *Server) adminAudit(w http.ResponseWriter, r *http.Request) error {
+	if _, err := s.getAdmin(r); err != nil {
+		return err
+	}
+
+	entries := []*db.AuditEntry{}
+
+	if err := s.rulesDB.ReadAuditEntries().ForEach(func(entry *db.AuditEntry) error {
+		entries = append(entries, entry)
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	return json.NewEncoder(w).Encode(entries)
+}
+
+// getAnyRule returns the claimed directory, the rule with the given match
+// string, and the claimant of the directory, regardless of who is asking.
+func (s *Server) getAnyRule(dir, match string) (*Directory, *db.Rule, string, error) {
+	s.rulesMu.RLock()
+	defer s.rulesMu.RUnlock()
+
+	directory, ok := s.directoryRules[dir]
+	if !ok {
+		return nil, nil, "", ErrDirectoryNotClaimed
+	}
+
+	rule, ok := directory.Rules[match]
+	if !ok {
+		return nil, nil, "", ErrNoRule
+	}
+
+	return directory, rule, directory.ClaimedBy, nil
+}
+
+func (s *Server) getAdmin(r *http.Request) (string, error) {
+	admin := s.getUser(r)
+
+	_, groups := users.GetIDs(admin)
+	if groups == nil || !slices.Contains(groups, s.config.GetAdminGroup()) {
+		return "", ErrNotAdmin
+	}
+
+	return admin, nil
+}
+
+func (s *Server) isAdmin(r *http.Request) bool {
+	_, err := s.getAdmin(r)
+
+	return err == nil
+}
+
+// getAdminRequest returns the admin making the request, the directory being
+// acted upon, and whether the claimant should be notified.
+func (s *Server) getAdminRequest(r *http.Request) (string, string, bool, error) {
+	admin, err := s.getAdmin(r)
+	if err != nil {
+		return "", "", false, err
+	}
+
+	dir, err := getDir(r)
+	if err != nil {
+		return "", "", false, err
+	}
+
+	notify := r.FormValue("notify") == "true"
+	if notify && s.config.GetNotifyURL() == "" {
+		return "", "", false, ErrNoNotifyURL
+	}
+
+	return admin, dir, notify, nil
+}
+
+// audit records the given admin action, sending a notification if requested.
+func (s *Server) audit(w http.ResponseWriter, notify bool, entry *db.AuditEntry) error {
+	if err := s.rulesDB.AddAuditEntry(entry); err != nil {
+		return err
+	}
+
+	slog.Info("admin action", "action", entry.Action, "admin", entry.Admin,
+		"dir", entry.Directory, "claimant", entry.Claimant, "details", entry.Details)
+
+	if notify {
+		go sendNotification(s.config.GetNotifyURL(), entry)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// sendNotification POSTs the given audit entry, as JSON, to the given URL.
+func sendNotification(url string, entry *db.AuditEntry) {
+	body, err := json.Marshal(entry)
+	if err != nil {
+		slog.Error("error encoding notification", "err", err)
+
+		return
+	}
+
+	client := http.Client{Timeout: notifyTimeout}
+
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body)) //nolint:noctx
+	if err != nil {
+		slog.Error("error sending notification", "url", url, "err", err)
+
+		return
+	}
+
+	resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		slog.Error("error sending notification", "url", url, "status", resp.Status)
+	}
+}
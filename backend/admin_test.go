@@ -0,0 +1,184 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package backend
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/wtsi-hgi/backup-plans/config"
+	"github.com/wtsi-hgi/backup-plans/db"
+	"github.com/wtsi-hgi/backup-plans/internal/testdb"
+)
+
+func TestAdmin(t *testing.T) {
+	Convey("Given a server with an admin group and a notification URL", t, func() {
+		u := userHandler(root)
+
+		notifications := make(chan *db.AuditEntry, 1)
+
+		srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			var entry db.AuditEntry
+
+			json.NewDecoder(r.Body).Decode(&entry) //nolint:errcheck
+
+			notifications <- &entry
+		}))
+
+		Reset(srv.Close)
+
+		cfg := filepath.Join(t.TempDir(), "config.yaml")
+
+		So(os.WriteFile(cfg, []byte("admingroup: 0\nnotifyurl: "+srv.URL+"\n"), 0600), ShouldBeNil)
+
+		c, err := config.Parse(cfg)
+		So(err, ShouldBeNil)
+
+		Reset(c.Stop)
+
+		s, err := New(testdb.CreateTestDatabase(t), u.getUser, c)
+		So(err, ShouldBeNil)
+
+		Reset(s.Stop)
+
+		So(s.AddTree(createTestTree(t)), ShouldBeNil)
+
+		const dir = "/some/path/MyDir/"
+
+		code, _ := getResponse(s.ClaimDir, "/api/dir/claim?dir="+dir, nil)
+		So(code, ShouldEqual, http.StatusOK)
+
+		code, resp := getResponse(s.CreateRule, "/api/rules/create?dir="+dir+"&action=backup&match=*.txt", nil)
+		So(resp, ShouldBeBlank)
+		So(code, ShouldEqual, http.StatusNoContent)
+
+		Convey("Only admins can use the admin endpoints", func() {
+			u = "nobody"
+
+			for _, fn := range [...]http.HandlerFunc{
+				s.AdminReassign, s.AdminRemoveRule, s.AdminUnfreeze, s.AdminRevoke, s.AdminAudit,
+			} {
+				code, resp := getResponse(fn, "/api/admin/?dir="+dir+"&to=nobody&match=*.txt", nil)
+				checkErrorResponse(t, code, resp, ErrNotAdmin)
+			}
+
+			So(s.directoryRules[dir].ClaimedBy, ShouldEqual, root)
+		})
+
+		Convey("An admin can reassign a claim, notifying the claimant", func() {
+			code, resp := getResponse(s.AdminReassign, "/api/admin/reassign?dir=/some/path/&to=nobody", nil)
+			checkErrorResponse(t, code, resp, ErrDirectoryNotClaimed)
+
+			code, resp = getResponse(s.AdminReassign, "/api/admin/reassign?dir="+dir+"&to=not-a-user", nil)
+			checkErrorResponse(t, code, resp, ErrInvalidUser)
+
+			code, resp = getResponse(s.AdminReassign, "/api/admin/reassign?dir="+dir+"&to=nobody&notify=true", nil)
+			So(resp, ShouldBeBlank)
+			So(code, ShouldEqual, http.StatusNoContent)
+			So(s.directoryRules[dir].ClaimedBy, ShouldEqual, "nobody")
+
+			var entry *db.AuditEntry
+
+			select {
+			case entry = <-notifications:
+			case <-time.After(5 * time.Second):
+			}
+
+			So(entry, ShouldNotBeNil)
+			So(entry.Action, ShouldEqual, AdminReassign)
+			So(entry.Admin, ShouldEqual, root)
+			So(entry.Claimant, ShouldEqual, root)
+			So(entry.Details, ShouldEqual, "nobody")
+
+			Convey("And remove rules from directories claimed by others", func() {
+				code, resp := getResponse(s.AdminRemoveRule, "/api/admin/rules/remove?dir="+dir+"&match=*.tsv", nil)
+				checkErrorResponse(t, code, resp, ErrNoRule)
+
+				code, resp = getResponse(s.AdminRemoveRule, "/api/admin/rules/remove?dir="+dir+"&match=*.txt", nil)
+				So(resp, ShouldBeBlank)
+				So(code, ShouldEqual, http.StatusNoContent)
+				So(s.directoryRules[dir].Rules, ShouldBeEmpty)
+			})
+		})
+
+		Convey("An admin can unfreeze a frozen directory", func() {
+			code, resp := getResponse(s.AdminUnfreeze, "/api/admin/unfreeze?dir="+dir, nil)
+			checkErrorResponse(t, code, resp, ErrDirectoryNotFrozen)
+
+			s.directoryRules[dir].Frozen = true
+
+			code, resp = getResponse(s.AdminUnfreeze, "/api/admin/unfreeze?dir="+dir, nil)
+			So(resp, ShouldBeBlank)
+			So(code, ShouldEqual, http.StatusNoContent)
+			So(s.directoryRules[dir].Frozen, ShouldBeFalse)
+		})
+
+		Convey("An admin can revoke a claim, removing its rules", func() {
+			code, resp := getResponse(s.AdminRevoke, "/api/admin/revoke?dir="+dir, nil)
+			So(resp, ShouldBeBlank)
+			So(code, ShouldEqual, http.StatusNoContent)
+			So(s.directoryRules, ShouldNotContainKey, dir)
+			So(s.rules, ShouldBeEmpty)
+
+			code, resp = getResponse(s.AdminRevoke, "/api/admin/revoke?dir="+dir, nil)
+			checkErrorResponse(t, code, resp, ErrDirectoryNotClaimed)
+
+			Convey("And all admin actions are recorded in the audit log", func() {
+				code, resp := getResponse(s.AdminAudit, "/api/admin/audit", nil)
+				So(code, ShouldEqual, http.StatusOK)
+
+				var entries []*db.AuditEntry
+
+				So(json.Unmarshal([]byte(resp), &entries), ShouldBeNil)
+				So(len(entries), ShouldEqual, 1)
+				So(entries[0].Action, ShouldEqual, AdminRevoke)
+				So(entries[0].Directory, ShouldEqual, dir)
+				So(entries[0].Admin, ShouldEqual, root)
+				So(entries[0].Claimant, ShouldEqual, root)
+			})
+		})
+
+		Convey("Notifications can't be requested without a notification URL", func() {
+			So(os.WriteFile(cfg, []byte("admingroup: 0\n"), 0600), ShouldBeNil)
+
+			c, err := config.Parse(cfg)
+			So(err, ShouldBeNil)
+
+			Reset(c.Stop)
+
+			s.config = c
+
+			code, resp := getResponse(s.AdminUnfreeze, "/api/admin/unfreeze?dir="+dir+"&notify=true", nil)
+			checkErrorResponse(t, code, resp, ErrNoNotifyURL)
+		})
+	})
+}
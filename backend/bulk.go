@@ -0,0 +1,457 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package backend
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/wtsi-hgi/backup-plans/db"
+	"github.com/wtsi-hgi/backup-plans/ruletree"
+)
+
+var (
+	ErrNoFilter = Error{
+		Code: http.StatusBadRequest,
+		Err:  errors.New("no filter given"), //nolint:err113
+	}
+	ErrInvalidGlob = Error{
+		Code: http.StatusBadRequest,
+		Err:  errors.New("invalid glob"), //nolint:err113
+	}
+	ErrConflictingMatch = Error{
+		Code: http.StatusBadRequest,
+		Err:  errors.New("match used in more than one change"), //nolint:err113
+	}
+)
+
+// BulkRules describes rule changes to be made to every claimed directory that
+// matches all of the given filters.
+type BulkRules struct {
+	Glob     string
+	Group    string
+	BOM      string
+	Claimant string
+	Create   []BulkRule
+	Update   []BulkRule
+	Remove   []string
+	Preview  bool
+}
+
+// BulkRule describes a rule for each of the Match strings, as with the
+// parameters to CreateRule.
+type BulkRule struct {
+	Action   string
+	Metadata string
+	Match    []string
+	Override bool
+}
+
+// BulkDirectory lists the matches of the rules that are, or would be, created,
+// updated and removed for a directory.
+type BulkDirectory struct {
+	Path      string
+	ClaimedBy string
+	Create    []string `json:",omitempty"`
+	Update    []string `json:",omitempty"`
+	Remove    []string `json:",omitempty"`
+}
+
+type bulkDirectory struct {
+	*Directory
+	create, update, remove []*db.Rule
+}
+
+type bulkTemplates struct {
+	create, update []*db.Rule
+	remove         []string
+}
+
+// BulkRules is an HTTP endpoint that applies a set of rule changes to every
+// claimed directory that matches all of the given filters.
+//
+// The body of the request is a JSON encoded BulkRules. The Glob is matched
+// against the path of each claimed directory, with each * only matching within
+// a single path part; the Group and BOM match the group of the directory; the
+// Claimant matches the user who claimed the directory. At least one filter must
+// be given.
+//
+// Rules in Create are added to each directory that has no rule for the match,
+// rules in Update change the existing rule for the match, and matches in Remove
+// have their rule removed; directories without a rule for the match are left
+// unchanged.
+//
+// Only directories claimed by the user are changed, unless the user is a member
+// of the admin group, in which case changes to directories claimed by others
+// are recorded in the admin audit log.
+//
+// All changes are made in a single transaction. If Preview is true, no changes
+// are made.
+//
+// The response is a JSON list of BulkDirectory, for each directory that would
+// be changed.
+func (s *Server) BulkRules(w http.ResponseWriter, r *http.Request) {
+	handle(w, r, s.bulkRules)
+}
+
+func (s *Server) bulkRules(w http.ResponseWriter, r *http.Request) error {
+	var req BulkRules
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return Error{Code: http.StatusBadRequest, Err: err}
+	}
+
+	templates, err := req.templates()
+	if err != nil {
+		return err
+	}
+
+	if err = req.checkFilter(); err != nil {
+		return err
+	}
+
+	user := s.getUser(r)
+	admin := s.isAdmin(r)
+
+	s.buildMu.Lock()
+	defer s.buildMu.Unlock()
+
+	dirs, err := s.planBulkRules(&req, templates, user, admin)
+	if err != nil {
+		return err
+	}
+
+	if !req.Preview && len(dirs) > 0 {
+		if err := s.applyBulkRules(dirs, user); err != nil {
+			return err
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	return json.NewEncoder(w).Encode(bulkResults(dirs))
+}
+
+func (b *BulkRules) templates() (*bulkTemplates, error) {
+	var (
+		t       bulkTemplates
+		err     error
+		matches = make(map[string]struct{})
+	)
+
+	if t.create, err = bulkRuleTemplates(b.Create, matches); err != nil {
+		return nil, err
+	}
+
+	if t.update, err = bulkRuleTemplates(b.Update, matches); err != nil {
+		return nil, err
+	}
+
+	remove, err := createMatchRules(db.Rule{}, b.Remove)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rule := range remove {
+		if err := addMatch(matches, rule.Match); err != nil {
+			return nil, err
+		}
+
+		t.remove = append(t.remove, rule.Match)
+	}
+
+	return &t, nil
+}
+
+func bulkRuleTemplates(bulkRules []BulkRule, matches map[string]struct{}) ([]*db.Rule, error) {
+	var templates []*db.Rule
+
+	for _, br := range bulkRules {
+		rules, err := newRules(br.Action, br.Metadata, br.Override, br.Match)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rule := range rules {
+			if err := addMatch(matches, rule.Match); err != nil {
+				return nil, err
+			}
+		}
+
+		templates = append(templates, rules...)
+	}
+
+	return templates, nil
+}
+
+func addMatch(matches map[string]struct{}, match string) error {
+	if _, ok := matches[match]; ok {
+		return ErrConflictingMatch
+	}
+
+	matches[match] = struct{}{}
+
+	return nil
+}
+
+func (b *BulkRules) checkFilter() error {
+	if b.Glob == "" && b.Group == "" && b.BOM == "" && b.Claimant == "" {
+		return ErrNoFilter
+	}
+
+	if b.Glob == "" {
+		return nil
+	}
+
+	if !strings.HasSuffix(b.Glob, "/") {
+		b.Glob += "/"
+	}
+
+	if _, err := filepath.Match(b.Glob, ""); err != nil {
+		return ErrInvalidGlob
+	}
+
+	return nil
+}
+
+func (s *Server) matchesBulkFilter(dir *Directory, b *BulkRules, user string, admin bool) bool {
+	if !admin && dir.ClaimedBy != user {
+		return false
+	}
+
+	if b.Claimant != "" && dir.ClaimedBy != b.Claimant {
+		return false
+	}
+
+	if b.Group != "" && s.dirGroups[dir.ID()] != b.Group {
+		return false
+	}
+
+	if b.BOM != "" && s.dirBoms[dir.ID()] != b.BOM {
+		return false
+	}
+
+	if b.Glob == "" {
+		return true
+	}
+
+	m, _ := filepath.Match(b.Glob, dir.Path) //nolint:errcheck
+
+	return m
+}
+
+func (s *Server) planBulkRules(b *BulkRules, t *bulkTemplates, user string, admin bool) ([]*bulkDirectory, error) {
+	s.rulesMu.RLock()
+	defer s.rulesMu.RUnlock()
+
+	var dirs []*bulkDirectory
+
+	for _, dir := range s.directoryRules {
+		if !s.matchesBulkFilter(dir, b, user, admin) {
+			continue
+		}
+
+		bd, err := s.planBulkDirectory(dir, t)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(bd.create)+len(bd.update)+len(bd.remove) > 0 {
+			dirs = append(dirs, bd)
+		}
+	}
+
+	slices.SortFunc(dirs, func(a, b *bulkDirectory) int { return strings.Compare(a.Path, b.Path) })
+
+	return dirs, nil
+}
+
+func (s *Server) planBulkDirectory(dir *Directory, t *bulkTemplates) (*bulkDirectory, error) {
+	bd := &bulkDirectory{Directory: dir}
+
+	for _, template := range t.create {
+		if _, ok := dir.Rules[template.Match]; !ok {
+			rule := *template
+			bd.create = append(bd.create, &rule)
+		}
+	}
+
+	for _, template := range t.update {
+		if existing, ok := dir.Rules[template.Match]; ok {
+			rule := *existing
+			rule.BackupType = template.BackupType
+			rule.Metadata = template.Metadata
+			rule.Template = 0
+			bd.update = append(bd.update, &rule)
+		}
+	}
+
+	for _, match := range t.remove {
+		if existing, ok := dir.Rules[match]; ok {
+			bd.remove = append(bd.remove, existing)
+		}
+	}
+
+	policy := s.config.GetPolicy(dir.Path)
+
+	if err := checkRulePolicy(&policy, bd.create); err != nil {
+		return nil, err
+	}
+
+	if err := checkUpdatePolicy(&policy, bd.update); err != nil {
+		return nil, err
+	}
+
+	return bd, nil
+}
+
+func (s *Server) applyBulkRules(dirs []*bulkDirectory, user string) error { //nolint:funlen
+	var (
+		changes     db.RuleChanges
+		add, remove []ruletree.DirRule
+		paths       = make([]string, len(dirs))
+	)
+
+	for n, bd := range dirs {
+		paths[n] = bd.Path
+
+		if len(bd.create) > 0 {
+			changes.Create = append(changes.Create, db.DirectoryRules{Directory: bd.Directory.Directory, Rules: bd.create})
+		}
+
+		changes.Update = append(changes.Update, bd.update...)
+		changes.Remove = append(changes.Remove, bd.remove...)
+
+		if bd.ClaimedBy != user {
+			changes.Audit = append(changes.Audit, &db.AuditEntry{
+				Directory: bd.Path,
+				Action:    AdminBulkRules,
+				Admin:     user,
+				Claimant:  bd.ClaimedBy,
+				Details:   bd.details(),
+			})
+		}
+	}
+
+	if err := s.rulesDB.ApplyRuleChanges(&changes); err != nil {
+		return err
+	}
+
+	s.rulesMu.Lock()
+
+	for _, bd := range dirs {
+		for _, rule := range bd.create {
+			bd.Rules[rule.Match] = rule
+			s.rules[uint64(rule.ID())] = rule //nolint:gosec
+
+			add = append(add, ruletree.DirRule{Directory: bd.Directory.Directory, Rule: rule})
+		}
+
+		for _, rule := range bd.update {
+			existing := bd.Rules[rule.Match]
+			existing.BackupType = rule.BackupType
+			existing.Metadata = rule.Metadata
+			existing.Modified = rule.Modified
+			existing.Template = rule.Template
+		}
+
+		for _, rule := range bd.remove {
+			delete(bd.Rules, rule.Match)
+			delete(s.rules, uint64(rule.ID())) //nolint:gosec
+
+			remove = append(remove, ruletree.DirRule{Directory: bd.Directory.Directory, Rule: rule})
+		}
+	}
+
+	s.rulesMu.Unlock()
+
+	if err := s.rootDir.ApplyRules(add, remove); err != nil {
+		return errors.Join(err, s.reloadRules())
+	}
+
+	return s.updateDirSummaries(paths...)
+}
+
+func (b *bulkDirectory) details() string {
+	var sb strings.Builder
+
+	for _, change := range [...]struct {
+		name  string
+		rules []*db.Rule
+	}{
+		{"create", b.create},
+		{"update", b.update},
+		{"remove", b.remove},
+	} {
+		if len(change.rules) == 0 {
+			continue
+		}
+
+		if sb.Len() > 0 {
+			sb.WriteString("; ")
+		}
+
+		sb.WriteString(change.name)
+		sb.WriteString(" ")
+		sb.WriteString(strings.Join(matches(change.rules), ", "))
+	}
+
+	return sb.String()
+}
+
+func matches(rules []*db.Rule) []string {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	ms := make([]string, len(rules))
+
+	for n, rule := range rules {
+		ms[n] = rule.Match
+	}
+
+	return ms
+}
+
+func bulkResults(dirs []*bulkDirectory) []BulkDirectory {
+	results := make([]BulkDirectory, len(dirs))
+
+	for n, bd := range dirs {
+		results[n] = BulkDirectory{
+			Path:      bd.Path,
+			ClaimedBy: bd.ClaimedBy,
+			Create:    matches(bd.create),
+			Update:    matches(bd.update),
+			Remove:    matches(bd.remove),
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,187 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package backend
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/wtsi-hgi/backup-plans/config"
+	"github.com/wtsi-hgi/backup-plans/db"
+	"github.com/wtsi-hgi/backup-plans/internal/testdb"
+)
+
+func TestBulkRules(t *testing.T) {
+	Convey("Given a server with claimed directories", t, func() {
+		u := userHandler(root)
+
+		cfg := filepath.Join(t.TempDir(), "config.yaml")
+
+		So(os.WriteFile(cfg, []byte("admingroup: 0\n"), 0600), ShouldBeNil)
+
+		c, err := config.Parse(cfg)
+		So(err, ShouldBeNil)
+
+		Reset(c.Stop)
+
+		s, err := New(testdb.CreateTestDatabase(t), u.getUser, c)
+		So(err, ShouldBeNil)
+
+		Reset(s.Stop)
+
+		So(s.AddTree(createTestTree(t)), ShouldBeNil)
+
+		const (
+			dirA = "/some/path/MyDir/"
+			dirB = "/some/path/ChildDir/"
+		)
+
+		for _, dir := range [...]string{dirA, dirB} {
+			code, _ := getResponse(s.ClaimDir, "/api/dir/claim?dir="+dir, nil)
+			So(code, ShouldEqual, http.StatusOK)
+		}
+
+		code, resp := getResponse(s.CreateRule, "/api/rules/create?dir="+dirA+"&action=backup&match=*.txt", nil)
+		So(resp, ShouldBeBlank)
+		So(code, ShouldEqual, http.StatusNoContent)
+
+		bulk := func(req string) (int, string, []BulkDirectory) {
+			code, resp := getResponse(s.BulkRules, "/api/rules/bulk", strings.NewReader(req))
+
+			var dirs []BulkDirectory
+
+			if code == http.StatusOK {
+				So(json.Unmarshal([]byte(resp), &dirs), ShouldBeNil)
+			}
+
+			return code, resp, dirs
+		}
+
+		Convey("Bulk changes require a valid filter and distinct matches", func() {
+			code, resp, _ := bulk(`{"Remove": ["*.txt"]}`)
+			checkErrorResponse(t, code, resp, ErrNoFilter)
+
+			code, resp, _ = bulk(`{"Glob": "/some/[/", "Remove": ["*.txt"]}`)
+			checkErrorResponse(t, code, resp, ErrInvalidGlob)
+
+			code, resp, _ = bulk(`{"Glob": "/some/path/*/", "Create": [{"Action": "nobackup", "Match": ["*.txt"]}], ` +
+				`"Remove": ["*.txt"]}`)
+			checkErrorResponse(t, code, resp, ErrConflictingMatch)
+
+			code, resp, _ = bulk(`{"Glob": "/some/path/*/", "Create": [{"Action": "unknown", "Match": ["*.tmp"]}]}`)
+			checkErrorResponse(t, code, resp, ErrInvalidAction)
+		})
+
+		const change = `"Create": [{"Action": "nobackup", "Match": ["*.tmp", "core.*"]}], ` +
+			`"Update": [{"Action": "nobackup", "Match": ["*.txt"]}]`
+
+		Convey("You can preview bulk changes", func() {
+			code, _, dirs := bulk(`{"Glob": "/some/path/*", "Preview": true, ` + change + `}`)
+			So(code, ShouldEqual, http.StatusOK)
+			So(dirs, ShouldResemble, []BulkDirectory{
+				{Path: dirB, ClaimedBy: root, Create: []string{"*.tmp", "core.*"}},
+				{Path: dirA, ClaimedBy: root, Create: []string{"*.tmp", "core.*"}, Update: []string{"*.txt"}},
+			})
+
+			So(len(s.directoryRules[dirA].Rules), ShouldEqual, 1)
+			So(s.directoryRules[dirA].Rules["*.txt"].BackupType, ShouldEqual, db.BackupIBackup)
+			So(s.directoryRules[dirB].Rules, ShouldBeEmpty)
+		})
+
+		Convey("You can apply bulk changes", func() {
+			code, _, dirs := bulk(`{"Glob": "/some/path/*", ` + change + `}`)
+			So(code, ShouldEqual, http.StatusOK)
+			So(len(dirs), ShouldEqual, 2)
+
+			So(len(s.directoryRules[dirA].Rules), ShouldEqual, 3)
+			So(s.directoryRules[dirA].Rules["*.txt"].BackupType, ShouldEqual, db.BackupNone)
+			So(s.directoryRules[dirB].Rules, ShouldContainKey, "core.*")
+			So(len(s.rules), ShouldEqual, 5)
+
+			code, _, dirs = bulk(`{"Glob": "/some/path/*", ` + change + `}`)
+			So(code, ShouldEqual, http.StatusOK)
+			So(dirs, ShouldResemble, []BulkDirectory{{Path: dirA, ClaimedBy: root, Update: []string{"*.txt"}}})
+
+			ns, err := New(s.rulesDB, u.getUser, c)
+			So(err, ShouldBeNil)
+
+			So(len(ns.directoryRules[dirA].Rules), ShouldEqual, 3)
+			So(len(ns.directoryRules[dirB].Rules), ShouldEqual, 2)
+
+			ns.Stop()
+
+			Convey("And remove rules in bulk", func() {
+				code, _, dirs := bulk(`{"Claimant": "` + root + `", "Remove": ["*.tmp"]}`)
+				So(code, ShouldEqual, http.StatusOK)
+				So(len(dirs), ShouldEqual, 2)
+				So(s.directoryRules[dirA].Rules, ShouldNotContainKey, "*.tmp")
+				So(s.directoryRules[dirB].Rules, ShouldNotContainKey, "*.tmp")
+				So(len(s.rules), ShouldEqual, 3)
+			})
+		})
+
+		Convey("Users can only change their own directories, but admins can change any", func() {
+			s.directoryRules[dirB].ClaimedBy = "nobody"
+
+			u = "nobody"
+
+			code, _, dirs := bulk(`{"Glob": "/some/path/*/", "Remove": ["*.txt"], "Create": [{"Action": "nobackup"}]}`)
+			So(code, ShouldEqual, http.StatusOK)
+			So(dirs, ShouldResemble, []BulkDirectory{{Path: dirB, ClaimedBy: "nobody", Create: []string{"*"}}})
+
+			u = root
+
+			code, _, dirs = bulk(`{"Claimant": "nobody", "Remove": ["*"]}`)
+			So(code, ShouldEqual, http.StatusOK)
+			So(dirs, ShouldResemble, []BulkDirectory{{Path: dirB, ClaimedBy: "nobody", Remove: []string{"*"}}})
+
+			entries, err := collectAuditEntries(s.rulesDB)
+			So(err, ShouldBeNil)
+			So(len(entries), ShouldEqual, 1)
+			So(entries[0].Action, ShouldEqual, AdminBulkRules)
+			So(entries[0].Directory, ShouldEqual, dirB)
+			So(entries[0].Claimant, ShouldEqual, "nobody")
+			So(entries[0].Details, ShouldEqual, "remove *")
+		})
+	})
+}
+
+func collectAuditEntries(d *db.DB) ([]*db.AuditEntry, error) {
+	var entries []*db.AuditEntry
+
+	err := d.ReadAuditEntries().ForEach(func(entry *db.AuditEntry) error {
+		entries = append(entries, entry)
+
+		return nil
+	})
+
+	return entries, err
+}
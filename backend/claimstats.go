@@ -142,6 +142,8 @@ func (s *Server) generateDirStats(dir *Directory, dirSummary *ruletree.DirSummar
 func (s *Server) gatherSBAs(dir *Directory, dirSummary *ruletree.DirSummary) []ibackup.SetBackupActivity {
 	sbas := make([]ibackup.SetBackupActivity, 0, len(dirSummary.RuleSummaries))
 	seen := make(map[string]struct{})
+	sourceMods := make(map[string]uint64)
+	dirRules := make([]*db.Rule, 0, len(dirSummary.RuleSummaries))
 
 	for _, ruleSummary := range dirSummary.RuleSummaries {
 		rule, ok := s.rules[ruleSummary.ID]
@@ -155,18 +157,30 @@ func (s *Server) gatherSBAs(dir *Directory, dirSummary *ruletree.DirSummary) []i
 			continue
 		}
 
-		sbas = s.addSBA(sbas, seen, dir, rule)
+		s.addSourceMod(sourceMods, rule, dir.Path, &ruleSummary)
+		dirRules = append(dirRules, rule)
+	}
+
+	for _, rule := range dirRules {
+		key, _ := sourceModKey(rule)
+
+		sbas = s.addSBA(sbas, seen, dir, rule, sourceMods[key])
 	}
 
 	return sbas
 }
 
 // addSBA will retrieve the ibackup.SetBackupActivity for a given set and add it to sbas. Duplicates are skipped.
+//
+// For manualgit and manualnfs rules, sourceMod is the newest modification time
+// of the files matched by rules with the same target, and is used to flag
+// stale targets.
 func (s *Server) addSBA( //nolint:gocyclo,funlen
 	sbas []ibackup.SetBackupActivity,
 	seen map[string]struct{},
 	dir *Directory,
 	rule *db.Rule,
+	sourceMod uint64,
 ) []ibackup.SetBackupActivity {
 	requester := dir.ClaimedBy
 
@@ -187,13 +201,18 @@ func (s *Server) addSBA( //nolint:gocyclo,funlen
 
 	case db.BackupManualGit:
 		if _, exists := seen[rule.Metadata]; !exists {
-			sbas = append(sbas, s.getGitBackupStatus(rule.Metadata, requester))
+			sba := s.getGitBackupStatus(rule.Metadata, requester)
+			markStale(&sba, sourceMod)
+
+			sbas = append(sbas, sba)
 			seen[rule.Metadata] = struct{}{}
 		}
 
 	case db.BackupManualNFS:
 		sba := s.getNFSStatus(rule.Metadata, requester)
 		if _, exists := seen[rule.Metadata]; !exists {
+			markStale(&sba, sourceMod)
+
 			sbas = append(sbas, sba)
 			seen[rule.Metadata] = struct{}{}
 		}
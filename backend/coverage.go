@@ -0,0 +1,69 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package backend
+
+import (
+	"time"
+
+	"github.com/wtsi-hgi/backup-plans/db"
+	"github.com/wtsi-hgi/backup-plans/ibackup"
+)
+
+// loadCoverage reads the manual set coverage, as stored by the verify command,
+// from the database, keying it by directory path and set name.
+//
+// Must be called with rulesMu held.
+func (s *Server) loadCoverage() error {
+	coverage := make(map[string]*ibackup.SetCoverage)
+
+	if err := s.rulesDB.ReadSetCoverage().ForEach(func(sc *db.SetCoverage) error {
+		dir, ok := s.dirs[uint64(sc.DirID())] //nolint:gosec
+		if !ok {
+			return nil
+		}
+
+		coverage[coverageKey(dir.Path, sc.SetName)] = &ibackup.SetCoverage{
+			Checked: time.Unix(sc.Checked, 0),
+			Matched: sc.Matched,
+			Missing: sc.Missing,
+			Stale:   sc.Stale,
+			Extra:   sc.Extra,
+			Percent: ibackup.CoveragePercent(sc.Matched, sc.Missing+sc.Stale),
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	s.coverage = coverage
+
+	return nil
+}
+
+func coverageKey(dir, set string) string {
+	return dir + "\x00" + set
+}
@@ -0,0 +1,94 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package backend
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/wtsi-hgi/backup-plans/db"
+	"github.com/wtsi-hgi/backup-plans/internal/config"
+	"github.com/wtsi-hgi/backup-plans/internal/testdb"
+)
+
+func TestCoverage(t *testing.T) {
+	Convey("Given a database with stored manual set coverage", t, func() {
+		var u userHandler
+
+		testDB := testdb.CreateTestDatabase(t)
+
+		dir := &db.Directory{
+			Path:      "/lustre/scratch123/humgen/a/c/",
+			ClaimedBy: "userB",
+		}
+
+		So(testDB.CreateDirectory(dir), ShouldBeNil)
+		So(testDB.SetSetCoverage(dir, &db.SetCoverage{
+			SetName: "manualSetName",
+			Matched: 4,
+			Missing: 1,
+			Stale:   1,
+			Extra:   3,
+			Checked: 1000,
+		}), ShouldBeNil)
+
+		s, err := New(testDB, u.getUser, config.NewConfig(t, nil, nil, nil, 0, nil))
+		So(err, ShouldBeNil)
+
+		Reset(s.Stop)
+
+		Convey("The coverage is included in the manual backup status of the set", func() {
+			sba := s.getManualIBackupStatus(dirSet{dir.Path, "manualSetName"}, "userB")
+			So(sba.Coverage, ShouldNotBeNil)
+			So(sba.Coverage.Checked, ShouldEqual, time.Unix(1000, 0))
+			So(sba.Coverage.Matched, ShouldEqual, 4)
+			So(sba.Coverage.Missing, ShouldEqual, 1)
+			So(sba.Coverage.Stale, ShouldEqual, 1)
+			So(sba.Coverage.Extra, ShouldEqual, 3)
+			So(sba.Coverage.Percent, ShouldEqual, 50)
+
+			sba = s.getManualIBackupStatus(dirSet{dir.Path, "otherSet"}, "userB")
+			So(sba.Coverage, ShouldBeNil)
+		})
+
+		Convey("Updated coverage is seen after reloading", func() {
+			So(testDB.SetSetCoverage(dir, &db.SetCoverage{
+				SetName: "manualSetName",
+				Matched: 4,
+				Checked: 2000,
+			}), ShouldBeNil)
+
+			s.rulesMu.Lock()
+			So(s.loadCoverage(), ShouldBeNil)
+			s.rulesMu.Unlock()
+
+			sba := s.getManualIBackupStatus(dirSet{dir.Path, "manualSetName"}, "userB")
+			So(sba.Coverage, ShouldNotBeNil)
+			So(sba.Coverage.Percent, ShouldEqual, 100)
+		})
+	})
+}
@@ -0,0 +1,113 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package backend
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/wtsi-hgi/backup-plans/db"
+	"github.com/wtsi-hgi/backup-plans/ruletree"
+	"github.com/wtsi-hgi/backup-plans/users"
+)
+
+var ErrInvalidPath = Error{
+	Code: http.StatusBadRequest,
+	Err:  errors.New("invalid file path"), //nolint:err113
+}
+
+const unplannedName = "unplanned"
+
+// Explanation describes which rule applies to a file, which other rules match
+// it, and the resulting backup type and ibackup set name.
+type Explanation struct {
+	Path string
+	*ruletree.Explanation
+	BackupType string
+	SetName    string `json:",omitempty"`
+}
+
+// Explain is an HTTP endpoint that explains which rule applies to a file.
+//
+// The 'path' GET param specifies the file.
+//
+// The response is a JSON encoded Explanation, with the matching rules that did
+// not apply listed nearest directory first, along with the reason each lost to
+// the applied rule: "precedence" for a more specific match in the same
+// directory, "nearer directory" for a rule on a directory closer to the file,
+// and "override from a parent" for an override rule on a parent directory. When
+// no rule applies, the reason for each matching rule is "not applied".
+func (s *Server) Explain(w http.ResponseWriter, r *http.Request) {
+	handle(w, r, s.explain)
+}
+
+func (s *Server) explain(w http.ResponseWriter, r *http.Request) error {
+	path := r.FormValue("path")
+	if !strings.HasPrefix(path, "/") || strings.HasSuffix(path, "/") {
+		return ErrInvalidPath
+	}
+
+	uid, groups := users.GetIDs(s.getUser(r))
+	if len(groups) == 0 {
+		return ErrNotAuthorised
+	}
+
+	s.rulesMu.RLock()
+	defer s.rulesMu.RUnlock()
+
+	summary, err := s.rootDir.Summary(path[:strings.LastIndexByte(path, '/')+1])
+	if err != nil {
+		return err
+	}
+
+	if !isAuthorised(summary, uid, groups, s.config.GetAdminGroup()) {
+		return ErrNotAuthorised
+	}
+
+	e, err := s.rootDir.Explain(path)
+	if err != nil {
+		return err
+	}
+
+	explanation := Explanation{Path: path, Explanation: e, BackupType: unplannedName}
+
+	if e.Rule != nil {
+		explanation.BackupType = e.Rule.Rule.BackupType.Name()
+
+		switch e.Rule.Rule.BackupType { //nolint:exhaustive
+		case db.BackupIBackup:
+			explanation.SetName = setNamePrefix + e.Rule.Directory
+		case db.BackupManualIBackup:
+			explanation.SetName = e.Rule.Rule.Metadata
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	return json.NewEncoder(w).Encode(explanation)
+}
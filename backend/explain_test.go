@@ -0,0 +1,114 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package backend
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/wtsi-hgi/backup-plans/internal/config"
+	"github.com/wtsi-hgi/backup-plans/internal/testdb"
+	"github.com/wtsi-hgi/backup-plans/ruletree"
+)
+
+func TestExplain(t *testing.T) {
+	Convey("With a configured backend", t, func() {
+		var u userHandler
+
+		s, err := New(testdb.CreateTestDatabase(t), u.getUser, config.NewConfig(t, nil, nil, nil, 0, nil))
+		So(err, ShouldBeNil)
+
+		Reset(s.Stop)
+
+		So(s.AddTree(createTestTree(t)), ShouldBeNil)
+
+		const (
+			dir  = "/some/path/MyDir/"
+			path = dir + "a.txt"
+		)
+
+		explain := func() *Explanation {
+			code, resp := getResponse(s.Explain, "/api/explain?path="+path, nil)
+			So(code, ShouldEqual, http.StatusOK)
+
+			var e Explanation
+
+			So(json.Unmarshal([]byte(resp), &e), ShouldBeNil)
+
+			return &e
+		}
+
+		Convey("You can only explain valid paths you are authorised to see", func() {
+			code, resp := getResponse(s.Explain, "/api/explain?path="+path, nil)
+			checkErrorResponse(t, code, resp, ErrNotAuthorised)
+
+			u = root
+
+			for _, p := range []string{"", "a.txt", dir} {
+				code, resp = getResponse(s.Explain, "/api/explain?path="+p, nil)
+				checkErrorResponse(t, code, resp, ErrInvalidPath)
+			}
+
+			e := explain()
+			So(e.Path, ShouldEqual, path)
+			So(e.BackupType, ShouldEqual, unplannedName)
+			So(e.Rule, ShouldBeNil)
+			So(e.Candidates, ShouldBeEmpty)
+		})
+
+		Convey("You can see which rule applies to a file, and which rules it beat", func() {
+			u = root
+
+			code, _ := getResponse(s.ClaimDir, "/api/dir/claim?dir="+dir, nil)
+			So(code, ShouldEqual, http.StatusOK)
+
+			code, _ = getResponse(s.CreateRule, "/api/rules/create?dir="+dir+"&action=backup&match=*.txt", nil)
+			So(code, ShouldEqual, http.StatusNoContent)
+
+			e := explain()
+			So(e.BackupType, ShouldEqual, "backup")
+			So(e.SetName, ShouldEqual, setNamePrefix+dir)
+			So(e.Rule, ShouldNotBeNil)
+			So(e.Rule.Directory, ShouldEqual, dir)
+			So(e.Rule.AppliedAt, ShouldEqual, dir)
+			So(e.Rule.Rule.Match, ShouldEqual, "*.txt")
+			So(e.Candidates, ShouldBeEmpty)
+
+			code, _ = getResponse(s.CreateRule, "/api/rules/create?dir="+dir+"&action=nobackup&match=a.*", nil)
+			So(code, ShouldEqual, http.StatusNoContent)
+
+			e = explain()
+			So(e.BackupType, ShouldEqual, "nobackup")
+			So(e.SetName, ShouldBeBlank)
+			So(e.Rule.Rule.Match, ShouldEqual, "a.*")
+			So(len(e.Candidates), ShouldEqual, 1)
+			So(e.Candidates[0].Rule.Match, ShouldEqual, "*.txt")
+			So(e.Candidates[0].Reason, ShouldEqual, ruletree.ReasonPrecedence)
+		})
+	})
+}
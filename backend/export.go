@@ -0,0 +1,598 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package backend
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wtsi-hgi/backup-plans/db"
+	"github.com/wtsi-hgi/backup-plans/ibackup"
+	"github.com/wtsi-hgi/backup-plans/odf"
+	"github.com/wtsi-hgi/backup-plans/ruletree"
+)
+
+var ErrUnknownSheet = Error{
+	Code: http.StatusBadRequest,
+	Err:  errors.New("unknown sheet"), //nolint:err113
+}
+
+const (
+	formatJSON = iota
+	formatCSV
+	formatODS
+
+	statusWeek     = 7 * 24 * 60 * 60
+	fractionDigits = 1000
+
+	programmeAll     = "All"
+	programmeUnknown = "Unknown"
+	backupUnplanned  = "unplanned"
+)
+
+var statuses = map[string]string{ //nolint:gochecknoglobals
+	odf.StyleRed:   "No backup in 6 weeks",
+	odf.StyleGreen: "Backup within 2 weeks",
+	odf.StyleBlue:  "No files to backup",
+	odf.StyleAmber: "No backup in 2 weeks",
+}
+
+// ReportExport is the exported form of the report summary.
+type ReportExport struct {
+	Directories []ReportRow
+	Programmes  []ProgrammeRow
+}
+
+// ReportRow summarises the sizes of the files, by backup type, beneath a
+// reporting root.
+type ReportRow struct {
+	Programme    string
+	Faculty      string
+	Path         string
+	Group        string
+	Status       string
+	Unplanned    uint64
+	NoBackup     uint64
+	Backup       uint64
+	ManualBackup uint64
+
+	status string
+}
+
+// ProgrammeRow summarises the files, by backup type, owned by the groups of a
+// programme (BOM). The 'All' programme totals every group.
+type ProgrammeRow struct {
+	Programme         string
+	Unplanned         SizeCount
+	UnplannedFraction float64
+	NoBackup          SizeCount
+	Backup            SizeCount
+	ManualBackup      SizeCount
+}
+
+// ClaimStatsRow summarises the files matched by a rule in a claimed directory,
+// along with the activity of the backup they belong to.
+type ClaimStatsRow struct {
+	Path       string
+	ClaimedBy  string
+	Group      string
+	Match      string
+	BackupType string
+	BackupName string
+	Files      uint64
+	Size       uint64
+	LastBackup time.Time
+	Failures   int64
+	Stale      bool
+}
+
+// SummaryJSON is an HTTP endpoint that produces the report summary as a JSON
+// encoded ReportExport.
+func (s *Server) SummaryJSON(w http.ResponseWriter, r *http.Request) {
+	handle(w, r, func(w http.ResponseWriter, r *http.Request) error {
+		return s.exportSummary(w, r, formatJSON)
+	})
+}
+
+// SummaryCSV is an HTTP endpoint that produces the report summary as a CSV
+// file. By default, the per-directory 'Backup Plans' sheet is produced; the
+// 'sheet' query parameter can be set to 'summary' to produce the per-programme
+// totals instead.
+func (s *Server) SummaryCSV(w http.ResponseWriter, r *http.Request) {
+	handle(w, r, func(w http.ResponseWriter, r *http.Request) error {
+		return s.exportSummary(w, r, formatCSV)
+	})
+}
+
+// SummaryODS is an HTTP endpoint that produces the report summary as an ODS
+// spreadsheet, matching the spreadsheet downloadable from the report page.
+func (s *Server) SummaryODS(w http.ResponseWriter, r *http.Request) {
+	handle(w, r, func(w http.ResponseWriter, r *http.Request) error {
+		return s.exportSummary(w, r, formatODS)
+	})
+}
+
+func (s *Server) exportSummary(w http.ResponseWriter, r *http.Request, format int) error {
+	report, err := s.reportExport()
+	if err != nil {
+		return err
+	}
+
+	return writeExport(w, r, format, "backup-report", report, reportDirectoriesTable(report.Directories),
+		reportProgrammesTable(report.Programmes))
+}
+
+func (s *Server) reportExport() (*ReportExport, error) {
+	s.rulesMu.RLock()
+	defer s.rulesMu.RUnlock()
+
+	dirSummary, err := s.collectSummary()
+	if err != nil {
+		return nil, err
+	}
+
+	boms := s.reverseBOMMap(s.config.GetBOMs())
+	owners := s.reverseBOMMap(s.config.GetOwners())
+	now := time.Now().Unix()
+
+	report := &ReportExport{
+		Directories: make([]ReportRow, 0, len(dirSummary.Summaries)),
+		Programmes:  programmeRows(dirSummary.GroupBackupTypeTotals, boms),
+	}
+
+	for path, ds := range dirSummary.Summaries {
+		row := s.reportRow(path, ds.Group, ds.RuleSummaries, now)
+		row.Programme = boms[ds.Group]
+		row.Faculty = owners[ds.Group]
+
+		report.Directories = append(report.Directories, row)
+	}
+
+	slices.SortFunc(report.Directories, func(a, b ReportRow) int { return strings.Compare(a.Path, b.Path) })
+
+	return report, nil
+}
+
+type sizeCountTime struct {
+	SizeCount
+	mtime uint64
+}
+
+func (s *Server) reportRow(path, group string, rules []ruletree.Rule, now int64) ReportRow {
+	var (
+		types       [int(db.BackupManualNFS) + 2]sizeCountTime
+		latestMTime uint64
+		count       uint64
+	)
+
+	for _, rs := range rules {
+		typ := s.getBackupTypeForTotals(rs.ID) + 1
+
+		for _, stats := range rs.Users {
+			types[typ].Count += stats.Files
+			types[typ].Size += stats.Size
+			types[typ].mtime = max(types[typ].mtime, stats.MTime)
+			latestMTime = max(latestMTime, stats.MTime)
+			count += stats.Files
+		}
+	}
+
+	row := ReportRow{
+		Path:      path,
+		Group:     group,
+		Unplanned: types[unplanned+1].Size,
+		NoBackup:  types[db.BackupNone+1].Size,
+		Backup:    types[db.BackupIBackup+1].Size,
+		status:    reportStatus(types[db.BackupNone+1].Count, count, types[db.BackupIBackup+1].mtime, latestMTime, now),
+	}
+
+	for _, sct := range types[db.BackupManualIBackup+1:] {
+		row.ManualBackup += sct.Size
+	}
+
+	row.Status = statuses[row.status]
+
+	return row
+}
+
+// reportStatus matches the status shown for a directory on the report page:
+// blue when there is nothing to be backed up, otherwise green, amber or red
+// depending on how far the last backed up file lags behind the latest
+// activity.
+func reportStatus(noBackupCount, count, backupMTime, latestMTime uint64, now int64) string {
+	if noBackupCount == count {
+		return odf.StyleBlue
+	}
+
+	lastActivity := max(now-int64(latestMTime), 0) //nolint:gosec
+	dt := now - int64(backupMTime) - lastActivity  //nolint:gosec
+
+	switch {
+	case dt < statusWeek:
+		return odf.StyleGreen
+	case dt < 3*statusWeek:
+		return odf.StyleAmber
+	default:
+		return odf.StyleRed
+	}
+}
+
+func programmeRows(totals map[string]map[int]*SizeCount, boms map[string]string) []ProgrammeRow {
+	programmes := map[string]*ProgrammeRow{programmeAll: {Programme: programmeAll}}
+
+	for group, typeCounts := range totals {
+		bom := boms[group]
+		if bom == "" || bom == "unknown" {
+			bom = programmeUnknown
+		}
+
+		row, ok := programmes[bom]
+		if !ok {
+			row = &ProgrammeRow{Programme: bom}
+			programmes[bom] = row
+		}
+
+		for typ, sc := range typeCounts {
+			addProgrammeCounts(row, typ, sc)
+			addProgrammeCounts(programmes[programmeAll], typ, sc)
+		}
+	}
+
+	rows := make([]ProgrammeRow, 0, len(programmes))
+
+	for _, row := range programmes {
+		total := row.Unplanned.Size + row.NoBackup.Size + row.Backup.Size + row.ManualBackup.Size
+		if total > 0 {
+			row.UnplannedFraction = math.Round(fractionDigits*float64(row.Unplanned.Size)/float64(total)) / fractionDigits
+		}
+
+		rows = append(rows, *row)
+	}
+
+	slices.SortFunc(rows, func(a, b ProgrammeRow) int {
+		switch {
+		case a.Programme == programmeAll:
+			return -1
+		case b.Programme == programmeAll:
+			return 1
+		}
+
+		return strings.Compare(a.Programme, b.Programme)
+	})
+
+	return rows
+}
+
+func addProgrammeCounts(row *ProgrammeRow, typ int, sc *SizeCount) {
+	var total *SizeCount
+
+	switch {
+	case typ == unplanned:
+		total = &row.Unplanned
+	case typ == int(db.BackupNone):
+		total = &row.NoBackup
+	case typ == int(db.BackupIBackup):
+		total = &row.Backup
+	default:
+		total = &row.ManualBackup
+	}
+
+	total.Count += sc.Count
+	total.Size += sc.Size
+}
+
+func reportDirectoriesTable(rows []ReportRow) odf.Table {
+	const (
+		textColumns  = 5
+		bytesColumns = 4
+	)
+
+	t := odf.Table{
+		Name: "Backup Plans",
+		Columns: []odf.Column{
+			{Repeat: textColumns},
+			{Repeat: bytesColumns, Style: odf.StyleBytes},
+		},
+		Rows: [][]odf.Cell{headerRow("Programme", "Faculty", "Path", "Group", "Status", "Unplanned", "NoBackup",
+			"Backup", "Manual Backup")},
+		Filter: textColumns,
+	}
+
+	for _, row := range rows {
+		t.Rows = append(t.Rows, []odf.Cell{
+			odf.String(row.Programme),
+			odf.String(row.Faculty),
+			odf.String(row.Path),
+			odf.String(row.Group),
+			{Text: row.Status, Style: row.status},
+			odf.Bytes(row.Unplanned),
+			odf.Bytes(row.NoBackup),
+			odf.Bytes(row.Backup),
+			odf.Bytes(row.ManualBackup),
+		})
+	}
+
+	return t
+}
+
+func reportProgrammesTable(rows []ProgrammeRow) odf.Table {
+	t := odf.Table{
+		Name: "Summary",
+		Columns: []odf.Column{
+			{Repeat: 2}, //nolint:mnd
+			{Style: odf.StyleBytes},
+			{Repeat: 2}, //nolint:mnd
+			{Style: odf.StyleBytes},
+			{},
+			{Style: odf.StyleBytes},
+			{},
+			{Style: odf.StyleBytes},
+		},
+		Rows: [][]odf.Cell{headerRow("BOM", "Unplanned Count", "Unplanned Size", "Unplanned Fraction",
+			"NoBackup Count", "NoBackup Size", "Backup Count", "Backup Size", "Manual Backup Count",
+			"Manual Backup Size")},
+	}
+
+	for _, row := range rows {
+		t.Rows = append(t.Rows, []odf.Cell{
+			odf.String(row.Programme),
+			odf.Count(row.Unplanned.Count),
+			odf.Bytes(row.Unplanned.Size),
+			odf.Float(row.UnplannedFraction),
+			odf.Count(row.NoBackup.Count),
+			odf.Bytes(row.NoBackup.Size),
+			odf.Count(row.Backup.Count),
+			odf.Bytes(row.Backup.Size),
+			odf.Count(row.ManualBackup.Count),
+			odf.Bytes(row.ManualBackup.Size),
+		})
+	}
+
+	return t
+}
+
+// ClaimStatsJSON is an HTTP endpoint that produces the rules of the claimed
+// directories, filtered as with the ClaimStats endpoint, as a JSON encoded
+// slice of ClaimStatsRow.
+func (s *Server) ClaimStatsJSON(w http.ResponseWriter, r *http.Request) {
+	handle(w, r, func(w http.ResponseWriter, r *http.Request) error {
+		return s.exportClaimStats(w, r, formatJSON)
+	})
+}
+
+// ClaimStatsCSV is an HTTP endpoint that produces the rules of the claimed
+// directories, filtered as with the ClaimStats endpoint, as a CSV file.
+func (s *Server) ClaimStatsCSV(w http.ResponseWriter, r *http.Request) {
+	handle(w, r, func(w http.ResponseWriter, r *http.Request) error {
+		return s.exportClaimStats(w, r, formatCSV)
+	})
+}
+
+// ClaimStatsODS is an HTTP endpoint that produces the rules of the claimed
+// directories, filtered as with the ClaimStats endpoint, as an ODS spreadsheet.
+func (s *Server) ClaimStatsODS(w http.ResponseWriter, r *http.Request) {
+	handle(w, r, func(w http.ResponseWriter, r *http.Request) error {
+		return s.exportClaimStats(w, r, formatODS)
+	})
+}
+
+func (s *Server) exportClaimStats(w http.ResponseWriter, r *http.Request, format int) error {
+	rows := s.claimStatsRows(createClaimstatsFilter(r))
+
+	return writeExport(w, r, format, "claimstats", rows, claimStatsTable(rows))
+}
+
+func (s *Server) claimStatsRows(f filter) []ClaimStatsRow {
+	s.rulesMu.RLock()
+	claimstats := s.collectDirStats(f)
+	s.rulesMu.RUnlock()
+
+	slices.SortFunc(claimstats, func(a, b DirStats) int { return strings.Compare(a.Path, b.Path) })
+
+	rows := make([]ClaimStatsRow, 0, len(claimstats))
+
+	for _, ds := range claimstats {
+		for _, rs := range ds.RuleStats {
+			rows = append(rows, claimStatsRow(&ds, rs))
+		}
+	}
+
+	return rows
+}
+
+func claimStatsRow(ds *DirStats, rs ruleStats) ClaimStatsRow {
+	row := ClaimStatsRow{
+		Path:       ds.Path,
+		ClaimedBy:  ds.ClaimedBy,
+		Group:      ds.Group,
+		BackupType: backupUnplanned,
+		Files:      rs.Count,
+		Size:       rs.Size,
+	}
+
+	if rs.Rule == nil {
+		return row
+	}
+
+	row.Match = rs.Match
+	row.BackupType = rs.BackupType.Name()
+
+	switch {
+	case rs.BackupType == db.BackupIBackup:
+		row.BackupName = setNamePrefix + ds.Path
+	case db.IsManual(rs.BackupType):
+		row.BackupName = rs.Metadata
+	default:
+		return row
+	}
+
+	for _, sba := range ds.BackupStatus {
+		if sba.Name == row.BackupName {
+			setClaimStatsActivity(&row, sba)
+
+			break
+		}
+	}
+
+	return row
+}
+
+func setClaimStatsActivity(row *ClaimStatsRow, sba ibackup.SetBackupActivity) {
+	row.LastBackup = sba.LastSuccess
+	row.Failures = sba.Failures
+	row.Stale = sba.Stale
+}
+
+func claimStatsTable(rows []ClaimStatsRow) odf.Table {
+	const textColumns = 6
+
+	t := odf.Table{
+		Name: "Claimed Directories",
+		Columns: []odf.Column{
+			{Repeat: textColumns + 1},
+			{Style: odf.StyleBytes},
+			{Repeat: 3}, //nolint:mnd
+		},
+		Rows: [][]odf.Cell{headerRow("Path", "Claimed By", "Group", "Match", "Backup Type", "Backup Name",
+			"Files", "Size", "Last Backup", "Failures", "Stale")},
+		Filter: textColumns,
+	}
+
+	for _, row := range rows {
+		var lastBackup string
+
+		failures := strconv.FormatInt(row.Failures, 10)
+
+		if !row.LastBackup.IsZero() {
+			lastBackup = row.LastBackup.Format(time.RFC3339)
+		}
+
+		t.Rows = append(t.Rows, []odf.Cell{
+			odf.String(row.Path),
+			odf.String(row.ClaimedBy),
+			odf.String(row.Group),
+			odf.String(row.Match),
+			odf.String(row.BackupType),
+			odf.String(row.BackupName),
+			odf.Count(row.Files),
+			odf.Bytes(row.Size),
+			odf.String(lastBackup),
+			{Text: failures, Value: failures},
+			odf.String(strconv.FormatBool(row.Stale)),
+		})
+	}
+
+	return t
+}
+
+func headerRow(names ...string) []odf.Cell {
+	row := make([]odf.Cell, len(names))
+
+	for n, name := range names {
+		row[n] = odf.String(name)
+	}
+
+	return row
+}
+
+// writeExport writes the given data in the requested format. JSON exports
+// encode data directly, ODS exports contain all of the given tables, and CSV
+// exports contain the table named by the 'sheet' query parameter, defaulting
+// to the first.
+func writeExport(w http.ResponseWriter, r *http.Request, format int, name string, data any,
+	tables ...odf.Table,
+) error {
+	filename := name + "-" + time.Now().Format(time.DateOnly)
+
+	switch format {
+	case formatCSV:
+		t, err := selectSheet(r.FormValue("sheet"), tables)
+		if err != nil {
+			return err
+		}
+
+		setDownloadHeaders(w, "text/csv; charset=utf-8", filename+".csv")
+
+		return writeCSV(w, t)
+	case formatODS:
+		setDownloadHeaders(w, "application/vnd.oasis.opendocument.spreadsheet", filename+".ods")
+
+		return odf.WriteSpreadsheet(w, tables...)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+
+		return json.NewEncoder(w).Encode(data)
+	}
+}
+
+func selectSheet(sheet string, tables []odf.Table) (odf.Table, error) {
+	if sheet == "" {
+		return tables[0], nil
+	}
+
+	for _, t := range tables {
+		if strings.EqualFold(t.Name, sheet) || strings.EqualFold(strings.ReplaceAll(t.Name, " ", ""), sheet) {
+			return t, nil
+		}
+	}
+
+	return odf.Table{}, ErrUnknownSheet
+}
+
+func setDownloadHeaders(w http.ResponseWriter, contentType, filename string) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+}
+
+// writeCSV writes the raw values of a table, so that sizes are given in bytes
+// rather than in their human readable form.
+func writeCSV(w http.ResponseWriter, t odf.Table) error {
+	cw := csv.NewWriter(w)
+
+	for _, row := range t.Rows {
+		record := make([]string, len(row))
+
+		for n, cell := range row {
+			record[n] = cmp.Or(cell.Value, cell.Text)
+		}
+
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
@@ -0,0 +1,223 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package backend
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/wtsi-hgi/backup-plans/internal/config"
+	"github.com/wtsi-hgi/backup-plans/internal/plandb"
+	"vimagination.zapto.org/tree"
+)
+
+func TestExport(t *testing.T) {
+	Convey("With a configured backend with reporting roots", t, func() {
+		firstGroup, err := user.LookupGroupId("1")
+		So(err, ShouldBeNil)
+
+		testDB, _ := plandb.PopulateExamplePlanDB(t)
+		tr := plandb.ExampleTree()
+
+		treeFile := filepath.Join(t.TempDir(), "tree.db")
+		f, err := os.Create(treeFile)
+		So(err, ShouldBeNil)
+
+		So(tree.Serialise(f, tr), ShouldBeNil)
+		So(f.Close(), ShouldBeNil)
+
+		roots := []string{
+			"/lustre/scratch123/humgen/a/b/",
+			"/lustre/scratch123/humgen/a/c/",
+		}
+
+		s, err := New(testDB, func(_ *http.Request) string { return userA }, config.NewConfig(t,
+			map[string][]string{"BOM1": {firstGroup.Name}},
+			map[string][]string{"Faculty1": {firstGroup.Name}},
+			roots, 0, nil))
+		So(err, ShouldBeNil)
+
+		So(s.AddTree(treeFile), ShouldBeNil)
+
+		Convey("The report summary can be exported as JSON", func() {
+			code, resp := getResponse(s.SummaryJSON, "/api/report/summary.json", nil)
+			So(code, ShouldEqual, http.StatusOK)
+
+			var report ReportExport
+
+			So(json.Unmarshal([]byte(resp), &report), ShouldBeNil)
+			So(report.Directories, ShouldResemble, []ReportRow{
+				{
+					Programme: "BOM1",
+					Faculty:   "Faculty1",
+					Path:      roots[0],
+					Group:     firstGroup.Name,
+					Status:    "Backup within 2 weeks",
+					Unplanned: 14,
+					NoBackup:  8,
+					Backup:    17,
+				},
+				{
+					Programme:    "BOM1",
+					Faculty:      "Faculty1",
+					Path:         roots[1],
+					Group:        firstGroup.Name,
+					Status:       "Backup within 2 weeks",
+					ManualBackup: 6,
+				},
+			})
+			So(report.Programmes, ShouldResemble, []ProgrammeRow{
+				{
+					Programme:         programmeAll,
+					Unplanned:         SizeCount{Count: 2, Size: 14},
+					UnplannedFraction: 0.311,
+					NoBackup:          SizeCount{Count: 1, Size: 8},
+					Backup:            SizeCount{Count: 2, Size: 17},
+					ManualBackup:      SizeCount{Count: 1, Size: 6},
+				},
+				{
+					Programme:         "BOM1",
+					Unplanned:         SizeCount{Count: 1, Size: 6},
+					UnplannedFraction: 0.286,
+					Backup:            SizeCount{Count: 1, Size: 9},
+					ManualBackup:      SizeCount{Count: 1, Size: 6},
+				},
+				{
+					Programme:         programmeUnknown,
+					Unplanned:         SizeCount{Count: 1, Size: 8},
+					UnplannedFraction: 0.333,
+					NoBackup:          SizeCount{Count: 1, Size: 8},
+					Backup:            SizeCount{Count: 1, Size: 8},
+				},
+			})
+		})
+
+		Convey("The report summary can be exported as CSV, selecting the sheet", func() {
+			records := getCSV(s.SummaryCSV, "/api/report/summary.csv")
+			So(records, ShouldResemble, [][]string{
+				{"Programme", "Faculty", "Path", "Group", "Status", "Unplanned", "NoBackup", "Backup", "Manual Backup"},
+				{"BOM1", "Faculty1", roots[0], firstGroup.Name, "Backup within 2 weeks", "14", "8", "17", "0"},
+				{"BOM1", "Faculty1", roots[1], firstGroup.Name, "Backup within 2 weeks", "0", "0", "0", "6"},
+			})
+
+			records = getCSV(s.SummaryCSV, "/api/report/summary.csv?sheet=summary")
+			So(len(records), ShouldEqual, 4)
+			So(records[1], ShouldResemble, []string{"All", "2", "14", "0.311", "1", "8", "2", "17", "1", "6"})
+
+			code, resp := getResponse(s.SummaryCSV, "/api/report/summary.csv?sheet=unknown", nil)
+			checkErrorResponse(t, code, resp, ErrUnknownSheet)
+		})
+
+		Convey("The report summary can be exported as an ODS spreadsheet", func() {
+			content := getODSContent(s.SummaryODS, "/api/report/summary.ods")
+			So(content, ShouldContainSubstring, `<table:table table:name="Backup Plans">`)
+			So(content, ShouldContainSubstring, `<table:table table:name="Summary">`)
+			So(content, ShouldContainSubstring,
+				`<table:table-cell office:value-type="string" table:style-name="g"><text:p>Backup within 2 weeks</text:p>`)
+			So(content, ShouldContainSubstring,
+				`<table:table-cell office:value="17" office:value-type="float"><text:p>17B</text:p>`)
+			So(content, ShouldContainSubstring, `table:target-range-address="&#39;Backup Plans&#39;.A1:&#39;Backup Plans&#39;.E3"`)
+		})
+
+		Convey("Claim stats can be exported, filtered by user or group", func() {
+			code, resp := getResponse(s.ClaimStatsJSON, "/api/claimstats.json?user=userA", nil)
+			So(code, ShouldEqual, http.StatusOK)
+
+			var rows []ClaimStatsRow
+
+			So(json.Unmarshal([]byte(resp), &rows), ShouldBeNil)
+			So(rows, ShouldResemble, []ClaimStatsRow{
+				{
+					Path: roots[0], ClaimedBy: userA, Group: firstGroup.Name, BackupType: backupUnplanned,
+					Files: 2, Size: 14,
+				},
+				{
+					Path: roots[0], ClaimedBy: userA, Group: firstGroup.Name, Match: "*.jpg", BackupType: "backup",
+					BackupName: setNamePrefix + roots[0], Files: 2, Size: 17,
+				},
+				{
+					Path: roots[0], ClaimedBy: userA, Group: firstGroup.Name, Match: "temp.jpg", BackupType: "nobackup",
+					Files: 1, Size: 8,
+				},
+			})
+
+			records := getCSV(s.ClaimStatsCSV, "/api/claimstats.csv?groupbom=BOM1")
+			So(len(records), ShouldEqual, 5)
+			So(records[4], ShouldResemble, []string{
+				roots[1], userB, firstGroup.Name, "*.txt", "manualibackup", "manualSetName", "1", "6", "", "0", "false",
+			})
+
+			content := getODSContent(s.ClaimStatsODS, "/api/claimstats.ods?user=userB")
+			So(content, ShouldContainSubstring, `<table:table table:name="Claimed Directories">`)
+			So(content, ShouldContainSubstring, `<text:p>manualSetName</text:p>`)
+			So(content, ShouldNotContainSubstring, `<text:p>temp.jpg</text:p>`)
+		})
+	})
+}
+
+func getCSV(fn http.HandlerFunc, u string) [][]string {
+	code, resp := getResponse(fn, u, nil)
+	So(code, ShouldEqual, http.StatusOK)
+
+	records, err := csv.NewReader(strings.NewReader(resp)).ReadAll()
+	So(err, ShouldBeNil)
+
+	return records
+}
+
+func getODSContent(fn http.HandlerFunc, u string) string {
+	w := httptest.NewRecorder()
+
+	fn(w, httptest.NewRequest(http.MethodGet, u, nil))
+
+	So(w.Code, ShouldEqual, http.StatusOK)
+	So(w.Header().Get("Content-Type"), ShouldEqual, "application/vnd.oasis.opendocument.spreadsheet")
+	So(w.Header().Get("Content-Disposition"), ShouldStartWith, "attachment; filename=")
+
+	body := w.Body.Bytes()
+
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	So(err, ShouldBeNil)
+
+	r, err := zr.Open("content.xml")
+	So(err, ShouldBeNil)
+
+	content, err := io.ReadAll(r)
+	So(err, ShouldBeNil)
+
+	return string(content)
+}
@@ -0,0 +1,199 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package backend
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/wtsi-hgi/backup-plans/ruletree"
+	"github.com/wtsi-hgi/backup-plans/users"
+)
+
+var ErrInvalidLimit = Error{
+	Code: http.StatusBadRequest,
+	Err:  errors.New("invalid limit or offset"), //nolint:err113
+}
+
+const (
+	defaultFileLimit = 100
+	maxFileLimit     = 1000
+	maxFileOffset    = 10000
+)
+
+// FileList is a page of the files matched by a rule, along with the total
+// number of matching files.
+type FileList struct {
+	Total int
+	Files []File
+}
+
+// File contains the path and stats of a file.
+type File struct {
+	Path     string
+	Size     uint64
+	MTime    uint64
+	UID, GID uint32
+}
+
+// Files is an HTTP endpoint that lists the files beneath a directory that are
+// matched by one of its rules.
+//
+// The 'dir' GET param specifies the directory and the 'rule' param the match
+// string of the rule. When 'rule' is not given, the files without a rule are
+// listed.
+//
+// Files are sorted by size, largest first, and paginated with the optional
+// 'offset' and 'limit' params; the limit defaults to 100, with a maximum of
+// 1000, and the offset can be no more than 10000.
+//
+// The response is a JSON encoded FileList.
+func (s *Server) Files(w http.ResponseWriter, r *http.Request) {
+	handle(w, r, s.files)
+}
+
+func (s *Server) files(w http.ResponseWriter, r *http.Request) error {
+	dir, err := getDir(r)
+	if err != nil {
+		return err
+	}
+
+	offset, limit, err := getPage(r)
+	if err != nil {
+		return err
+	}
+
+	ruleID, err := s.getFilesRule(r, dir)
+	if err != nil {
+		return err
+	}
+
+	fl := FileList{Files: []File{}}
+	keep := offset + limit
+
+	if err := s.rootDir.Files(dir, ruleID, func(path string, f ruletree.File) {
+		fl.Total++
+
+		fl.Files = append(fl.Files, File{Path: path, Size: f.Size, MTime: f.MTime, UID: f.UID, GID: f.GID})
+
+		if len(fl.Files) >= 2*keep {
+			fl.Files = largestFiles(fl.Files, keep)
+		}
+	}); err != nil {
+		return err
+	}
+
+	fl.Files = largestFiles(fl.Files, keep)
+	fl.Files = fl.Files[min(offset, len(fl.Files)):]
+
+	w.Header().Set("Content-Type", "application/json")
+
+	return json.NewEncoder(w).Encode(fl)
+}
+
+// getFilesRule checks that the user can see the given directory, and returns
+// the ID of the requested rule.
+func (s *Server) getFilesRule(r *http.Request, dir string) (int64, error) {
+	uid, groups := users.GetIDs(s.getUser(r))
+	if len(groups) == 0 {
+		return 0, ErrNotAuthorised
+	}
+
+	s.rulesMu.RLock()
+	defer s.rulesMu.RUnlock()
+
+	summary, err := s.rootDir.Summary(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	if !isAuthorised(summary, uid, groups, s.config.GetAdminGroup()) {
+		return 0, ErrNotAuthorised
+	}
+
+	if !r.Form.Has("rule") {
+		return 0, nil
+	}
+
+	directory, ok := s.directoryRules[dir]
+	if !ok {
+		return 0, ErrDirectoryNotClaimed
+	}
+
+	rule, ok := directory.Rules[r.FormValue("rule")]
+	if !ok {
+		return 0, ErrNoRule
+	}
+
+	return rule.ID(), nil
+}
+
+func getPage(r *http.Request) (int, int, error) {
+	offset, err := getPageParam(r, "offset", 0)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	limit, err := getPageParam(r, "limit", defaultFileLimit)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if limit == 0 || limit > maxFileLimit || offset > maxFileOffset {
+		return 0, 0, ErrInvalidLimit
+	}
+
+	return offset, limit, nil
+}
+
+func getPageParam(r *http.Request, param string, def int) (int, error) {
+	str := r.FormValue(param)
+	if str == "" {
+		return def, nil
+	}
+
+	n, err := strconv.ParseUint(str, 10, 31)
+	if err != nil {
+		return 0, ErrInvalidLimit
+	}
+
+	return int(n), nil
+}
+
+// largestFiles sorts the given files by size, largest first, and returns no
+// more than the given number of them.
+func largestFiles(files []File, n int) []File {
+	slices.SortFunc(files, func(a, b File) int {
+		return cmp.Or(cmp.Compare(b.Size, a.Size), strings.Compare(a.Path, b.Path))
+	})
+
+	return files[:min(n, len(files))]
+}
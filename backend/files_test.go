@@ -0,0 +1,117 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package backend
+
+import (
+	"encoding/json"
+	"net/http"
+	"os/user"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/wtsi-hgi/backup-plans/internal/config"
+	"github.com/wtsi-hgi/backup-plans/internal/testdb"
+	"github.com/wtsi-hgi/backup-plans/users"
+)
+
+func TestFiles(t *testing.T) {
+	Convey("With a configured backend", t, func() {
+		var u userHandler
+
+		cu, err := user.Current()
+		So(err, ShouldBeNil)
+
+		uid, _ := users.GetIDs(cu.Username)
+
+		s, err := New(testdb.CreateTestDatabase(t), u.getUser, config.NewConfig(t, nil, nil, nil, 0, nil))
+		So(err, ShouldBeNil)
+
+		Reset(s.Stop)
+
+		So(s.AddTree(createTestTree(t)), ShouldBeNil)
+
+		files := func(query string) *FileList {
+			code, resp := getResponse(s.Files, "/api/files?"+query, nil)
+			So(code, ShouldEqual, http.StatusOK)
+
+			var fl FileList
+
+			So(json.Unmarshal([]byte(resp), &fl), ShouldBeNil)
+
+			return &fl
+		}
+
+		a := File{Path: "/some/path/MyDir/a.txt", Size: 3, MTime: 4, UID: 0, GID: 2}
+		b := File{Path: "/some/path/MyDir/b.csv", Size: 5, MTime: 6, UID: uid, GID: 2}
+
+		Convey("You can only list files in directories you are authorised to see", func() {
+			code, resp := getResponse(s.Files, "/api/files?dir=/some/path/MyDir/", nil)
+			checkErrorResponse(t, code, resp, ErrNotAuthorised)
+
+			u = root
+
+			So(files("dir=/some/path/MyDir/"), ShouldResemble, &FileList{Total: 2, Files: []File{b, a}})
+		})
+
+		Convey("You can page through the files, largest first", func() {
+			u = root
+
+			So(files("dir=/some/path/&limit=2"), ShouldResemble, &FileList{
+				Total: 7,
+				Files: []File{
+					{Path: "/some/path/ChildDir/Child/a.file", Size: 35, MTime: 36, UID: uid, GID: 2},
+					{Path: "/some/path/ChildDir/a.txt", Size: 35, MTime: 36, UID: uid, GID: 2},
+				},
+			})
+			So(files("dir=/some/path/MyDir/&offset=1&limit=1"), ShouldResemble, &FileList{Total: 2, Files: []File{a}})
+			So(files("dir=/some/path/MyDir/&offset=2"), ShouldResemble, &FileList{Total: 2, Files: []File{}})
+
+			for _, query := range []string{"limit=0", "limit=1001", "limit=a", "offset=-1", "offset=10001"} {
+				code, resp := getResponse(s.Files, "/api/files?dir=/some/path/MyDir/&"+query, nil)
+				checkErrorResponse(t, code, resp, ErrInvalidLimit)
+			}
+		})
+
+		Convey("You can list the files matched by a rule", func() {
+			u = root
+
+			code, resp := getResponse(s.Files, "/api/files?dir=/some/path/MyDir/&rule=*.txt", nil)
+			checkErrorResponse(t, code, resp, ErrDirectoryNotClaimed)
+
+			code, _ = getResponse(s.ClaimDir, "/api/dir/claim?dir=/some/path/MyDir/", nil)
+			So(code, ShouldEqual, http.StatusOK)
+
+			code, _ = getResponse(s.CreateRule, "/api/rules/create?dir=/some/path/MyDir/&action=backup&match=*.txt", nil)
+			So(code, ShouldEqual, http.StatusNoContent)
+
+			So(files("dir=/some/path/MyDir/&rule=*.txt"), ShouldResemble, &FileList{Total: 1, Files: []File{a}})
+			So(files("dir=/some/path/MyDir/"), ShouldResemble, &FileList{Total: 1, Files: []File{b}})
+
+			code, resp = getResponse(s.Files, "/api/files?dir=/some/path/MyDir/&rule=*.csv", nil)
+			checkErrorResponse(t, code, resp, ErrNoRule)
+		})
+	})
+}
@@ -0,0 +1,271 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package backend
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/wtsi-hgi/backup-plans/db"
+	"github.com/wtsi-hgi/backup-plans/ruletree"
+)
+
+// ReportSnapshot contains the totals, by backup type, of the reporting roots,
+// groups and BOMs at the time a batch of trees was loaded.
+type ReportSnapshot struct {
+	ID     int64
+	Time   int64
+	Roots  map[string]map[int]*SizeCount
+	Groups map[string]map[int]*SizeCount `json:",omitempty"`
+	BOMs   map[string]map[int]*SizeCount `json:",omitempty"`
+}
+
+// SnapshotReport stores the current totals of the reporting roots, groups and
+// BOMs in the database, for the ReportHistory endpoint.
+//
+// It should be called once after each batch of trees is loaded, so that each
+// snapshot covers all of the loaded trees. Snapshots older than the configured
+// report history are removed.
+func (s *Server) SnapshotReport() error {
+	s.rulesMu.RLock()
+	totals, err := s.reportTotals()
+	s.rulesMu.RUnlock()
+
+	if err != nil {
+		return err
+	}
+
+	if err := s.rulesDB.AddReportSnapshot(totals); err != nil {
+		return err
+	}
+
+	if keep := s.config.GetReportHistory(); keep > 0 {
+		return s.rulesDB.RemoveReportSnapshots(time.Now().Add(-keep).Unix())
+	}
+
+	return nil
+}
+
+// reportTotals returns the current totals, by backup type, of the reporting
+// roots, groups and BOMs.
+//
+// The caller must hold a read lock on rulesMu.
+func (s *Server) reportTotals() ([]*db.ReportTotal, error) {
+	summary := &ReportSummary{GroupBackupTypeTotals: make(map[string]map[int]*SizeCount)}
+
+	if err := s.collectBackupTotals(summary); err != nil {
+		return nil, err
+	}
+
+	var totals []*db.ReportTotal
+
+	for _, root := range s.rootDir.GlobPaths(s.config.GetReportingRoots()...) {
+		ds, err := s.getRootSummary(root)
+		if err != nil {
+			return nil, err
+		} else if ds == nil {
+			continue
+		}
+
+		totals = appendTotals(totals, db.TotalRoot, root, s.ruleSummaryTotals(ds.RuleSummaries))
+	}
+
+	boms := make(map[string]map[int]*SizeCount)
+	reverseBOMs := s.reverseBOMMap(s.config.GetBOMs())
+
+	for group, groupTotals := range summary.GroupBackupTypeTotals {
+		totals = appendTotals(totals, db.TotalGroup, group, groupTotals)
+
+		if bom, ok := reverseBOMs[group]; ok {
+			addSizeCounts(boms, bom, groupTotals)
+		}
+	}
+
+	for bom, bomTotals := range boms {
+		totals = appendTotals(totals, db.TotalBOM, bom, bomTotals)
+	}
+
+	return totals, nil
+}
+
+func (s *Server) ruleSummaryTotals(rules []ruletree.Rule) map[int]*SizeCount {
+	totals := make(map[int]*SizeCount)
+
+	for _, rule := range rules {
+		backupType := s.getBackupTypeForTotals(rule.ID)
+
+		counts, ok := totals[backupType]
+		if !ok {
+			counts = new(SizeCount)
+			totals[backupType] = counts
+		}
+
+		for _, group := range rule.Groups {
+			counts.Count += group.Files
+			counts.Size += group.Size
+		}
+	}
+
+	return totals
+}
+
+func addSizeCounts(totals map[string]map[int]*SizeCount, name string, add map[int]*SizeCount) {
+	named, ok := totals[name]
+	if !ok {
+		named = make(map[int]*SizeCount)
+		totals[name] = named
+	}
+
+	for backupType, sc := range add {
+		counts, ok := named[backupType]
+		if !ok {
+			counts = new(SizeCount)
+			named[backupType] = counts
+		}
+
+		counts.Count += sc.Count
+		counts.Size += sc.Size
+	}
+}
+
+func appendTotals(totals []*db.ReportTotal, kind, name string, counts map[int]*SizeCount) []*db.ReportTotal {
+	for backupType, sc := range counts {
+		totals = append(totals, &db.ReportTotal{
+			Kind:       kind,
+			Name:       name,
+			BackupType: backupType,
+			Count:      sc.Count,
+			Size:       sc.Size,
+		})
+	}
+
+	return totals
+}
+
+// ReportHistory is an HTTP endpoint that returns the snapshots of the report
+// totals taken each time a batch of trees was loaded, as a JSON encoded slice of
+// ReportSnapshot, oldest first.
+//
+// The optional 'from' and 'to' GET params limit the snapshots to those taken
+// between the given unix times, inclusive. The optional 'root' GET param limits
+// the totals to those of the given reporting root, omitting the group and BOM
+// totals.
+func (s *Server) ReportHistory(w http.ResponseWriter, r *http.Request) {
+	handle(w, r, s.reportHistory)
+}
+
+func (s *Server) reportHistory(w http.ResponseWriter, r *http.Request) error {
+	from, err := getTimeParam(r, "from", 0)
+	if err != nil {
+		return err
+	}
+
+	to, err := getTimeParam(r, "to", math.MaxInt64)
+	if err != nil {
+		return err
+	}
+
+	root := r.FormValue("root")
+	snapshots := []*ReportSnapshot{}
+
+	var snapshot *ReportSnapshot
+
+	if err := s.rulesDB.ReadReportTotals(from, to).ForEach(func(t *db.ReportTotal) error {
+		if root != "" && (t.Kind != db.TotalRoot || t.Name != root) {
+			return nil
+		}
+
+		if snapshot == nil || snapshot.ID != t.Snapshot {
+			snapshot = newReportSnapshot(t, root == "")
+			snapshots = append(snapshots, snapshot)
+		}
+
+		snapshot.add(t)
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	return json.NewEncoder(w).Encode(snapshots)
+}
+
+func getTimeParam(r *http.Request, param string, def int64) (int64, error) {
+	v := r.FormValue(param)
+	if v == "" {
+		return def, nil
+	}
+
+	t, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidTime
+	}
+
+	return t, nil
+}
+
+func newReportSnapshot(t *db.ReportTotal, all bool) *ReportSnapshot {
+	snapshot := &ReportSnapshot{
+		ID:    t.Snapshot,
+		Time:  t.Created,
+		Roots: make(map[string]map[int]*SizeCount),
+	}
+
+	if all {
+		snapshot.Groups = make(map[string]map[int]*SizeCount)
+		snapshot.BOMs = make(map[string]map[int]*SizeCount)
+	}
+
+	return snapshot
+}
+
+func (r *ReportSnapshot) add(t *db.ReportTotal) {
+	var totals map[string]map[int]*SizeCount
+
+	switch t.Kind {
+	case db.TotalRoot:
+		totals = r.Roots
+	case db.TotalGroup:
+		totals = r.Groups
+	case db.TotalBOM:
+		totals = r.BOMs
+	default:
+		return
+	}
+
+	named, ok := totals[t.Name]
+	if !ok {
+		named = make(map[int]*SizeCount)
+		totals[t.Name] = named
+	}
+
+	named[t.BackupType] = &SizeCount{Count: t.Count, Size: t.Size}
+}
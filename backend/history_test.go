@@ -0,0 +1,163 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package backend
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/wtsi-hgi/backup-plans/db"
+	"github.com/wtsi-hgi/backup-plans/internal/config"
+	"github.com/wtsi-hgi/backup-plans/internal/memtree"
+	"github.com/wtsi-hgi/backup-plans/internal/plandb"
+	"github.com/wtsi-hgi/backup-plans/users"
+)
+
+func TestReportHistory(t *testing.T) {
+	Convey("Given a server with reporting roots and BOMs", t, func() {
+		testDB, _ := plandb.PopulateExamplePlanDB(t)
+		treeFile := filepath.Join(t.TempDir(), "tree")
+
+		_, dFn, err := memtree.FromTree(plandb.ExampleTree(), treeFile)
+		So(err, ShouldBeNil)
+
+		Reset(dFn)
+
+		roots := []string{"/lustre/scratch123/humgen/a/b/", "/lustre/scratch123/humgen/a/c/"}
+		boms := map[string][]string{"bomA": {users.Group(1), users.Group(2)}}
+
+		s, err := New(testDB, func(*http.Request) string { return userA },
+			config.NewConfig(t, boms, nil, roots, 0, nil))
+		So(err, ShouldBeNil)
+
+		start := time.Now().Unix()
+
+		Convey("Adding a tree does not store a snapshot", func() {
+			So(s.AddTree(treeFile), ShouldBeNil)
+
+			code, resp := getResponse(s.ReportHistory, "/api/report/history", nil)
+			So(code, ShouldEqual, http.StatusOK)
+			So(resp, ShouldEqual, "[]\n")
+		})
+
+		Convey("You can store a snapshot of the report totals", func() {
+			So(s.AddTree(treeFile), ShouldBeNil)
+			So(s.SnapshotReport(), ShouldBeNil)
+
+			code, resp := getResponse(s.ReportHistory, "/api/report/history", nil)
+			So(code, ShouldEqual, http.StatusOK)
+
+			var snapshots []ReportSnapshot
+
+			So(json.Unmarshal([]byte(resp), &snapshots), ShouldBeNil)
+			So(len(snapshots), ShouldEqual, 1)
+			So(snapshots[0].Time, ShouldBeGreaterThanOrEqualTo, start)
+			So(snapshots[0].Roots, ShouldResemble, map[string]map[int]*SizeCount{
+				"/lustre/scratch123/humgen/a/b/": {
+					unplanned:             {Count: 2, Size: 14},
+					int(db.BackupNone):    {Count: 1, Size: 8},
+					int(db.BackupIBackup): {Count: 2, Size: 17},
+				},
+				"/lustre/scratch123/humgen/a/c/": {
+					int(db.BackupManualIBackup): {Count: 1, Size: 6},
+				},
+			})
+			So(snapshots[0].Groups, ShouldResemble, map[string]map[int]*SizeCount{
+				users.Group(1): {
+					unplanned:                   {Count: 1, Size: 6},
+					int(db.BackupIBackup):       {Count: 1, Size: 9},
+					int(db.BackupManualIBackup): {Count: 1, Size: 6},
+				},
+				users.Group(2): {
+					unplanned:             {Count: 1, Size: 8},
+					int(db.BackupNone):    {Count: 1, Size: 8},
+					int(db.BackupIBackup): {Count: 1, Size: 8},
+				},
+			})
+			So(snapshots[0].BOMs, ShouldResemble, map[string]map[int]*SizeCount{
+				"bomA": {
+					unplanned:                   {Count: 2, Size: 14},
+					int(db.BackupNone):          {Count: 1, Size: 8},
+					int(db.BackupIBackup):       {Count: 2, Size: 17},
+					int(db.BackupManualIBackup): {Count: 1, Size: 6},
+				},
+			})
+
+			Convey("with snapshots taken in the same second kept separate", func() {
+				So(s.SnapshotReport(), ShouldBeNil)
+
+				code, resp := getResponse(s.ReportHistory, "/api/report/history", nil)
+				So(code, ShouldEqual, http.StatusOK)
+
+				var more []ReportSnapshot
+
+				So(json.Unmarshal([]byte(resp), &more), ShouldBeNil)
+				So(len(more), ShouldEqual, 2)
+				So(more[0].ID, ShouldNotEqual, more[1].ID)
+				So(more[1].Roots, ShouldResemble, more[0].Roots)
+				So(more[1].Groups, ShouldResemble, more[0].Groups)
+			})
+
+			Convey("which can be limited to a single reporting root", func() {
+				code, resp := getResponse(s.ReportHistory,
+					"/api/report/history?root=/lustre/scratch123/humgen/a/c/", nil)
+				So(code, ShouldEqual, http.StatusOK)
+
+				var snapshots []ReportSnapshot
+
+				So(json.Unmarshal([]byte(resp), &snapshots), ShouldBeNil)
+				So(len(snapshots), ShouldEqual, 1)
+				So(snapshots[0].Roots, ShouldResemble, map[string]map[int]*SizeCount{
+					"/lustre/scratch123/humgen/a/c/": {
+						int(db.BackupManualIBackup): {Count: 1, Size: 6},
+					},
+				})
+				So(snapshots[0].Groups, ShouldBeNil)
+				So(snapshots[0].BOMs, ShouldBeNil)
+			})
+
+			Convey("which can be limited to a time range", func() {
+				code, resp := getResponse(s.ReportHistory,
+					"/api/report/history?from="+strconv.FormatInt(time.Now().Unix()+1, 10), nil)
+				So(code, ShouldEqual, http.StatusOK)
+				So(resp, ShouldEqual, "[]\n")
+
+				code, resp = getResponse(s.ReportHistory, "/api/report/history?from=0&to="+
+					strconv.FormatInt(time.Now().Unix(), 10), nil)
+				So(code, ShouldEqual, http.StatusOK)
+				So(resp, ShouldNotEqual, "[]\n")
+
+				code, resp = getResponse(s.ReportHistory, "/api/report/history?to=yesterday", nil)
+				checkErrorResponse(t, code, resp, ErrInvalidTime)
+			})
+		})
+	})
+}
@@ -0,0 +1,274 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package backend
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/wtsi-hgi/backup-plans/db"
+	"github.com/wtsi-hgi/backup-plans/ruletree"
+)
+
+var ErrInvalidOp = Error{
+	Code: http.StatusBadRequest,
+	Err:  errors.New("invalid op"), //nolint:err113
+}
+
+// RulePreview contains the counts of a directory before and after a rule
+// change.
+type RulePreview struct {
+	Before, After *PreviewCounts
+}
+
+// PreviewCounts contains the file counts and sizes, per backup type, for a
+// directory and each of its children, and the counts for each ibackup set of
+// the directory.
+type PreviewCounts struct {
+	Directory map[int]*SizeCount
+	Children  map[string]map[int]*SizeCount
+	Sets      map[string]*SizeCount
+}
+
+// PreviewRule is an HTTP endpoint that shows what a rule change would do,
+// without making the change.
+//
+// The 'op' GET param is one of 'create', 'update' or 'remove', and the other
+// params are as for CreateRule, UpdateRule and RemoveRule respectively.
+//
+// The response is a JSON encoded RulePreview, with backup types as in the
+// summary report.
+func (s *Server) PreviewRule(w http.ResponseWriter, r *http.Request) {
+	handle(w, r, s.previewRule)
+}
+
+func (s *Server) previewRule(w http.ResponseWriter, r *http.Request) error { //nolint:funlen
+	dir, err := getDir(r)
+	if err != nil {
+		return err
+	}
+
+	s.buildMu.Lock()
+	defer s.buildMu.Unlock()
+
+	directory, add, update, remove, err := s.getPreviewChange(r, dir)
+	if err != nil {
+		return err
+	}
+
+	before, err := s.rootDir.Summary(dir)
+	if err != nil {
+		return err
+	}
+
+	after := before
+
+	if len(add)+len(remove) > 0 {
+		if after, err = s.rootDir.PreviewRules(directory.Directory, add, remove); err != nil {
+			return err
+		}
+	}
+
+	s.rulesMu.RLock()
+
+	own := make(map[uint64]*db.Rule, len(directory.Rules))
+
+	for _, rule := range directory.Rules {
+		own[uint64(rule.ID())] = rule //nolint:gosec
+	}
+
+	preview := RulePreview{Before: s.previewCounts(dir, before, own)}
+
+	for _, rule := range remove {
+		delete(own, uint64(rule.ID())) //nolint:gosec
+	}
+
+	for _, rule := range slices.Concat(add, update) {
+		own[uint64(rule.ID())] = rule //nolint:gosec
+	}
+
+	preview.After = s.previewCounts(dir, after, own)
+
+	s.rulesMu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	return json.NewEncoder(w).Encode(preview)
+}
+
+// getPreviewChange returns the directory being changed, and the rules that
+// would be added, updated and removed by the change.
+func (s *Server) getPreviewChange(r *http.Request, dir string) (*Directory, []*db.Rule,
+	[]*db.Rule, []*db.Rule, error) {
+	switch r.FormValue("op") {
+	case "create":
+		return s.getPreviewCreate(r, dir)
+	case "update":
+		return s.getPreviewUpdate(r, dir)
+	case "remove":
+		directory, rule, err := s.getRuleToRemove(r, dir)
+
+		return directory, nil, nil, []*db.Rule{rule}, err
+	}
+
+	return nil, nil, nil, nil, ErrInvalidOp
+}
+
+func (s *Server) getPreviewCreate(r *http.Request, dir string) (*Directory, []*db.Rule,
+	[]*db.Rule, []*db.Rule, error) {
+	rules, err := getRuleDetails(r)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	directory, err := s.checkAddRules(r, dir, rules)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	var lastID uint64
+
+	s.rulesMu.RLock()
+
+	for id := range s.rules {
+		lastID = max(lastID, id)
+	}
+
+	s.rulesMu.RUnlock()
+
+	for n, rule := range rules {
+		rules[n] = rule.WithID(int64(lastID) + int64(n) + 1) //nolint:gosec
+	}
+
+	return directory, rules, nil, nil, nil
+}
+
+func (s *Server) getPreviewUpdate(r *http.Request, dir string) (*Directory, []*db.Rule,
+	[]*db.Rule, []*db.Rule, error) {
+	rules, err := getRuleDetails(r)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	s.rulesMu.RLock()
+	defer s.rulesMu.RUnlock()
+
+	existing, err := s.checkUpdateRules(r, dir, rules)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	for n, rule := range rules {
+		updated := *existing[n]
+		updated.BackupType = rule.BackupType
+		updated.Metadata = rule.Metadata
+		rules[n] = &updated
+	}
+
+	return s.directoryRules[dir], nil, rules, nil, nil
+}
+
+// previewCounts totals the given summary by backup type, using the given rules
+// of the directory in place of the stored rules.
+//
+// Must be called with rulesMu held.
+func (s *Server) previewCounts(dir string, ds *ruletree.DirSummary, own map[uint64]*db.Rule) *PreviewCounts {
+	pc := &PreviewCounts{
+		Directory: make(map[int]*SizeCount),
+		Children:  make(map[string]map[int]*SizeCount, len(ds.Children)),
+		Sets:      make(map[string]*SizeCount),
+	}
+
+	for _, rs := range ds.RuleSummaries {
+		rule := s.lookupRule(rs.ID, own)
+		sc := ruleSizeCount(rs)
+
+		addSizeCount(pc.Directory, previewBackupType(rule), sc)
+
+		if _, ok := own[rs.ID]; !ok {
+			continue
+		}
+
+		switch rule.BackupType { //nolint:exhaustive
+		case db.BackupIBackup:
+			addSizeCount(pc.Sets, setNamePrefix+dir, sc)
+		case db.BackupManualIBackup:
+			addSizeCount(pc.Sets, rule.Metadata, sc)
+		}
+	}
+
+	for name, child := range ds.Children {
+		totals := make(map[int]*SizeCount)
+
+		for _, rs := range child.RuleSummaries {
+			addSizeCount(totals, previewBackupType(s.lookupRule(rs.ID, own)), ruleSizeCount(rs))
+		}
+
+		pc.Children[name] = totals
+	}
+
+	return pc
+}
+
+func (s *Server) lookupRule(id uint64, own map[uint64]*db.Rule) *db.Rule {
+	if rule, ok := own[id]; ok {
+		return rule
+	}
+
+	return s.rules[id]
+}
+
+func previewBackupType(rule *db.Rule) int {
+	if rule == nil {
+		return unplanned
+	}
+
+	return int(rule.BackupType)
+}
+
+func ruleSizeCount(rs ruletree.Rule) SizeCount {
+	var sc SizeCount
+
+	for _, group := range rs.Groups {
+		sc.Count += group.Files
+		sc.Size += group.Size
+	}
+
+	return sc
+}
+
+func addSizeCount[K comparable](totals map[K]*SizeCount, key K, sc SizeCount) {
+	counts, ok := totals[key]
+	if !ok {
+		counts = new(SizeCount)
+		totals[key] = counts
+	}
+
+	counts.Count += sc.Count
+	counts.Size += sc.Size
+}
@@ -0,0 +1,132 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package backend
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/wtsi-hgi/backup-plans/db"
+	lconfig "github.com/wtsi-hgi/backup-plans/internal/config"
+	"github.com/wtsi-hgi/backup-plans/internal/testdb"
+)
+
+func TestPreviewRule(t *testing.T) {
+	Convey("Given a server with a claimed directory", t, func() {
+		u := userHandler(root)
+
+		s, err := New(testdb.CreateTestDatabase(t), u.getUser, lconfig.NewConfig(t, nil, nil, nil, 0, nil))
+		So(err, ShouldBeNil)
+
+		Reset(s.Stop)
+
+		So(s.AddTree(createTestTree(t)), ShouldBeNil)
+
+		const (
+			dir = "/some/path/MyDir/"
+			set = setNamePrefix + dir
+		)
+
+		code, _ := getResponse(s.ClaimDir, "/api/dir/claim?dir="+dir, nil)
+		So(code, ShouldEqual, http.StatusOK)
+
+		preview := func(query string) (int, string, *RulePreview) {
+			code, resp := getResponse(s.PreviewRule, "/api/rules/preview?dir="+dir+"&"+query, nil)
+
+			var p RulePreview
+
+			if code == http.StatusOK {
+				So(json.Unmarshal([]byte(resp), &p), ShouldBeNil)
+			}
+
+			return code, resp, &p
+		}
+
+		Convey("Previews require a valid op and the same permissions as the change", func() {
+			code, resp, _ := preview("op=unknown&action=backup&match=*.txt")
+			checkErrorResponse(t, code, resp, ErrInvalidOp)
+
+			code, resp, _ = preview("op=remove&match=*.txt")
+			checkErrorResponse(t, code, resp, ErrNoRule)
+
+			u = "someone"
+
+			code, resp, _ = preview("op=create&action=backup&match=*.txt")
+			checkErrorResponse(t, code, resp, ErrInvalidUser)
+		})
+
+		Convey("You can preview the creation of a rule", func() {
+			code, _, p := preview("op=create&action=backup&match=*.txt")
+			So(code, ShouldEqual, http.StatusOK)
+			So(p.Before.Directory, ShouldResemble, map[int]*SizeCount{unplanned: {Count: 2, Size: 8}})
+			So(p.Before.Sets, ShouldBeEmpty)
+			So(p.After.Directory, ShouldResemble, map[int]*SizeCount{
+				unplanned:             {Count: 1, Size: 5},
+				int(db.BackupIBackup): {Count: 1, Size: 3},
+			})
+			So(p.After.Sets, ShouldResemble, map[string]*SizeCount{set: {Count: 1, Size: 3}})
+			So(p.After.Children, ShouldContainKey, "ChildToClaim/")
+
+			So(s.directoryRules[dir].Rules, ShouldBeEmpty)
+
+			summary, err := s.rootDir.Summary(dir)
+			So(err, ShouldBeNil)
+			So(len(summary.RuleSummaries), ShouldEqual, 1)
+		})
+
+		Convey("With an existing rule", func() {
+			code, resp := getResponse(s.CreateRule, "/api/rules/create?dir="+dir+"&action=backup&match=*.txt", nil)
+			So(resp, ShouldBeBlank)
+			So(code, ShouldEqual, http.StatusNoContent)
+
+			Convey("You can preview updating it", func() {
+				code, _, p := preview("op=update&action=nobackup&match=*.txt")
+				So(code, ShouldEqual, http.StatusOK)
+				So(p.Before.Sets, ShouldResemble, map[string]*SizeCount{set: {Count: 1, Size: 3}})
+				So(p.After.Directory, ShouldResemble, map[int]*SizeCount{
+					unplanned:          {Count: 1, Size: 5},
+					int(db.BackupNone): {Count: 1, Size: 3},
+				})
+				So(p.After.Sets, ShouldBeEmpty)
+				So(s.directoryRules[dir].Rules["*.txt"].BackupType, ShouldEqual, db.BackupIBackup)
+			})
+
+			Convey("You can preview removing it", func() {
+				code, _, p := preview("op=remove&match=*.txt")
+				So(code, ShouldEqual, http.StatusOK)
+				So(p.Before.Directory, ShouldResemble, map[int]*SizeCount{
+					unplanned:             {Count: 1, Size: 5},
+					int(db.BackupIBackup): {Count: 1, Size: 3},
+				})
+				So(p.After.Directory, ShouldResemble, map[int]*SizeCount{unplanned: {Count: 2, Size: 8}})
+				So(p.After.Sets, ShouldBeEmpty)
+				So(s.directoryRules[dir].Rules, ShouldContainKey, "*.txt")
+			})
+		})
+	})
+}
@@ -32,7 +32,9 @@ import (
 	"net/http"
 	"slices"
 	"strings"
+	"time"
 
+	"github.com/wtsi-hgi/backup-plans/config"
 	"github.com/wtsi-hgi/backup-plans/db"
 	"github.com/wtsi-hgi/backup-plans/ibackup"
 	"github.com/wtsi-hgi/backup-plans/ruletree"
@@ -40,7 +42,9 @@ import (
 	"vimagination.zapto.org/tree"
 )
 
-type summary struct {
+// ReportSummary is the summary of the reporting roots produced by the Summary
+// endpoint.
+type ReportSummary struct {
 	Summaries             map[string]*ruletree.DirSummary
 	Rules                 map[uint64]*db.Rule
 	Directories           map[string][]uint64
@@ -51,6 +55,8 @@ type summary struct {
 const (
 	unplanned     = -1
 	setNamePrefix = "plan::"
+	nfsKeyPrefix  = "nfs:"
+	gitDir        = ".git/"
 )
 
 type SizeCount struct {
@@ -62,7 +68,7 @@ type dirSet struct {
 	dir, set string
 }
 
-func (s *Server) addTotals(backupType int, group ruletree.Stats, summary *summary) {
+func (s *Server) addTotals(backupType int, group ruletree.Stats, summary *ReportSummary) {
 	groupTotals, ok := summary.GroupBackupTypeTotals[group.Name]
 	if !ok {
 		groupTotals = make(map[int]*SizeCount)
@@ -86,9 +92,25 @@ func (s *Server) Summary(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) summary(w http.ResponseWriter, _ *http.Request) error {
+	s.rulesMu.RLock()
+	defer s.rulesMu.RUnlock()
+
+	dirSummary, err := s.collectSummary()
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-type", "application/json")
+
+	return json.NewEncoder(w).Encode(dirSummary)
+}
+
+// collectSummary builds the summary of the reporting roots. The caller must
+// hold a read lock on rulesMu.
+func (s *Server) collectSummary() (*ReportSummary, error) {
 	reportingRoots := s.rootDir.GlobPaths(s.config.GetReportingRoots()...)
 
-	dirSummary := summary{
+	dirSummary := &ReportSummary{
 		Summaries:             make(map[string]*ruletree.DirSummary, len(reportingRoots)),
 		Rules:                 make(map[uint64]*db.Rule),
 		Directories:           make(map[string][]uint64),
@@ -96,19 +118,13 @@ func (s *Server) summary(w http.ResponseWriter, _ *http.Request) error {
 		GroupBackupTypeTotals: make(map[string]map[int]*SizeCount),
 	}
 
-	s.rulesMu.RLock()
-	defer s.rulesMu.RUnlock()
-
-	err := s.collectBackupTotals(&dirSummary)
-	if err != nil {
-		return err
+	if err := s.collectBackupTotals(dirSummary); err != nil {
+		return nil, err
 	}
 
-	s.buildRootDirSummary(reportingRoots, &dirSummary)
-
-	w.Header().Set("Content-type", "application/json")
+	s.buildRootDirSummary(reportingRoots, dirSummary)
 
-	return json.NewEncoder(w).Encode(dirSummary)
+	return dirSummary, nil
 }
 
 func (s *Server) getClaimed(root string) string {
@@ -121,15 +137,15 @@ func (s *Server) getClaimed(root string) string {
 }
 
 func (s *Server) populateBackupStatus(dirClaims, repos, nfs map[string]string,
-	manualIbackup map[string][]dirSet, dirSummary *summary,
+	manualIbackup map[string][]dirSet, sourceMods map[string]uint64, dirSummary *ReportSummary,
 ) {
 	s.populateIbackupStatus(dirClaims, dirSummary)
 	s.populateManualIBackupStatus(manualIbackup, dirSummary)
-	s.populateGitBackupStatus(repos, dirSummary)
-	s.populateNFSStatus(nfs, dirSummary)
+	s.populateGitBackupStatus(repos, sourceMods, dirSummary)
+	s.populateNFSStatus(nfs, sourceMods, dirSummary)
 }
 
-func (s *Server) populateIbackupStatus(dirClaims map[string]string, dirSummary *summary) {
+func (s *Server) populateIbackupStatus(dirClaims map[string]string, dirSummary *ReportSummary) {
 	for dir, claimedBy := range dirClaims {
 		planName := setNamePrefix + dir
 		dirSummary.BackupStatus[dir] = s.getIBackupBackupStatus(planName, dir, claimedBy)
@@ -152,7 +168,7 @@ func (s *Server) getIBackupBackupStatus(planName, dir, claimedBy string) ibackup
 	}
 }
 
-func (s *Server) populateManualIBackupStatus(manualIbackup map[string][]dirSet, dirSummary *summary) {
+func (s *Server) populateManualIBackupStatus(manualIbackup map[string][]dirSet, dirSummary *ReportSummary) {
 	for claimedBy, dirSets := range manualIbackup {
 		for _, dirSet := range dirSets {
 			sba := s.getManualIBackupStatus(dirSet, claimedBy)
@@ -168,43 +184,62 @@ func (s *Server) getManualIBackupStatus(dirSet dirSet, claimedBy string) ibackup
 			"dir", dirSet.dir, "claimedBy", claimedBy, "set", dirSet.set, "err", err)
 	}
 
-	if sbaPtr != nil {
-		return *sbaPtr
-	}
-
-	return ibackup.SetBackupActivity{
+	sba := ibackup.SetBackupActivity{
 		Name:      dirSet.set,
 		Requester: claimedBy,
 	}
+
+	if sbaPtr != nil {
+		sba = *sbaPtr
+	}
+
+	sba.Coverage = s.coverage[coverageKey(dirSet.dir, dirSet.set)]
+
+	return sba
 }
 
-func (s *Server) populateGitBackupStatus(repos map[string]string, dirSummary *summary) {
+func (s *Server) populateGitBackupStatus(repos map[string]string, sourceMods map[string]uint64,
+	dirSummary *ReportSummary,
+) {
 	for repo, claimedBy := range repos {
-		dirSummary.BackupStatus[repo] = s.getGitBackupStatus(repo, claimedBy)
+		sba := s.getGitBackupStatus(repo, claimedBy)
+
+		markStale(&sba, sourceMods[repo])
+
+		dirSummary.BackupStatus[repo] = sba
 	}
 }
 
 func (s *Server) getGitBackupStatus(repo, claimedBy string) ibackup.SetBackupActivity {
-	t, err := s.gitCache.GetLatestCommitDate(repo)
-	if err != nil {
-		slog.Error("error querying repo status", "repo", repo, "err", err)
-	}
-
 	sba := ibackup.SetBackupActivity{
-		LastSuccess: t,
 		Name:        repo,
 		Requester:   claimedBy,
 		Failures:    -1,
+		WorkingCopy: s.workingCopies[repo],
+	}
+
+	status, err := s.gitCache.GetRepoStatus(repo)
+	if err != nil {
+		slog.Error("error querying repo status", "repo", repo, "err", err)
+
+		sba.Error = err.Error()
+	} else {
+		sba.LastSuccess = status.Latest
+		sba.Branches = status.Branches
 	}
 
 	return sba
 }
 
-func (s *Server) populateNFSStatus(backupPaths map[string]string, dirSummary *summary) {
+func (s *Server) populateNFSStatus(backupPaths map[string]string, sourceMods map[string]uint64,
+	dirSummary *ReportSummary,
+) {
 	for backupPath, claimedBy := range backupPaths {
 		sba := s.getNFSStatus(backupPath, claimedBy)
 
-		dirSummary.BackupStatus["nfs:"+backupPath] = sba
+		markStale(&sba, sourceMods[nfsKeyPrefix+backupPath])
+
+		dirSummary.BackupStatus[nfsKeyPrefix+backupPath] = sba
 	}
 }
 
@@ -215,11 +250,24 @@ func (s *Server) getNFSStatus(backupPath, claimedBy string) ibackup.SetBackupAct
 		Failures:  -1,
 	}
 
-	client := s.config.GetWRStatClient()
+	src := s.config.GetMTimeSource(backupPath)
+
+	var (
+		t   time.Time
+		err error
+	)
+
+	switch src.Source {
+	case config.MTimeSourceStat:
+		t, err = s.mtimeCache.Walk(backupPath, src.MaxDepth, src.BudgetDuration())
+	case config.MTimeSourceTree:
+		t, err = s.getTreeModTime(backupPath)
+	default:
+		t, err = s.config.GetWRStatClient().GetWRStatModTime(backupPath)
+	}
 
-	t, err := client.GetWRStatModTime(backupPath)
 	if err != nil {
-		slog.Error("error querying wrstat status", "path", backupPath, "err", err)
+		slog.Error("error querying nfs status", "path", backupPath, "source", src.Source, "err", err)
 	}
 
 	sba.LastSuccess = t
@@ -227,7 +275,74 @@ func (s *Server) getNFSStatus(backupPath, claimedBy string) ibackup.SetBackupAct
 	return sba
 }
 
-func (s *Server) collectBackupTotals(dirSummary *summary) error {
+// markStale records sourceMod, the newest modification time of the files
+// backed up to a manualgit or manualnfs target, against the status of that
+// target, flagging it as stale when the target is older. Nothing is recorded
+// when either time is unknown.
+func markStale(sba *ibackup.SetBackupActivity, sourceMod uint64) {
+	if sourceMod == 0 || sba.LastSuccess.IsZero() {
+		return
+	}
+
+	sba.SourceLastMod = time.Unix(int64(sourceMod), 0) //nolint:gosec
+	sba.Stale = sba.LastSuccess.Before(sba.SourceLastMod)
+}
+
+// sourceModKey returns the key of the status of the target of the given
+// manualgit or manualnfs rule, which is also used to collect the newest
+// modification time of its source files. Returns false for other rule types.
+func sourceModKey(rule *db.Rule) (string, bool) {
+	switch rule.BackupType { //nolint:exhaustive
+	case db.BackupManualGit:
+		return rule.Metadata, true
+	case db.BackupManualNFS:
+		return nfsKeyPrefix + rule.Metadata, true
+	}
+
+	return "", false
+}
+
+// addSourceMod records, against the target of the given manualgit or manualnfs
+// rule, the newest modification time of the files it matches beneath dirPath.
+//
+// For manualgit rules, the contents of .git directories are ignored, as git
+// updates them without the files in the working copy changing.
+func (s *Server) addSourceMod(sourceMods map[string]uint64, rule *db.Rule, dirPath string,
+	ruleSummary *ruletree.Rule,
+) {
+	key, ok := sourceModKey(rule)
+	if !ok {
+		return
+	}
+
+	lastMod := ruleSummary.LastMod()
+
+	if rule.BackupType == db.BackupManualGit {
+		gitLastMod, err := s.rootDir.LastMod(dirPath, rule.ID(), gitDir)
+		if err != nil {
+			slog.Error("error finding source modification time", "dir", dirPath, "repo", rule.Metadata, "err", err)
+		} else {
+			lastMod = gitLastMod
+		}
+	}
+
+	sourceMods[key] = max(sourceMods[key], lastMod)
+}
+
+func (s *Server) getTreeModTime(path string) (time.Time, error) {
+	ds, err := s.rootDir.Summary(strings.TrimSuffix(path, "/") + "/")
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if ds.LastMod == 0 {
+		return time.Time{}, nil
+	}
+
+	return time.Unix(int64(ds.LastMod), 0), nil //nolint:gosec
+}
+
+func (s *Server) collectBackupTotals(dirSummary *ReportSummary) error {
 	ds, err := s.rootDir.Summary("/")
 	if err != nil {
 		return err
@@ -251,11 +366,12 @@ func (s *Server) getBackupTypeForTotals(id uint64) int {
 	return int(s.rules[id].BackupType)
 }
 
-func (s *Server) buildRootDirSummary(reportingRoots []string, dirSummary *summary) {
+func (s *Server) buildRootDirSummary(reportingRoots []string, dirSummary *ReportSummary) {
 	dirClaims := make(map[string]string)
 	repos := make(map[string]string)
 	nfs := make(map[string]string)
 	manualIbackup := make(map[string][]dirSet)
+	sourceMods := make(map[string]uint64)
 
 	for _, root := range reportingRoots {
 		ds, err := s.getRootSummary(root)
@@ -276,10 +392,10 @@ func (s *Server) buildRootDirSummary(reportingRoots []string, dirSummary *summar
 		nds.ClaimedBy = s.getClaimed(root)
 		dirSummary.Summaries[root] = nds
 
-		s.collectRuleMetadata(ds, dirSummary, dirClaims, repos, nfs, manualIbackup)
+		s.collectRuleMetadata(ds, dirSummary, dirClaims, repos, nfs, manualIbackup, sourceMods)
 	}
 
-	s.populateBackupStatus(dirClaims, repos, nfs, manualIbackup, dirSummary)
+	s.populateBackupStatus(dirClaims, repos, nfs, manualIbackup, sourceMods, dirSummary)
 }
 
 func (s *Server) getRootSummary(root string) (*ruletree.DirSummary, error) {
@@ -324,8 +440,8 @@ func (s *Server) collectChildDirSummaries(ds *ruletree.DirSummary, root string)
 	}
 }
 
-func (s *Server) collectRuleMetadata(ds *ruletree.DirSummary, dirSummary *summary, //nolint:gocyclo
-	dirClaims, repos, nfs map[string]string, manualIbackup map[string][]dirSet,
+func (s *Server) collectRuleMetadata(ds *ruletree.DirSummary, dirSummary *ReportSummary, //nolint:gocyclo
+	dirClaims, repos, nfs map[string]string, manualIbackup map[string][]dirSet, sourceMods map[string]uint64,
 ) {
 	for _, ruleSummary := range ds.RuleSummaries {
 		rule := s.rules[ruleSummary.ID]
@@ -349,6 +465,8 @@ func (s *Server) collectRuleMetadata(ds *ruletree.DirSummary, dirSummary *summar
 			nfs[rule.Metadata] = dir.ClaimedBy
 		}
 
+		s.addSourceMod(sourceMods, rule, dirPath, &ruleSummary)
+
 		if _, ok := dirSummary.Directories[dirPath]; ok {
 			continue
 		}
@@ -357,7 +475,7 @@ func (s *Server) collectRuleMetadata(ds *ruletree.DirSummary, dirSummary *summar
 	}
 }
 
-func (s *Server) collectRules(dirSummary *summary, dir *ruletree.DirRules) {
+func (s *Server) collectRules(dirSummary *ReportSummary, dir *ruletree.DirRules) {
 	ruleIDs := make([]uint64, 0, len(dir.Rules))
 
 	for _, r := range dir.Rules {
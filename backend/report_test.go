@@ -84,7 +84,7 @@ func TestReport(t *testing.T) {
 			code, str := getResponse(srv.Summary, "/api/report/summary", nil)
 			So(code, ShouldEqual, http.StatusOK)
 
-			var gotSummary summary
+			var gotSummary ReportSummary
 
 			err = json.NewDecoder(strings.NewReader(str)).Decode(&gotSummary)
 			So(err, ShouldBeNil)
@@ -229,7 +229,7 @@ func TestReport(t *testing.T) {
 					"/lustre/scratch123/humgen/a/b/newdir/":              abNewDir,
 					"/lustre/scratch123/humgen/a/b/newdir/testextradir/": testextradir,
 				}
-				expectedSummary := summary{
+				expectedSummary := ReportSummary{
 					Summaries: map[string]*ruletree.DirSummary{
 						"/lustre/scratch123/humgen/a/": {
 							User:    "root",
@@ -400,3 +400,49 @@ func getSingleClientFromMultiClient(t *testing.T, client *ibackup.MultiClient) *
 
 	return singleClient.Load().(*server.Client) //nolint:errcheck,forcetypeassert
 }
+
+func TestMarkStale(t *testing.T) {
+	Convey("A manual target is only marked as stale when older than its source files", t, func() {
+		target := time.Unix(2000, 0)
+
+		for _, test := range [...]struct {
+			lastSuccess   time.Time
+			sourceMod     uint64
+			sourceLastMod time.Time
+			stale         bool
+		}{
+			{target, 1000, time.Unix(1000, 0), false},
+			{target, 2000, time.Unix(2000, 0), false},
+			{target, 3000, time.Unix(3000, 0), true},
+			{target, 0, time.Time{}, false},
+			{time.Time{}, 3000, time.Time{}, false},
+		} {
+			sba := ibackup.SetBackupActivity{LastSuccess: test.lastSuccess}
+
+			markStale(&sba, test.sourceMod)
+
+			So(sba.SourceLastMod, ShouldEqual, test.sourceLastMod)
+			So(sba.Stale, ShouldEqual, test.stale)
+		}
+	})
+}
+
+func TestSourceModKey(t *testing.T) {
+	Convey("Manual git and NFS targets with the same name have different keys", t, func() {
+		for _, test := range [...]struct {
+			rule *db.Rule
+			key  string
+			ok   bool
+		}{
+			{&db.Rule{BackupType: db.BackupManualGit, Metadata: "/target"}, "/target", true},
+			{&db.Rule{BackupType: db.BackupManualNFS, Metadata: "/target"}, "nfs:/target", true},
+			{&db.Rule{BackupType: db.BackupManualIBackup, Metadata: "/target"}, "", false},
+			{&db.Rule{BackupType: db.BackupIBackup}, "", false},
+		} {
+			key, ok := sourceModKey(test.rule)
+
+			So(key, ShouldEqual, test.key)
+			So(ok, ShouldEqual, test.ok)
+		}
+	})
+}
@@ -26,6 +26,7 @@
 package backend
 
 import (
+	"cmp"
 	"encoding/json"
 	"errors"
 	"maps"
@@ -35,6 +36,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/wtsi-hgi/backup-plans/config"
 	"github.com/wtsi-hgi/backup-plans/db"
 	"github.com/wtsi-hgi/backup-plans/ruletree"
 	"github.com/wtsi-hgi/backup-plans/users"
@@ -95,6 +97,18 @@ var (
 		Code: http.StatusBadRequest,
 		Err:  errors.New("directory already frozen"), //nolint:err113
 	}
+	ErrOutsidePolicy = Error{
+		Code: http.StatusBadRequest,
+		Err:  errors.New("review or removal date not permitted by policy"), //nolint:err113
+	}
+	ErrBackupTypeNotAllowed = Error{
+		Code: http.StatusBadRequest,
+		Err:  errors.New("backup type not permitted by policy"), //nolint:err113
+	}
+	ErrOverrideNotAllowed = Error{
+		Code: http.StatusBadRequest,
+		Err:  errors.New("override not permitted by policy"), //nolint:err113
+	}
 )
 
 const (
@@ -157,6 +171,25 @@ func (s *Server) loadRules() ([]ruletree.DirRule, error) { //nolint:funlen
 	return dirRules, nil
 }
 
+// reloadRules rebuilds the in-memory rules, and those in the rule tree, from the
+// database, bringing them back in line with it after a change to the database
+// could not be applied to them.
+func (s *Server) reloadRules() error {
+	s.rulesMu.Lock()
+	rules, err := s.loadRules()
+	s.rulesMu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if err := s.rootDir.ReplaceRules(rules); err != nil {
+		return err
+	}
+
+	return s.updateDirSummaries("/")
+}
+
 // ClaimDir is an HTTP endpoint that allows a user to claim a directory in order
 // to add rules to it. The user must be the owner of the directory, in the group
 // of the directory, own a file within the directory tree, or be in a group that
@@ -195,7 +228,7 @@ func (s *Server) claimDir(w http.ResponseWriter, r *http.Request) error { //noli
 	err = s.claimDirectory(
 		dir,
 		user,
-		defaultDirDetails(),
+		defaultDirDetails(s.config.GetPolicy(dir)),
 	)
 	if err != nil {
 		return err
@@ -206,15 +239,39 @@ func (s *Server) claimDir(w http.ResponseWriter, r *http.Request) error { //noli
 	return json.NewEncoder(w).Encode(user)
 }
 
-func defaultDirDetails() dirDetails {
-	reviewDate := time.Now().Add(twoyears).Unix()
+// defaultDirDetails returns the details for a newly claimed directory, moving
+// the default review and removal dates within the bounds of the given policy.
+func defaultDirDetails(policy config.Policy) dirDetails {
+	now := time.Now()
 
-	return dirDetails{Frequency: defaultFrequency,
-		ReviewDate: reviewDate,
-		RemoveDate: reviewDate + month,
+	earliest, latest := policy.ReviewBounds(now)
+	review := clamp(now.Add(twoyears), earliest, latest)
+
+	earliest, latest = policy.RemovalBounds(review)
+	remove := clamp(review.Add(month*time.Second), earliest, latest)
+
+	return dirDetails{Frequency: cmp.Or(policy.Frequency, defaultFrequency),
+		ReviewDate: review.Unix(),
+		RemoveDate: remove.Unix(),
 	}
 }
 
+func clamp(t, earliest, latest time.Time) time.Time {
+	if t.Before(earliest) {
+		return earliest
+	}
+
+	if !latest.IsZero() && t.After(latest) {
+		return latest
+	}
+
+	return t
+}
+
+func within(t, earliest, latest time.Time) bool {
+	return !t.Before(earliest) && (latest.IsZero() || !t.After(latest))
+}
+
 func (s *Server) claimDirectory(fileDir, user string, dirdetails dirDetails) error {
 	directory := &db.Directory{
 		Path:       fileDir,
@@ -360,6 +417,12 @@ func (s *Server) setDirDetails(_ http.ResponseWriter, r *http.Request) error { /
 		return err
 	}
 
+	policy := s.config.GetPolicy(dir)
+
+	if err = checkDirPolicy(&policy, dDetails); err != nil {
+		return err
+	}
+
 	user := s.getUser(r)
 
 	s.rulesMu.Lock()
@@ -411,6 +474,22 @@ func validateDirDetails(d dirDetails) error {
 	return nil
 }
 
+func checkDirPolicy(policy *config.Policy, d dirDetails) error {
+	review := time.Unix(d.ReviewDate, 0)
+
+	earliest, latest := policy.ReviewBounds(time.Now())
+	if !within(review, earliest, latest) {
+		return ErrOutsidePolicy
+	}
+
+	earliest, latest = policy.RemovalBounds(review)
+	if !within(time.Unix(d.RemoveDate, 0), earliest, latest) {
+		return ErrOutsidePolicy
+	}
+
+	return nil
+}
+
 type dirDetails struct {
 	Frequency  uint
 	Frozen     bool
@@ -481,7 +560,7 @@ func (s *Server) CreateRule(w http.ResponseWriter, r *http.Request) {
 	handle(w, r, s.createRule)
 }
 
-func (s *Server) createRule(w http.ResponseWriter, r *http.Request) error { //nolint:funlen,gocyclo
+func (s *Server) createRule(w http.ResponseWriter, r *http.Request) error {
 	dir, err := getDir(r)
 	if err != nil {
 		return err
@@ -500,6 +579,18 @@ func (s *Server) createRule(w http.ResponseWriter, r *http.Request) error { //no
 		return err
 	}
 
+	if err := s.addDirRules(directory, rules); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// addDirRules adds the given rules to the given directory, regenerating the
+// rule summaries. The buildMu lock must be held.
+func (s *Server) addDirRules(directory *Directory, rules []*db.Rule) error {
 	if err := s.rulesDB.CreateDirectoryRule(directory.Directory, rules...); err != nil {
 		return err
 	}
@@ -517,13 +608,7 @@ func (s *Server) createRule(w http.ResponseWriter, r *http.Request) error { //no
 		return err
 	}
 
-	if err := s.updateDirSummaries(directory.Path); err != nil {
-		return err
-	}
-
-	w.WriteHeader(http.StatusNoContent)
-
-	return nil
+	return s.updateDirSummaries(directory.Path)
 }
 
 func (s *Server) checkAddRules(r *http.Request, dir string, rules []*db.Rule) (*Directory, error) {
@@ -545,45 +630,67 @@ func (s *Server) checkAddRules(r *http.Request, dir string, rules []*db.Rule) (*
 		}
 	}
 
+	policy := s.config.GetPolicy(dir)
+
+	if err := checkRulePolicy(&policy, rules); err != nil {
+		return nil, err
+	}
+
 	return directory, nil
 }
 
-func getRuleDetails(r *http.Request) ([]*db.Rule, error) { //nolint:cyclop,gocyclo,funlen
+// checkRulePolicy checks that the given rules only use the backup types, and
+// overrides, permitted by the policy.
+func checkRulePolicy(policy *config.Policy, rules []*db.Rule) error {
+	if err := checkUpdatePolicy(policy, rules); err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		if rule.Override && policy.NoOverrides {
+			return ErrOverrideNotAllowed
+		}
+	}
+
+	return nil
+}
+
+// checkUpdatePolicy checks that the given rule updates only use the backup
+// types permitted by the policy. Updates do not change whether a rule is an
+// override, so overrides are not checked.
+func checkUpdatePolicy(policy *config.Policy, rules []*db.Rule) error {
+	for _, rule := range rules {
+		if !policy.AllowsBackupType(rule.BackupType) {
+			return ErrBackupTypeNotAllowed
+		}
+	}
+
+	return nil
+}
+
+func getRuleDetails(r *http.Request) ([]*db.Rule, error) {
+	return newRules(r.FormValue("action"), r.FormValue("metadata"), r.FormValue("override") == "true", r.Form["match"])
+}
+
+// newRules returns a rule for each of the given matches, or a single rule
+// matching everything if there are none.
+func newRules(action, metadata string, override bool, matches []string) ([]*db.Rule, error) {
 	var rule db.Rule
 
-	var requireMetadata bool
-
-	switch r.FormValue("action") {
-	case "nobackup":
-		rule.BackupType = db.BackupNone
-	case "backup":
-		rule.BackupType = db.BackupIBackup
-	case "manualibackup":
-		rule.BackupType = db.BackupManualIBackup
-		requireMetadata = true
-	case "manualgit":
-		rule.BackupType = db.BackupManualGit
-		requireMetadata = true
-	case "manualprefect":
-		rule.BackupType = db.BackupManualPrefect
-		requireMetadata = true
-	case "manualnfs":
-		rule.BackupType = db.BackupManualNFS
-		requireMetadata = true
-	case "manualunchecked":
-		rule.BackupType = db.BackupManualUnchecked
-		requireMetadata = true
-	default:
+	backupType, ok := db.ParseBackupType(action)
+	if !ok {
 		return nil, ErrInvalidAction
 	}
 
-	if requireMetadata {
-		rule.Metadata = r.FormValue("metadata")
+	rule.BackupType = backupType
+
+	if db.IsManual(backupType) {
+		rule.Metadata = metadata
 	}
 
-	rule.Override = r.FormValue("override") == "true"
+	rule.Override = override
 
-	rules, err := createMatchRules(rule, r.Form["match"])
+	rules, err := createMatchRules(rule, matches)
 	if err != nil {
 		return nil, err
 	} else if len(rules) == 0 {
@@ -635,7 +742,7 @@ func (s *Server) UpdateRule(w http.ResponseWriter, r *http.Request) {
 	handle(w, r, s.updateRule)
 }
 
-func (s *Server) updateRule(w http.ResponseWriter, r *http.Request) error { //nolint:funlen,gocyclo
+func (s *Server) updateRule(w http.ResponseWriter, r *http.Request) error {
 	dir, err := getDir(r)
 	if err != nil {
 		return err
@@ -649,33 +756,58 @@ func (s *Server) updateRule(w http.ResponseWriter, r *http.Request) error { //no
 	s.rulesMu.Lock()
 	defer s.rulesMu.Unlock()
 
+	existing, err := s.checkUpdateRules(r, dir, rules)
+	if err != nil {
+		return err
+	}
+
+	for n, rule := range rules {
+		existing[n].BackupType = rule.BackupType
+		existing[n].Metadata = rule.Metadata
+		existing[n].Template = 0
+	}
+
+	if err := s.rulesDB.UpdateRule(existing...); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// checkUpdateRules checks that the user can update the given rules in the given
+// directory, returning the existing rules with the same matches.
+//
+// Must be called with rulesMu held.
+func (s *Server) checkUpdateRules(r *http.Request, dir string, rules []*db.Rule) ([]*db.Rule, error) {
 	directory, ok := s.directoryRules[dir]
 	if !ok {
-		return ErrInvalidDir
+		return nil, ErrInvalidDir
 	}
 
 	if directory.ClaimedBy != s.getUser(r) {
-		return ErrInvalidUser
+		return nil, ErrInvalidUser
+	}
+
+	policy := s.config.GetPolicy(dir)
+
+	if err := checkUpdatePolicy(&policy, rules); err != nil {
+		return nil, err
 	}
 
+	existing := make([]*db.Rule, len(rules))
+
 	for n, rule := range rules {
 		existingRule, ok := directory.Rules[rule.Match]
 		if !ok {
-			return ErrNoRule
+			return nil, ErrNoRule
 		}
 
-		existingRule.BackupType = rule.BackupType
-		existingRule.Metadata = rule.Metadata
-		rules[n] = existingRule
+		existing[n] = existingRule
 	}
 
-	if err := s.rulesDB.UpdateRule(rules...); err != nil {
-		return err
-	}
-
-	w.WriteHeader(http.StatusNoContent)
-
-	return nil
+	return existing, nil
 }
 
 // RemoveRule allows the claimant of a directory to remove a rule from that
@@ -687,7 +819,7 @@ func (s *Server) RemoveRule(w http.ResponseWriter, r *http.Request) {
 	handle(w, r, s.removeRule)
 }
 
-func (s *Server) removeRule(w http.ResponseWriter, r *http.Request) error { //nolint:funlen
+func (s *Server) removeRule(w http.ResponseWriter, r *http.Request) error {
 	dir, err := getDir(r)
 	if err != nil {
 		return err
@@ -701,20 +833,7 @@ func (s *Server) removeRule(w http.ResponseWriter, r *http.Request) error { //no
 		return err
 	}
 
-	if err := s.rulesDB.RemoveRule(rule); err != nil {
-		return err
-	}
-
-	if err := s.rootDir.RemoveRule(directory.Directory, rule); err != nil {
-		return err
-	}
-
-	s.rulesMu.Lock()
-	delete(directory.Rules, rule.Match)
-	delete(s.rules, uint64(rule.ID())) //nolint:gosec
-	s.rulesMu.Unlock()
-
-	if err := s.updateDirSummaries(directory.Path); err != nil {
+	if err := s.removeDirRule(directory, rule); err != nil {
 		return err
 	}
 
@@ -746,6 +865,25 @@ func (s *Server) getRuleToRemove(r *http.Request, dir string) (*Directory, *db.R
 	return directory, rule, nil
 }
 
+// removeDirRule removes the given rule from the given directory, regenerating
+// the rule summaries. The buildMu lock must be held.
+func (s *Server) removeDirRule(directory *Directory, rule *db.Rule) error {
+	if err := s.rulesDB.RemoveRule(rule); err != nil {
+		return err
+	}
+
+	if err := s.rootDir.RemoveRule(directory.Directory, rule); err != nil {
+		return err
+	}
+
+	s.rulesMu.Lock()
+	delete(directory.Rules, rule.Match)
+	delete(s.rules, uint64(rule.ID())) //nolint:gosec
+	s.rulesMu.Unlock()
+
+	return s.updateDirSummaries(directory.Path)
+}
+
 func getDir(r *http.Request) (string, error) {
 	dir := r.FormValue("dir")
 
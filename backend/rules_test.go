@@ -32,12 +32,14 @@ import (
 	"os/user"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
 	"testing/synctest"
 	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
 	"github.com/wtsi-hgi/backup-plans/config"
+	"github.com/wtsi-hgi/backup-plans/db"
 	lconfig "github.com/wtsi-hgi/backup-plans/internal/config"
 	"github.com/wtsi-hgi/backup-plans/internal/directories"
 	"github.com/wtsi-hgi/backup-plans/internal/plandb"
@@ -395,7 +397,7 @@ ibackupcacheduration: 3600`,
 			s, err := New(testDB, u.getUser, c)
 			So(err, ShouldBeNil)
 
-			Reset(s.stop)
+			Reset(s.Stop)
 			Reset(s.config.GetCachedIBackupClient().Stop)
 			Reset(s.config.GetIBackupClient().Stop)
 
@@ -416,7 +418,7 @@ ibackupcacheduration: 3600`,
 				So(err, ShouldBeNil)
 				So(ns.directoryRules["/lustre/scratch123/humgen/a/b/"].Melt, ShouldBeGreaterThanOrEqualTo, now)
 
-				ns.stop()
+				ns.Stop()
 
 				got := &set.Set{
 					Requester:   "userA",
@@ -438,7 +440,109 @@ ibackupcacheduration: 3600`,
 				So(err, ShouldBeNil)
 				So(ns.directoryRules["/lustre/scratch123/humgen/a/b/"].Melt, ShouldEqual, 0)
 
-				ns.stop()
+				ns.Stop()
+			})
+		})
+	})
+}
+
+func TestPolicies(t *testing.T) {
+	Convey("Given a server with a configured policy", t, func() {
+		u := userHandler(root)
+
+		cfg := filepath.Join(t.TempDir(), "config.yaml")
+
+		So(os.WriteFile(cfg, []byte(`policies:
+  /some/path/:
+    frequency: 3
+    maxreview: 365
+    maxremoval: 60
+    forbidden:
+      - backup
+    nooverrides: true
+`), 0600), ShouldBeNil)
+
+		c, err := config.Parse(cfg)
+		So(err, ShouldBeNil)
+
+		Reset(c.Stop)
+
+		s, err := New(testdb.CreateTestDatabase(t), u.getUser, c)
+		So(err, ShouldBeNil)
+
+		Reset(s.Stop)
+
+		So(s.AddTree(createTestTree(t)), ShouldBeNil)
+
+		const dir = "/some/path/MyDir/"
+
+		Convey("Claimed directories get defaults from the policy", func() {
+			code, _ := getResponse(s.ClaimDir, "/api/dir/claim?dir="+dir, nil)
+			So(code, ShouldEqual, http.StatusOK)
+
+			now := time.Now()
+			claimed := s.directoryRules[dir]
+
+			So(claimed.Frequency, ShouldEqual, 3)
+			So(claimed.ReviewDate, ShouldBeLessThanOrEqualTo, now.AddDate(0, 0, 365).Unix())
+			So(claimed.RemoveDate-claimed.ReviewDate, ShouldBeLessThanOrEqualTo, 60*24*3600)
+
+			code, resp := getResponse(s.Tree, "/api/tree?dir="+dir, nil)
+			So(code, ShouldEqual, http.StatusOK)
+			So(resp, ShouldContainSubstring, `"Policy":{"Prefix":"/some/path/","Frequency":3,`)
+
+			Convey("Directory details must be within the policy bounds", func() {
+				review := strconv.FormatInt(now.AddDate(0, 0, 300).Unix(), 10)
+				remove := strconv.FormatInt(now.AddDate(0, 0, 330).Unix(), 10)
+				tooLate := strconv.FormatInt(now.AddDate(2, 0, 0).Unix(), 10)
+
+				code, resp := getResponse(s.SetDirDetails, "/api/dir/setdetails", url.Values{
+					"dir": {dir}, "frequency": {"1"}, "frozen": {"false"}, "review": {tooLate}, "remove": {tooLate},
+				})
+				checkErrorResponse(t, code, resp, ErrOutsidePolicy)
+
+				code, resp = getResponse(s.SetDirDetails, "/api/dir/setdetails", url.Values{
+					"dir": {dir}, "frequency": {"1"}, "frozen": {"false"}, "review": {review}, "remove": {tooLate},
+				})
+				checkErrorResponse(t, code, resp, ErrOutsidePolicy)
+
+				code, resp = getResponse(s.SetDirDetails, "/api/dir/setdetails", url.Values{
+					"dir": {dir}, "frequency": {"1"}, "frozen": {"false"}, "review": {review}, "remove": {remove},
+				})
+				So(resp, ShouldBeBlank)
+				So(code, ShouldEqual, http.StatusNoContent)
+			})
+
+			Convey("Rules must use permitted backup types and overrides", func() {
+				code, resp := getResponse(s.CreateRule, "/api/rules/create?dir="+dir+"&action=backup&match=*.txt", nil)
+				checkErrorResponse(t, code, resp, ErrBackupTypeNotAllowed)
+
+				code, resp = getResponse(s.CreateRule,
+					"/api/rules/create?dir="+dir+"&action=nobackup&match=*.txt&override=true", nil)
+				checkErrorResponse(t, code, resp, ErrOverrideNotAllowed)
+
+				code, resp = getResponse(s.CreateRule, "/api/rules/create?dir="+dir+"&action=nobackup&match=*.txt", nil)
+				So(resp, ShouldBeBlank)
+				So(code, ShouldEqual, http.StatusNoContent)
+
+				code, resp = getResponse(s.UpdateRule, "/api/rules/update?dir="+dir+"&action=backup&match=*.txt", nil)
+				checkErrorResponse(t, code, resp, ErrBackupTypeNotAllowed)
+
+				code, resp = getResponse(s.UpdateRule,
+					"/api/rules/update?dir="+dir+"&action=nobackup&match=*.txt&override=true", nil)
+				So(resp, ShouldBeBlank)
+				So(code, ShouldEqual, http.StatusNoContent)
+				So(s.directoryRules[dir].Rules["*.txt"].Override, ShouldBeFalse)
+
+				code, resp = getResponse(s.BulkRules, "/api/rules/bulk", strings.NewReader(
+					`{"Glob": "/some/path/*/", "Update": [{"Action": "backup", "Match": ["*.txt"]}]}`))
+				checkErrorResponse(t, code, resp, ErrBackupTypeNotAllowed)
+
+				code, _ = getResponse(s.BulkRules, "/api/rules/bulk", strings.NewReader(
+					`{"Glob": "/some/path/*/", "Update": [{"Action": "nobackup", "Match": ["*.txt"], "Override": true}]}`))
+				So(code, ShouldEqual, http.StatusOK)
+				So(s.directoryRules[dir].Rules["*.txt"].BackupType, ShouldEqual, db.BackupNone)
+				So(s.directoryRules[dir].Rules["*.txt"].Override, ShouldBeFalse)
 			})
 		})
 	})
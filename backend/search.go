@@ -0,0 +1,406 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package backend
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/wtsi-hgi/backup-plans/db"
+	"github.com/wtsi-hgi/backup-plans/ruletree"
+	"github.com/wtsi-hgi/backup-plans/users"
+)
+
+var (
+	ErrNoQuery = Error{
+		Code: http.StatusBadRequest,
+		Err:  errors.New("no search query"), //nolint:err113
+	}
+	ErrInvalidFilter = Error{
+		Code: http.StatusBadRequest,
+		Err:  errors.New("invalid search filter"), //nolint:err113
+	}
+)
+
+const maxSearchMatches = 10000
+
+var maxSearchVisits = 1 << 20 //nolint:gochecknoglobals
+
+// SearchResults is a page of the directories matching a search, along with the
+// total number of matching directories, and whether the search stopped early.
+type SearchResults struct {
+	Total     int
+	Truncated bool
+	Results   []SearchResult
+}
+
+// SearchResult describes a directory that matched a search.
+type SearchResult struct {
+	Path      string
+	User      string
+	Group     string
+	UID, GID  uint32
+	ClaimedBy string
+	Files     uint64
+	Size      uint64
+}
+
+type searchFilter struct {
+	claimed  *bool
+	uid, gid *uint32
+	minSize  uint64
+	status   *int
+}
+
+// Search is an HTTP endpoint that finds directories in all of the loaded trees.
+//
+// The 'q' GET param is either a glob, containing '*', '?' or '[', or a
+// substring. When it contains a '/', it is matched against the whole directory
+// path, otherwise against the directory name, with substrings matched case
+// insensitively. Absolute globs are matched a path part at a time, as with
+// the reporting roots.
+//
+// The results can be filtered with the following optional params:
+//
+//	claimed: true or false, for claimed or unclaimed directories;
+//	uid, gid: the owner of the directory;
+//	minsize: the minimum total size of the files in the directory;
+//	status: a backup type (eg. backup, nobackup), or unplanned, of which the
+//		directory must contain files.
+//
+// Only directories the user is authorised to see are returned.
+//
+// Results are ranked with exact name matches first, then names with the query
+// as a prefix, then others, with shallower directories first. The shallowest
+// 10000 matches are considered, and at most 2^20 directories are searched;
+// Truncated is set if either limit was reached. Results are paginated as with
+// the Files endpoint.
+//
+// The response is a JSON encoded SearchResults.
+func (s *Server) Search(w http.ResponseWriter, r *http.Request) {
+	handle(w, r, s.search)
+}
+
+func (s *Server) search(w http.ResponseWriter, r *http.Request) error { //nolint:funlen
+	q := r.FormValue("q")
+	if q == "" {
+		return ErrNoQuery
+	}
+
+	offset, limit, err := getPage(r)
+	if err != nil {
+		return err
+	}
+
+	filter, err := getSearchFilter(r)
+	if err != nil {
+		return err
+	}
+
+	uid, groups := users.GetIDs(s.getUser(r))
+	if len(groups) == 0 {
+		return ErrNotAuthorised
+	}
+
+	paths, truncated := s.searchPaths(q)
+	summaries := s.searchSummaries(paths, uid, groups)
+	results := SearchResults{Truncated: truncated, Results: []SearchResult{}}
+
+	s.rulesMu.RLock()
+
+	for n, path := range paths {
+		if summaries[n] == nil {
+			continue
+		}
+
+		if result, ok := s.searchResult(path, summaries[n], filter); ok {
+			results.Results = append(results.Results, result)
+		}
+	}
+
+	s.rulesMu.RUnlock()
+
+	results.Total = len(results.Results)
+	results.Results = results.Results[min(offset, len(results.Results)):]
+	results.Results = results.Results[:min(limit, len(results.Results))]
+
+	w.Header().Set("Content-Type", "application/json")
+
+	return json.NewEncoder(w).Encode(results)
+}
+
+func getSearchFilter(r *http.Request) (*searchFilter, error) {
+	var (
+		filter searchFilter
+		err    error
+	)
+
+	if str := r.FormValue("claimed"); str != "" {
+		claimed, err := strconv.ParseBool(str)
+		if err != nil {
+			return nil, ErrInvalidFilter
+		}
+
+		filter.claimed = &claimed
+	}
+
+	if filter.uid, err = getSearchID(r, "uid"); err != nil {
+		return nil, err
+	}
+
+	if filter.gid, err = getSearchID(r, "gid"); err != nil {
+		return nil, err
+	}
+
+	if str := r.FormValue("minsize"); str != "" {
+		if filter.minSize, err = strconv.ParseUint(str, 10, 64); err != nil {
+			return nil, ErrInvalidFilter
+		}
+	}
+
+	if str := r.FormValue("status"); str != "" {
+		status := unplanned
+
+		if str != unplannedName {
+			bt, ok := db.ParseBackupType(str)
+			if !ok {
+				return nil, ErrInvalidFilter
+			}
+
+			status = int(bt)
+		}
+
+		filter.status = &status
+	}
+
+	return &filter, nil
+}
+
+func getSearchID(r *http.Request, param string) (*uint32, error) {
+	str := r.FormValue(param)
+	if str == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	id, err := strconv.ParseUint(str, 10, 32)
+	if err != nil {
+		return nil, ErrInvalidFilter
+	}
+
+	id32 := uint32(id)
+
+	return &id32, nil
+}
+
+// searchPaths returns the ranked paths of the directories matching the given
+// query, and whether there were more matches than were considered.
+func (s *Server) searchPaths(q string) ([]string, bool) {
+	isGlob := strings.ContainsAny(q, "*?[")
+
+	if isGlob && strings.HasPrefix(q, "/") {
+		return s.searchGlob(q)
+	}
+
+	var (
+		paths     []string
+		truncated bool
+		visited   int
+	)
+
+	match := searchMatcher(q, isGlob)
+
+	s.rootDir.Directories(func(path string) bool {
+		if visited++; visited > maxSearchVisits {
+			truncated = true
+
+			return false
+		}
+
+		if !match(path) {
+			return true
+		}
+
+		if len(paths) == maxSearchMatches {
+			truncated = true
+
+			return false
+		}
+
+		paths = append(paths, path)
+
+		return true
+	})
+
+	rankSearch(q, paths)
+
+	return paths, truncated
+}
+
+// searchSummaries returns the summaries of the given paths, with nil for those
+// the user is not authorised to see.
+func (s *Server) searchSummaries(paths []string, uid uint32, groups []uint32) []*ruletree.DirSummary {
+	summaries := make([]*ruletree.DirSummary, len(paths))
+	adminGroup := s.config.GetAdminGroup()
+
+	for n, path := range paths {
+		summary, err := s.rootDir.Summary(path)
+		if err == nil && isAuthorised(summary, uid, groups, adminGroup) {
+			summaries[n] = summary
+		}
+	}
+
+	return summaries
+}
+
+func (s *Server) searchGlob(q string) ([]string, bool) {
+	if !strings.HasSuffix(q, "/") {
+		q += "/"
+	}
+
+	paths := s.rootDir.GlobPath(q)
+
+	rankSearch(q, paths)
+
+	if len(paths) > maxSearchMatches {
+		return paths[:maxSearchMatches], true
+	}
+
+	return paths, false
+}
+
+// searchMatcher returns a function that reports whether a directory path
+// matches the given query.
+func searchMatcher(q string, isGlob bool) func(string) bool {
+	full := strings.Contains(q, "/")
+	lower := strings.ToLower(q)
+
+	return func(path string) bool {
+		target := path
+
+		if !full {
+			target = searchName(path)
+		}
+
+		if isGlob {
+			m, _ := filepath.Match(strings.TrimSuffix(q, "/"), strings.TrimSuffix(target, "/")) //nolint:errcheck
+
+			return m
+		}
+
+		if full {
+			return strings.Contains(target, q)
+		}
+
+		return strings.Contains(strings.ToLower(target), lower)
+	}
+}
+
+// searchName returns the name of the given directory, without the trailing
+// slash.
+func searchName(path string) string {
+	path = strings.TrimSuffix(path, "/")
+
+	return path[strings.LastIndexByte(path, '/')+1:]
+}
+
+func rankSearch(q string, paths []string) {
+	lower := strings.ToLower(strings.Trim(q, "/"))
+
+	rank := func(path string) int {
+		name := strings.ToLower(searchName(path))
+
+		switch {
+		case name == lower:
+			return 0
+		case strings.HasPrefix(name, lower):
+			return 1
+		default:
+			return 2 //nolint:mnd
+		}
+	}
+
+	slices.SortStableFunc(paths, func(a, b string) int {
+		return cmp.Or(
+			cmp.Compare(rank(a), rank(b)),
+			cmp.Compare(strings.Count(a, "/"), strings.Count(b, "/")),
+			strings.Compare(a, b),
+		)
+	})
+}
+
+// searchResult returns the result for a directory, and whether it passes the
+// given filter.
+//
+// Must be called with rulesMu held.
+func (s *Server) searchResult(path string, summary *ruletree.DirSummary,
+	filter *searchFilter) (SearchResult, bool) {
+	uid, gid := summary.IDs()
+	result := SearchResult{
+		Path:  path,
+		User:  users.Username(uid),
+		Group: users.Group(gid),
+		UID:   uid,
+		GID:   gid,
+	}
+
+	if dr, ok := s.directoryRules[path]; ok {
+		result.ClaimedBy = dr.ClaimedBy
+	}
+
+	hasStatus := filter.status == nil
+
+	for _, rs := range summary.RuleSummaries {
+		sc := ruleSizeCount(rs)
+
+		result.Files += sc.Count
+		result.Size += sc.Size
+
+		if !hasStatus && sc.Count > 0 && previewBackupType(s.rules[rs.ID]) == *filter.status {
+			hasStatus = true
+		}
+	}
+
+	return result, hasStatus && filter.matches(&result)
+}
+
+func (f *searchFilter) matches(result *SearchResult) bool {
+	switch {
+	case f.claimed != nil && *f.claimed != (result.ClaimedBy != ""):
+		return false
+	case f.uid != nil && *f.uid != result.UID:
+		return false
+	case f.gid != nil && *f.gid != result.GID:
+		return false
+	default:
+		return result.Size >= f.minSize
+	}
+}
@@ -0,0 +1,185 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package backend
+
+import (
+	"encoding/json"
+	"net/http"
+	"os/user"
+	"strconv"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/wtsi-hgi/backup-plans/internal/config"
+	"github.com/wtsi-hgi/backup-plans/internal/testdb"
+	"github.com/wtsi-hgi/backup-plans/users"
+)
+
+func TestSearch(t *testing.T) {
+	Convey("With a configured backend", t, func() {
+		u := userHandler(root)
+
+		cu, err := user.Current()
+		So(err, ShouldBeNil)
+
+		uid, _ := users.GetIDs(cu.Username)
+
+		s, err := New(testdb.CreateTestDatabase(t), u.getUser, config.NewConfig(t, nil, nil, nil, 0, nil))
+		So(err, ShouldBeNil)
+
+		Reset(s.Stop)
+
+		So(s.AddTree(createTestTree(t)), ShouldBeNil)
+
+		search := func(query string) (int, []string) {
+			code, resp := getResponse(s.Search, "/api/search?"+query, nil)
+			So(code, ShouldEqual, http.StatusOK)
+
+			var results SearchResults
+
+			So(json.Unmarshal([]byte(resp), &results), ShouldBeNil)
+			So(results.Truncated, ShouldBeFalse)
+
+			paths := make([]string, len(results.Results))
+
+			for n, result := range results.Results {
+				paths[n] = result.Path
+			}
+
+			return results.Total, paths
+		}
+
+		dirs := []string{
+			"/some/path/ChildDir/",
+			"/some/path/MyDir/",
+			"/some/path/OtherDir/",
+			"/some/path/YourDir/",
+		}
+
+		Convey("Searches require a query and valid filters", func() {
+			code, resp := getResponse(s.Search, "/api/search", nil)
+			checkErrorResponse(t, code, resp, ErrNoQuery)
+
+			for _, query := range []string{"claimed=maybe", "uid=-1", "gid=a", "minsize=big", "status=unknown"} {
+				code, resp = getResponse(s.Search, "/api/search?q=dir&"+query, nil)
+				checkErrorResponse(t, code, resp, ErrInvalidFilter)
+			}
+		})
+
+		Convey("You can search for directories by substring", func() {
+			total, paths := search("q=dir")
+			So(total, ShouldEqual, 4)
+			So(paths, ShouldResemble, dirs)
+
+			total, paths = search("q=child")
+			So(total, ShouldEqual, 4)
+			So(paths, ShouldResemble, []string{
+				"/some/path/ChildDir/Child/",
+				"/some/path/ChildDir/",
+				"/some/path/MyDir/ChildToClaim/",
+				"/some/path/MyDir/ChildToNotClaim/",
+			})
+
+			_, paths = search("q=/MyDir/Child")
+			So(paths, ShouldResemble, []string{
+				"/some/path/MyDir/ChildToClaim/",
+				"/some/path/MyDir/ChildToNotClaim/",
+			})
+
+			total, paths = search("q=dir&offset=1&limit=2")
+			So(total, ShouldEqual, 4)
+			So(paths, ShouldResemble, dirs[1:3])
+		})
+
+		Convey("Searches stop after visiting too many directories", func() {
+			defer func(visits int) { maxSearchVisits = visits }(maxSearchVisits)
+
+			maxSearchVisits = 5
+
+			code, resp := getResponse(s.Search, "/api/search?q=dir", nil)
+			So(code, ShouldEqual, http.StatusOK)
+
+			var results SearchResults
+
+			So(json.Unmarshal([]byte(resp), &results), ShouldBeNil)
+			So(results.Truncated, ShouldBeTrue)
+			So(results.Total, ShouldEqual, 2)
+		})
+
+		Convey("You can search for directories by glob", func() {
+			_, paths := search("q=*Dir")
+			So(paths, ShouldResemble, dirs)
+
+			_, paths = search("q=/some/path/*Dir/")
+			So(paths, ShouldResemble, dirs)
+
+			_, paths = search("q=/some/*/M*")
+			So(paths, ShouldResemble, []string{"/some/path/MyDir/"})
+		})
+
+		Convey("You can filter the results", func() {
+			code, _ := getResponse(s.ClaimDir, "/api/dir/claim?dir=/some/path/MyDir/", nil)
+			So(code, ShouldEqual, http.StatusOK)
+
+			code, _ = getResponse(s.CreateRule, "/api/rules/create?dir=/some/path/MyDir/&action=backup&match=*.txt", nil)
+			So(code, ShouldEqual, http.StatusNoContent)
+
+			_, paths := search("q=dir&claimed=true")
+			So(paths, ShouldResemble, []string{"/some/path/MyDir/"})
+
+			_, paths = search("q=dir&claimed=false")
+			So(paths, ShouldResemble, []string{dirs[0], dirs[2], dirs[3]})
+
+			owned := dirs[:2]
+			if uid == 0 {
+				owned = dirs
+			}
+
+			_, paths = search("q=dir&uid=" + strconv.FormatUint(uint64(uid), 10))
+			So(paths, ShouldResemble, owned)
+
+			_, paths = search("q=dir&gid=22")
+			So(paths, ShouldBeEmpty)
+
+			_, paths = search("q=dir&minsize=40")
+			So(paths, ShouldResemble, []string{dirs[0], dirs[2]})
+
+			_, paths = search("q=dir&status=backup")
+			So(paths, ShouldResemble, []string{"/some/path/MyDir/"})
+
+			_, paths = search("q=dir&status=unplanned")
+			So(paths, ShouldResemble, dirs)
+		})
+
+		Convey("You only see directories you are authorised to see", func() {
+			u = "nobody"
+
+			total, paths := search("q=dir")
+			So(total, ShouldEqual, 0)
+			So(paths, ShouldBeEmpty)
+		})
+	})
+}
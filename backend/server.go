@@ -39,6 +39,8 @@ import (
 	"github.com/wtsi-hgi/backup-plans/config"
 	"github.com/wtsi-hgi/backup-plans/db"
 	"github.com/wtsi-hgi/backup-plans/git"
+	"github.com/wtsi-hgi/backup-plans/ibackup"
+	"github.com/wtsi-hgi/backup-plans/mtime"
 	"github.com/wtsi-hgi/backup-plans/ruletree"
 	"vimagination.zapto.org/httpbuffer"
 	_ "vimagination.zapto.org/httpbuffer/gzip" //
@@ -62,9 +64,13 @@ type Server struct {
 	rules          map[uint64]*db.Rule
 	dirGroups      map[int64]string
 	dirBoms        map[int64]string
+	coverage       map[string]*ibackup.SetCoverage
+	workingCopies  map[string]*ibackup.GitWorkingCopy
+	templates      map[int64]*db.Template
 
-	config   *config.Config
-	gitCache *git.Cache
+	config     *config.Config
+	gitCache   *git.Cache
+	mtimeCache *mtime.Cache
 
 	rootDir *ruletree.RootDir
 
@@ -97,7 +103,20 @@ func New(db *db.DB, getUser func(r *http.Request) string, c *config.Config) (*Se
 		return nil, err
 	}
 
-	s.gitCache = git.NewCache(time.Hour)
+	if err = s.loadCoverage(); err != nil {
+		return nil, err
+	}
+
+	if err = s.loadWorkingCopies(); err != nil {
+		return nil, err
+	}
+
+	if err = s.loadTemplates(); err != nil {
+		return nil, err
+	}
+
+	s.gitCache = git.NewCache(time.Hour, c.GetGitCredentials)
+	s.mtimeCache = mtime.NewCache(time.Hour)
 
 	ctx, done := context.WithCancel(context.Background())
 
@@ -237,6 +256,18 @@ func (s *Server) getMainProgrammes(w http.ResponseWriter, _ *http.Request) error
 	return json.NewEncoder(w).Encode(s.config.GetMainProgrammes())
 }
 
+// ConfigStatus is an HTTP endpoint that returns the generation and load time of
+// the current config, and the error from the last reload if it failed.
+func (s *Server) ConfigStatus(w http.ResponseWriter, r *http.Request) {
+	handle(w, r, s.configStatus)
+}
+
+func (s *Server) configStatus(w http.ResponseWriter, _ *http.Request) error {
+	w.Header().Set("Content-type", "application/json")
+
+	return json.NewEncoder(w).Encode(s.config.Status())
+}
+
 func (s *Server) refreezer(ctx context.Context) {
 	for {
 		select {
@@ -247,6 +278,15 @@ func (s *Server) refreezer(ctx context.Context) {
 
 		s.rulesMu.Lock()
 		s.refreezeUpdatedDirectories()
+
+		if err := s.loadCoverage(); err != nil {
+			slog.Error("error loading set coverage", "err", err)
+		}
+
+		if err := s.loadWorkingCopies(); err != nil {
+			slog.Error("error loading git working copies", "err", err)
+		}
+
 		s.rulesMu.Unlock()
 	}
 }
@@ -270,6 +310,14 @@ func (s *Server) refreezeUpdatedDirectories() {
 	}
 }
 
+// Stop stops the background refreezing of directories, and the git and mtime
+// caches.
+func (s *Server) Stop() {
+	s.exit()
+	s.gitCache.Stop()
+	s.mtimeCache.Stop()
+}
+
 type rw struct{}
 
 func (rw) Write([]byte) (int, error) { return 0, nil }
@@ -116,8 +116,3 @@ func checkErrorResponse(t *testing.T, code int, resp string, err Error) {
 	So(resp, ShouldEqual, err.Error()+"\n")
 	So(code, ShouldEqual, err.Code)
 }
-
-func (s *Server) stop() {
-	s.exit()
-	s.gitCache.Stop()
-}
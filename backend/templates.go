@@ -0,0 +1,489 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package backend
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/wtsi-hgi/backup-plans/db"
+	"github.com/wtsi-hgi/backup-plans/users"
+)
+
+var (
+	ErrNoTemplate = Error{
+		Code: http.StatusBadRequest,
+		Err:  errors.New("no matching template"), //nolint:err113
+	}
+	ErrInvalidTemplate = Error{
+		Code: http.StatusBadRequest,
+		Err:  errors.New("template requires a name and rules"), //nolint:err113
+	}
+	ErrTemplateExists = Error{
+		Code: http.StatusBadRequest,
+		Err:  errors.New("template already exists"), //nolint:err113
+	}
+	ErrCannotEditTemplate = Error{
+		Code: http.StatusForbidden,
+		Err:  errors.New("not allowed to edit template"), //nolint:err113
+	}
+)
+
+// TemplateRequest is the body of a request to create or update a Template.
+type TemplateRequest struct {
+	Name  string
+	Group string
+	Rules []BulkRule
+}
+
+// TemplateInfo is a Template along with its ID.
+type TemplateInfo struct {
+	ID int64
+	*db.Template
+}
+
+// loadTemplates reads the rule templates from the database.
+//
+// Must be called with rulesMu held.
+func (s *Server) loadTemplates() error {
+	templates := make(map[int64]*db.Template)
+
+	if err := s.rulesDB.ReadTemplates().ForEach(func(t *db.Template) error {
+		templates[t.ID()] = t
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	s.templates = templates
+
+	return nil
+}
+
+// Templates is an HTTP endpoint that returns a JSON list of the rule templates
+// available to the user: those with no group, and those for groups of which
+// the user is a member. Admins can see all templates.
+//
+// Each template includes its ID, which is used to identify it in the other
+// template endpoints.
+func (s *Server) Templates(w http.ResponseWriter, r *http.Request) {
+	handle(w, r, s.listTemplates)
+}
+
+func (s *Server) listTemplates(w http.ResponseWriter, r *http.Request) error {
+	user := s.getUser(r)
+	admin := s.isAdmin(r)
+
+	s.rulesMu.RLock()
+
+	templates := make([]TemplateInfo, 0, len(s.templates))
+
+	for id, t := range s.templates {
+		if admin || canUseTemplate(t, user) {
+			templates = append(templates, TemplateInfo{ID: id, Template: t})
+		}
+	}
+
+	s.rulesMu.RUnlock()
+
+	slices.SortFunc(templates, func(a, b TemplateInfo) int {
+		return cmp.Or(strings.Compare(a.Group, b.Group), strings.Compare(a.Name, b.Name))
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+
+	return json.NewEncoder(w).Encode(templates)
+}
+
+func canUseTemplate(t *db.Template, user string) bool {
+	return t.Group == "" || inGroup(user, t.Group)
+}
+
+func inGroup(user, group string) bool {
+	_, gids := users.GetIDs(user)
+
+	for _, gid := range gids {
+		if users.Group(gid) == group {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CreateTemplate is an HTTP endpoint that creates a new rule template.
+//
+// The body of the request is a JSON encoded TemplateRequest, with the rules
+// given as for BulkRules. A template with no Group can only be created by an
+// admin; otherwise, it can be created by any member of the Group.
+func (s *Server) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	handle(w, r, s.createTemplate)
+}
+
+func (s *Server) createTemplate(w http.ResponseWriter, r *http.Request) error {
+	req, rules, err := getTemplateRequest(r)
+	if err != nil {
+		return err
+	}
+
+	if !s.canEditTemplate(r, req.Group) {
+		return ErrCannotEditTemplate
+	}
+
+	s.rulesMu.Lock()
+	defer s.rulesMu.Unlock()
+
+	for _, t := range s.templates {
+		if t.Name == req.Name && t.Group == req.Group {
+			return ErrTemplateExists
+		}
+	}
+
+	t := &db.Template{
+		Name:      req.Name,
+		Group:     req.Group,
+		Rules:     rules,
+		CreatedBy: s.getUser(r),
+	}
+
+	if err := s.rulesDB.CreateTemplate(t); err != nil {
+		return err
+	}
+
+	s.templates[t.ID()] = t
+
+	w.Header().Set("Content-Type", "application/json")
+
+	return json.NewEncoder(w).Encode(t.ID())
+}
+
+func getTemplateRequest(r *http.Request) (*TemplateRequest, []db.TemplateRule, error) {
+	var req TemplateRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, nil, Error{Code: http.StatusBadRequest, Err: err}
+	}
+
+	if req.Name == "" || len(req.Rules) == 0 {
+		return nil, nil, ErrInvalidTemplate
+	}
+
+	rules, err := bulkRuleTemplates(req.Rules, make(map[string]struct{}))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	templateRules := make([]db.TemplateRule, len(rules))
+
+	for n, rule := range rules {
+		templateRules[n] = db.TemplateRule{
+			BackupType: rule.BackupType,
+			Metadata:   rule.Metadata,
+			Match:      rule.Match,
+			Override:   rule.Override,
+		}
+	}
+
+	return &req, templateRules, nil
+}
+
+func (s *Server) canEditTemplate(r *http.Request, group string) bool {
+	if s.isAdmin(r) {
+		return true
+	}
+
+	return group != "" && inGroup(s.getUser(r), group)
+}
+
+// UpdateTemplate is an HTTP endpoint that changes the name and rules of an
+// existing rule template, identified by the 'id' GET param.
+//
+// The body of the request is as for CreateTemplate, though the Group cannot be
+// changed. Directories that used the template are not changed until
+// SyncTemplate is called.
+func (s *Server) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	handle(w, r, s.updateTemplate)
+}
+
+func (s *Server) updateTemplate(w http.ResponseWriter, r *http.Request) error {
+	req, rules, err := getTemplateRequest(r)
+	if err != nil {
+		return err
+	}
+
+	t, err := s.getEditableTemplate(r)
+	if err != nil {
+		return err
+	}
+
+	s.rulesMu.Lock()
+	defer s.rulesMu.Unlock()
+
+	for _, other := range s.templates {
+		if other != t && other.Name == req.Name && other.Group == t.Group {
+			return ErrTemplateExists
+		}
+	}
+
+	updated := *t
+	updated.Name = req.Name
+	updated.Rules = rules
+
+	if err := s.rulesDB.UpdateTemplate(&updated); err != nil {
+		return err
+	}
+
+	s.templates[t.ID()] = &updated
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+func (s *Server) getEditableTemplate(r *http.Request) (*db.Template, error) {
+	t, err := s.getTemplate(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.canEditTemplate(r, t.Group) {
+		return nil, ErrCannotEditTemplate
+	}
+
+	return t, nil
+}
+
+// getTemplate returns the template, identified by the 'id' GET param, if it is
+// available to the user.
+func (s *Server) getTemplate(r *http.Request) (*db.Template, error) {
+	id, err := strconv.ParseInt(r.FormValue("id"), 10, 64)
+	if err != nil {
+		return nil, ErrNoTemplate
+	}
+
+	s.rulesMu.RLock()
+	t, ok := s.templates[id]
+	s.rulesMu.RUnlock()
+
+	if !ok || !s.isAdmin(r) && !canUseTemplate(t, s.getUser(r)) {
+		return nil, ErrNoTemplate
+	}
+
+	return t, nil
+}
+
+// RemoveTemplate is an HTTP endpoint that removes the rule template identified
+// by the 'id' GET param. Rules created from the template are kept.
+func (s *Server) RemoveTemplate(w http.ResponseWriter, r *http.Request) {
+	handle(w, r, s.removeTemplate)
+}
+
+func (s *Server) removeTemplate(w http.ResponseWriter, r *http.Request) error {
+	t, err := s.getEditableTemplate(r)
+	if err != nil {
+		return err
+	}
+
+	s.rulesMu.Lock()
+	defer s.rulesMu.Unlock()
+
+	if err := s.rulesDB.RemoveTemplate(t); err != nil {
+		return err
+	}
+
+	delete(s.templates, t.ID())
+
+	for _, rule := range s.rules {
+		if rule.Template == t.ID() {
+			rule.Template = 0
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// ApplyTemplate is an HTTP endpoint that adds the rules of the template
+// identified by the 'id' GET param to the directory given by the 'dir' GET
+// param, as with CreateRule. The new rules remember the template they came
+// from.
+func (s *Server) ApplyTemplate(w http.ResponseWriter, r *http.Request) {
+	handle(w, r, s.applyTemplate)
+}
+
+func (s *Server) applyTemplate(w http.ResponseWriter, r *http.Request) error {
+	dir, err := getDir(r)
+	if err != nil {
+		return err
+	}
+
+	t, err := s.getTemplate(r)
+	if err != nil {
+		return err
+	}
+
+	rules := t.NewRules()
+
+	s.buildMu.Lock()
+	defer s.buildMu.Unlock()
+
+	directory, err := s.checkAddRules(r, dir, rules)
+	if err != nil {
+		return err
+	}
+
+	if err := s.addDirRules(directory, rules); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// SyncTemplate is an HTTP endpoint that brings every directory that used the
+// template identified by the 'id' GET param in line with the current rules of
+// the template: missing rules are created, rules whose action or metadata have
+// changed are updated, and rules no longer in the template are removed. Rules
+// not created from the template are left unchanged.
+//
+// As with BulkRules, only directories claimed by the user are changed, unless
+// the user is an admin, and if the 'preview' GET param is "true", no changes
+// are made. The response is a JSON list of BulkDirectory.
+func (s *Server) SyncTemplate(w http.ResponseWriter, r *http.Request) {
+	handle(w, r, s.syncTemplate)
+}
+
+func (s *Server) syncTemplate(w http.ResponseWriter, r *http.Request) error {
+	t, err := s.getTemplate(r)
+	if err != nil {
+		return err
+	}
+
+	user := s.getUser(r)
+	admin := s.isAdmin(r)
+
+	s.buildMu.Lock()
+	defer s.buildMu.Unlock()
+
+	dirs, err := s.planTemplateSync(t, user, admin)
+	if err != nil {
+		return err
+	}
+
+	if r.FormValue("preview") != "true" && len(dirs) > 0 {
+		if err := s.applyBulkRules(dirs, user); err != nil {
+			return err
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	return json.NewEncoder(w).Encode(bulkResults(dirs))
+}
+
+func (s *Server) planTemplateSync(t *db.Template, user string, admin bool) ([]*bulkDirectory, error) {
+	s.rulesMu.RLock()
+	defer s.rulesMu.RUnlock()
+
+	var dirs []*bulkDirectory
+
+	for _, dir := range s.directoryRules {
+		if !admin && dir.ClaimedBy != user || !usesTemplate(dir, t) {
+			continue
+		}
+
+		bd, err := s.planTemplateDirectory(dir, t)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(bd.create)+len(bd.update)+len(bd.remove) > 0 {
+			dirs = append(dirs, bd)
+		}
+	}
+
+	slices.SortFunc(dirs, func(a, b *bulkDirectory) int { return strings.Compare(a.Path, b.Path) })
+
+	return dirs, nil
+}
+
+func usesTemplate(dir *Directory, t *db.Template) bool {
+	for _, rule := range dir.Rules {
+		if rule.Template == t.ID() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Server) planTemplateDirectory(dir *Directory, t *db.Template) (*bulkDirectory, error) {
+	bd := &bulkDirectory{Directory: dir}
+	templateRules := make(map[string]*db.Rule, len(t.Rules))
+
+	for _, rule := range t.NewRules() {
+		templateRules[rule.Match] = rule
+
+		existing, ok := dir.Rules[rule.Match]
+		if !ok {
+			bd.create = append(bd.create, rule)
+		} else if existing.Template == t.ID() &&
+			(existing.BackupType != rule.BackupType || existing.Metadata != rule.Metadata) {
+			updated := *existing
+			updated.BackupType = rule.BackupType
+			updated.Metadata = rule.Metadata
+			bd.update = append(bd.update, &updated)
+		}
+	}
+
+	for _, rule := range dir.Rules {
+		if _, ok := templateRules[rule.Match]; !ok && rule.Template == t.ID() {
+			bd.remove = append(bd.remove, rule)
+		}
+	}
+
+	slices.SortFunc(bd.remove, func(a, b *db.Rule) int { return strings.Compare(a.Match, b.Match) })
+
+	policy := s.config.GetPolicy(dir.Path)
+
+	if err := checkRulePolicy(&policy, bd.create); err != nil {
+		return nil, err
+	}
+
+	if err := checkUpdatePolicy(&policy, bd.update); err != nil {
+		return nil, err
+	}
+
+	return bd, nil
+}
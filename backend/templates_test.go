@@ -0,0 +1,226 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package backend
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/wtsi-hgi/backup-plans/config"
+	"github.com/wtsi-hgi/backup-plans/db"
+	"github.com/wtsi-hgi/backup-plans/internal/testdb"
+)
+
+func TestTemplates(t *testing.T) {
+	Convey("Given a server with a claimed directory", t, func() {
+		u := userHandler(root)
+
+		cfg := filepath.Join(t.TempDir(), "config.yaml")
+
+		So(os.WriteFile(cfg, []byte("admingroup: 0\n"), 0600), ShouldBeNil)
+
+		c, err := config.Parse(cfg)
+		So(err, ShouldBeNil)
+
+		Reset(c.Stop)
+
+		s, err := New(testdb.CreateTestDatabase(t), u.getUser, c)
+		So(err, ShouldBeNil)
+
+		Reset(s.Stop)
+
+		So(s.AddTree(createTestTree(t)), ShouldBeNil)
+
+		const dir = "/some/path/MyDir/"
+
+		code, _ := getResponse(s.ClaimDir, "/api/dir/claim?dir="+dir, nil)
+		So(code, ShouldEqual, http.StatusOK)
+
+		const template = `{"Name": "Defaults", "Rules": [` +
+			`{"Action": "backup", "Match": ["*.vcf.gz"]}, ` +
+			`{"Action": "nobackup", "Match": ["*.tmp", "work/"]}` +
+			`]}`
+
+		Convey("Only admins can create templates without a group", func() {
+			u = "nobody"
+
+			code, resp := getResponse(s.CreateTemplate, "/api/templates/create", strings.NewReader(template))
+			checkErrorResponse(t, code, resp, ErrCannotEditTemplate)
+
+			code, resp = getResponse(s.CreateTemplate, "/api/templates/create",
+				strings.NewReader(`{"Name": "Defaults", "Group": "root", "Rules": [{"Action": "backup"}]}`))
+			checkErrorResponse(t, code, resp, ErrCannotEditTemplate)
+		})
+
+		Convey("Templates must have a name and valid rules", func() {
+			code, resp := getResponse(s.CreateTemplate, "/api/templates/create",
+				strings.NewReader(`{"Name": "", "Rules": [{"Action": "backup"}]}`))
+			checkErrorResponse(t, code, resp, ErrInvalidTemplate)
+
+			code, resp = getResponse(s.CreateTemplate, "/api/templates/create",
+				strings.NewReader(`{"Name": "Defaults", "Rules": [{"Action": "unknown"}]}`))
+			checkErrorResponse(t, code, resp, ErrInvalidAction)
+		})
+
+		Convey("You can create a template", func() {
+			code, resp := getResponse(s.CreateTemplate, "/api/templates/create", strings.NewReader(template))
+			So(code, ShouldEqual, http.StatusOK)
+			So(resp, ShouldEqual, "1\n")
+
+			code, resp = getResponse(s.CreateTemplate, "/api/templates/create", strings.NewReader(template))
+			checkErrorResponse(t, code, resp, ErrTemplateExists)
+
+			code, resp = getResponse(s.CreateTemplate, "/api/templates/create",
+				strings.NewReader(`{"Name": "Defaults", "Group": "root", "Rules": [{"Action": "backup"}]}`))
+			So(code, ShouldEqual, http.StatusOK)
+			So(resp, ShouldEqual, "2\n")
+
+			Convey("…which users can see if it has no group, or they are in the group", func() {
+				code, resp := getResponse(s.Templates, "/api/templates", nil)
+				So(code, ShouldEqual, http.StatusOK)
+
+				var templates []TemplateInfo
+
+				So(json.Unmarshal([]byte(resp), &templates), ShouldBeNil)
+				So(len(templates), ShouldEqual, 2)
+				So(templates[0].ID, ShouldEqual, 1)
+				So(templates[0].Rules, ShouldResemble, []db.TemplateRule{
+					{BackupType: db.BackupIBackup, Match: "*.vcf.gz"},
+					{BackupType: db.BackupNone, Match: "*.tmp"},
+					{BackupType: db.BackupNone, Match: "work/*"},
+				})
+				So(templates[1].Group, ShouldEqual, "root")
+
+				u = "nobody"
+
+				code, resp = getResponse(s.Templates, "/api/templates", nil)
+				So(code, ShouldEqual, http.StatusOK)
+				So(json.Unmarshal([]byte(resp), &templates), ShouldBeNil)
+				So(len(templates), ShouldEqual, 1)
+				So(templates[0].ID, ShouldEqual, 1)
+
+				code, resp = getResponse(s.ApplyTemplate, "/api/templates/apply?id=2&dir="+dir, nil)
+				checkErrorResponse(t, code, resp, ErrNoTemplate)
+			})
+
+			Convey("…and apply it to a directory", func() {
+				code, resp := getResponse(s.ApplyTemplate, "/api/templates/apply?id=1&dir="+dir, nil)
+				So(resp, ShouldBeBlank)
+				So(code, ShouldEqual, http.StatusNoContent)
+
+				rules := s.directoryRules[dir].Rules
+				So(len(rules), ShouldEqual, 3)
+				So(rules["*.vcf.gz"].BackupType, ShouldEqual, db.BackupIBackup)
+				So(rules["*.vcf.gz"].Template, ShouldEqual, 1)
+				So(rules["work/*"].Template, ShouldEqual, 1)
+
+				code, resp = getResponse(s.ApplyTemplate, "/api/templates/apply?id=1&dir="+dir, nil)
+				checkErrorResponse(t, code, resp, ErrRuleExists)
+
+				Convey("…and update directories when the template changes", func() {
+					code, resp := getResponse(s.UpdateTemplate, "/api/templates/update?id=1", strings.NewReader(
+						`{"Name": "Defaults", "Rules": [`+
+							`{"Action": "nobackup", "Match": ["*.vcf.gz", "core.*"]}, `+
+							`{"Action": "nobackup", "Match": ["*.tmp"]}`+
+							`]}`))
+					So(resp, ShouldBeBlank)
+					So(code, ShouldEqual, http.StatusNoContent)
+
+					So(rules["*.vcf.gz"].BackupType, ShouldEqual, db.BackupIBackup)
+
+					expected := []BulkDirectory{{
+						Path:      dir,
+						ClaimedBy: root,
+						Create:    []string{"core.*"},
+						Update:    []string{"*.vcf.gz"},
+						Remove:    []string{"work/*"},
+					}}
+
+					code, resp = getResponse(s.SyncTemplate, "/api/templates/sync?id=1&preview=true", nil)
+					So(code, ShouldEqual, http.StatusOK)
+
+					var dirs []BulkDirectory
+
+					So(json.Unmarshal([]byte(resp), &dirs), ShouldBeNil)
+					So(dirs, ShouldResemble, expected)
+					So(rules["*.vcf.gz"].BackupType, ShouldEqual, db.BackupIBackup)
+
+					code, resp = getResponse(s.SyncTemplate, "/api/templates/sync?id=1", nil)
+					So(code, ShouldEqual, http.StatusOK)
+					So(json.Unmarshal([]byte(resp), &dirs), ShouldBeNil)
+					So(dirs, ShouldResemble, expected)
+
+					rules = s.directoryRules[dir].Rules
+					So(len(rules), ShouldEqual, 3)
+					So(rules["*.vcf.gz"].BackupType, ShouldEqual, db.BackupNone)
+					So(rules["core.*"].Template, ShouldEqual, 1)
+					So(rules, ShouldNotContainKey, "work/*")
+
+					code, resp = getResponse(s.SyncTemplate, "/api/templates/sync?id=1", nil)
+					So(code, ShouldEqual, http.StatusOK)
+					So(resp, ShouldEqual, "[]\n")
+				})
+
+				Convey("…but rules edited since it was applied are left alone", func() {
+					code, resp := getResponse(s.UpdateRule, "/api/rules/update?dir="+dir+"&action=backup&match=work/*", nil)
+					So(resp, ShouldBeBlank)
+					So(code, ShouldEqual, http.StatusNoContent)
+					So(rules["work/*"].Template, ShouldEqual, 0)
+
+					code, resp = getResponse(s.UpdateTemplate, "/api/templates/update?id=1", strings.NewReader(
+						`{"Name": "Defaults", "Rules": [`+
+							`{"Action": "backup", "Match": ["*.vcf.gz"]}, `+
+							`{"Action": "nobackup", "Match": ["*.tmp"]}`+
+							`]}`))
+					So(resp, ShouldBeBlank)
+					So(code, ShouldEqual, http.StatusNoContent)
+
+					code, resp = getResponse(s.SyncTemplate, "/api/templates/sync?id=1", nil)
+					So(code, ShouldEqual, http.StatusOK)
+					So(resp, ShouldEqual, "[]\n")
+					So(rules["work/*"].BackupType, ShouldEqual, db.BackupIBackup)
+				})
+
+				Convey("…and removing the template keeps the rules", func() {
+					code, resp := getResponse(s.RemoveTemplate, "/api/templates/remove?id=1", nil)
+					So(resp, ShouldBeBlank)
+					So(code, ShouldEqual, http.StatusNoContent)
+
+					So(len(rules), ShouldEqual, 3)
+					So(rules["*.vcf.gz"].Template, ShouldEqual, 0)
+
+					code, resp = getResponse(s.SyncTemplate, "/api/templates/sync?id=1", nil)
+					checkErrorResponse(t, code, resp, ErrNoTemplate)
+				})
+			})
+		})
+	})
+}
@@ -32,6 +32,7 @@ import (
 	"slices"
 	"strings"
 
+	"github.com/wtsi-hgi/backup-plans/config"
 	"github.com/wtsi-hgi/backup-plans/db"
 	"github.com/wtsi-hgi/backup-plans/ruletree"
 	"github.com/wtsi-hgi/backup-plans/users"
@@ -64,15 +65,19 @@ func (s *Server) AddTree(file string) error {
 	return s.updateDirMaps(rootPath)
 }
 
-func (s *Server) updateDirSummaries(path string) error {
+func (s *Server) updateDirSummaries(paths ...string) error {
 	s.rulesMu.Lock()
 	defer s.rulesMu.Unlock()
 
 	toUpdate := make([]string, 0, len(s.directoryRules))
 
 	for p := range s.directoryRules {
-		if strings.HasPrefix(p, path) || strings.HasPrefix(path, p) {
-			toUpdate = append(toUpdate, p)
+		for _, path := range paths {
+			if strings.HasPrefix(p, path) || strings.HasPrefix(path, p) {
+				toUpdate = append(toUpdate, p)
+
+				break
+			}
 		}
 	}
 
@@ -89,12 +94,15 @@ func (s *Server) updateDirSummaries(path string) error {
 	return nil
 }
 
-type treeDB struct {
+// TreeDB is the data about a directory, and its children, produced by the Tree
+// endpoint.
+type TreeDB struct {
 	*ruletree.DirSummary
 	ClaimedBy    string
 	Rules        map[string]map[uint64]*db.Rule
 	Unauthorised []string
 	CanClaim     bool
+	Policy       *config.Policy `json:",omitempty"`
 	dirDetails
 }
 
@@ -130,12 +138,16 @@ func (s *Server) tree(w http.ResponseWriter, r *http.Request) error { //nolint:f
 		return ErrNotAuthorised
 	}
 
-	t := treeDB{
+	t := TreeDB{
 		DirSummary:   summary,
 		Rules:        make(map[string]map[uint64]*db.Rule),
 		Unauthorised: []string{},
 	}
 
+	if policy := s.config.GetPolicy(dir); policy.Prefix != "" {
+		t.Policy = &policy
+	}
+
 	t.CanClaim = isOwner(uid, groups, duid, dgid)
 
 	for name, child := range summary.Children {
@@ -0,0 +1,312 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package backend
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/wtsi-hgi/backup-plans/ruletree"
+	"github.com/wtsi-hgi/backup-plans/users"
+	"vimagination.zapto.org/tree"
+)
+
+var ErrInvalidDepth = Error{
+	Code: http.StatusBadRequest,
+	Err:  errors.New("invalid depth"), //nolint:err113
+}
+
+const (
+	defaultUnplannedDepth = 1
+	defaultUnplannedLimit = 20
+	maxUnplannedDepth     = 10
+)
+
+// UnplannedDir describes the files in an unclaimed directory that are not
+// matched by any rule.
+type UnplannedDir struct {
+	Path     string
+	Root     string
+	UID, GID uint32
+	User     string
+	Group    string
+	BOM      string
+	Files    uint64
+	Size     uint64
+	LastMod  uint64
+}
+
+// Unplanned is an HTTP endpoint that lists the unclaimed directories, beneath
+// the reporting roots, containing the most data not matched by any rule.
+//
+// The optional 'depth' GET param sets how many levels below each reporting
+// root the directories are, defaulting to 1, with 0 meaning the roots
+// themselves, and a maximum of 10. The optional 'limit' GET param sets the
+// number of directories to return, defaulting to 20, with a maximum of 1000.
+//
+// The response is a JSON encoded slice of UnplannedDir, largest first.
+// Claimed directories, and their descendants, are not included, and the files
+// and size of a directory do not include those of any claimed descendants,
+// though LastMod may.
+func (s *Server) Unplanned(w http.ResponseWriter, r *http.Request) {
+	handle(w, r, s.unplanned)
+}
+
+func (s *Server) unplanned(w http.ResponseWriter, r *http.Request) error {
+	depth, err := getDepth(r)
+	if err != nil {
+		return err
+	}
+
+	limit, err := getPageParam(r, "limit", defaultUnplannedLimit)
+	if err != nil {
+		return err
+	} else if limit == 0 || limit > maxFileLimit {
+		return ErrInvalidLimit
+	}
+
+	s.rulesMu.RLock()
+	dirs, err := s.collectUnplanned(depth)
+	s.rulesMu.RUnlock()
+
+	if err != nil {
+		return err
+	}
+
+	slices.SortFunc(dirs, func(a, b UnplannedDir) int {
+		return cmp.Or(cmp.Compare(b.Size, a.Size), strings.Compare(a.Path, b.Path))
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+
+	return json.NewEncoder(w).Encode(dirs[:min(limit, len(dirs))])
+}
+
+func getDepth(r *http.Request) (int, error) {
+	str := r.FormValue("depth")
+	if str == "" {
+		return defaultUnplannedDepth, nil
+	}
+
+	n, err := strconv.ParseUint(str, 10, 8)
+	if err != nil || n > maxUnplannedDepth {
+		return 0, ErrInvalidDepth
+	}
+
+	return int(n), nil
+}
+
+// collectUnplanned returns the unclaimed directories, at the given depth below
+// each reporting root, that contain files not matched by any rule.
+//
+// The caller must hold a read lock on rulesMu.
+func (s *Server) collectUnplanned(depth int) ([]UnplannedDir, error) {
+	u := unplannedCollector{
+		Server:  s,
+		boms:    s.reverseBOMMap(s.config.GetBOMs()),
+		claimed: slices.Sorted(maps.Keys(s.directoryRules)),
+		seen:    make(map[string]struct{}),
+		found:   []UnplannedDir{},
+	}
+
+	for _, root := range s.rootDir.GlobPaths(s.config.GetReportingRoots()...) {
+		if _, claimed := s.directoryRules[root]; claimed {
+			continue
+		}
+
+		ds, err := u.summary(root)
+		if err != nil {
+			return nil, err
+		} else if ds == nil {
+			continue
+		}
+
+		if err := u.walk(root, root, ds, depth); err != nil {
+			return nil, err
+		}
+	}
+
+	return u.found, nil
+}
+
+type unplannedCollector struct {
+	*Server
+	boms    map[string]string
+	claimed []string
+	seen    map[string]struct{}
+	found   []UnplannedDir
+}
+
+// walk descends, through unclaimed directories, the given number of levels
+// below the given directory, adding those directories containing unplanned
+// files.
+func (u *unplannedCollector) walk(root, path string, ds *ruletree.DirSummary, depth int) error {
+	if depth == 0 {
+		return u.add(root, path, ds)
+	}
+
+	for name, child := range ds.Children {
+		childPath := path + name
+
+		if _, claimed := u.directoryRules[childPath]; claimed || unplannedRule(child) == nil {
+			continue
+		}
+
+		if depth == 1 {
+			if err := u.add(root, childPath, child); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		cds, err := u.summary(childPath)
+		if err != nil {
+			return err
+		} else if cds == nil {
+			continue
+		}
+
+		if err := u.walk(root, childPath, cds, depth-1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (u *unplannedCollector) summary(path string) (*ruletree.DirSummary, error) {
+	ds, err := u.rootDir.Summary(path)
+	if errors.Is(err, ruletree.ErrNotFound) || errors.As(err, new(tree.ChildNotFoundError)) {
+		return nil, nil //nolint:nilnil
+	}
+
+	return ds, err
+}
+
+func (u *unplannedCollector) add(root, path string, ds *ruletree.DirSummary) error {
+	rule := unplannedRule(ds)
+	if rule == nil {
+		return nil
+	}
+
+	if _, ok := u.seen[path]; ok {
+		return nil
+	}
+
+	u.seen[path] = struct{}{}
+
+	claimedFiles, claimedSize, err := u.claimedUnplanned(path)
+	if err != nil {
+		return err
+	}
+
+	uid, gid := ds.IDs()
+	group := users.Group(gid)
+	dir := UnplannedDir{
+		Path:    path,
+		Root:    root,
+		UID:     uid,
+		GID:     gid,
+		User:    users.Username(uid),
+		Group:   group,
+		BOM:     u.boms[group],
+		LastMod: rule.LastMod(),
+	}
+
+	for _, stats := range rule.Users {
+		dir.Files += stats.Files
+		dir.Size += stats.Size
+	}
+
+	if dir.Files <= claimedFiles {
+		return nil
+	}
+
+	dir.Files -= claimedFiles
+	dir.Size -= min(claimedSize, dir.Size)
+
+	u.found = append(u.found, dir)
+
+	return nil
+}
+
+// claimedUnplanned returns the number and size of the files not matched by any
+// rule within the claimed descendants of the given directory.
+func (u *unplannedCollector) claimedUnplanned(path string) (uint64, uint64, error) {
+	var (
+		files, size uint64
+		outer       string
+	)
+
+	start, _ := slices.BinarySearch(u.claimed, path)
+
+	for _, claimed := range u.claimed[start:] {
+		if !strings.HasPrefix(claimed, path) {
+			break
+		}
+
+		if claimed == path || outer != "" && strings.HasPrefix(claimed, outer) {
+			continue
+		}
+
+		outer = claimed
+
+		ds, err := u.summary(claimed)
+		if err != nil {
+			return 0, 0, err
+		} else if ds == nil {
+			continue
+		}
+
+		if rule := unplannedRule(ds); rule != nil {
+			for _, stats := range rule.Users {
+				files += stats.Files
+				size += stats.Size
+			}
+		}
+	}
+
+	return files, size, nil
+}
+
+// unplannedRule returns the summary of the files in the given directory that
+// are not matched by any rule, or nil if there are none.
+func unplannedRule(ds *ruletree.DirSummary) *ruletree.Rule {
+	for n := range ds.RuleSummaries {
+		if ds.RuleSummaries[n].ID == 0 {
+			return &ds.RuleSummaries[n]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package backend
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/user"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/wtsi-hgi/backup-plans/internal/config"
+	"github.com/wtsi-hgi/backup-plans/internal/directories"
+	"github.com/wtsi-hgi/backup-plans/internal/testdb"
+	"github.com/wtsi-hgi/backup-plans/users"
+	"vimagination.zapto.org/tree"
+)
+
+func TestUnplanned(t *testing.T) {
+	Convey("Given a server with a reporting root containing unplanned files", t, func() {
+		u, err := user.Current()
+		So(err, ShouldBeNil)
+
+		uid, gids := users.GetIDs(u.Username)
+		gid := gids[0]
+		group := users.Group(gid)
+
+		root := directories.NewRoot("/data/", 1)
+		projects := root.AddDirectory("projects")
+		projects.AddDirectory("p1").SetMeta(uid, gid, 1).AddDirectory("deep").SetMeta(uid, gid, 1)
+		projects.AddDirectory("p2").SetMeta(uid, gid, 1)
+		projects.AddDirectory("p3").SetMeta(uid, gid, 1)
+
+		directories.AddFile(&root.Directory, "projects/p1/a.bam", uid, gid, 100, 10)
+		directories.AddFile(&root.Directory, "projects/p1/deep/b.bam", uid, gid, 50, 20)
+		directories.AddFile(&root.Directory, "projects/p2/c.bam", uid, gid, 500, 30)
+		directories.AddFile(&root.Directory, "projects/p3/d.txt", uid, gid, 10, 5)
+		directories.AddFile(&root.Directory, "other/e.txt", uid, gid, 1000, 40)
+
+		treeFile := filepath.Join(t.TempDir(), "tree.db")
+
+		f, err := os.Create(treeFile)
+		So(err, ShouldBeNil)
+		So(tree.Serialise(f, root), ShouldBeNil)
+		So(f.Close(), ShouldBeNil)
+
+		s, err := New(testdb.CreateTestDatabase(t), func(*http.Request) string { return u.Username },
+			config.NewConfig(t, map[string][]string{"bomA": {group}}, nil, []string{"/data/projects/"}, 0, nil))
+		So(err, ShouldBeNil)
+		So(s.AddTree(treeFile), ShouldBeNil)
+
+		getUnplanned := func(query string) []UnplannedDir {
+			code, resp := getResponse(s.Unplanned, "/api/report/unplanned"+query, nil)
+			So(code, ShouldEqual, http.StatusOK)
+
+			var dirs []UnplannedDir
+
+			So(json.Unmarshal([]byte(resp), &dirs), ShouldBeNil)
+
+			return dirs
+		}
+
+		dir := func(path string, files, size, lastMod uint64) UnplannedDir {
+			return UnplannedDir{
+				Path:    path,
+				Root:    "/data/projects/",
+				UID:     uid,
+				GID:     gid,
+				User:    u.Username,
+				Group:   group,
+				BOM:     "bomA",
+				Files:   files,
+				Size:    size,
+				LastMod: lastMod,
+			}
+		}
+
+		Convey("You can get the largest unplanned directories below the reporting roots", func() {
+			So(getUnplanned(""), ShouldResemble, []UnplannedDir{
+				dir("/data/projects/p2/", 1, 500, 30),
+				dir("/data/projects/p1/", 2, 150, 20),
+				dir("/data/projects/p3/", 1, 10, 5),
+			})
+			So(getUnplanned("?limit=1"), ShouldResemble, []UnplannedDir{
+				dir("/data/projects/p2/", 1, 500, 30),
+			})
+			So(getUnplanned("?depth=2"), ShouldResemble, []UnplannedDir{
+				dir("/data/projects/p1/deep/", 1, 50, 20),
+			})
+		})
+
+		Convey("Claimed directories are not included", func() {
+			code, _ := getResponse(s.ClaimDir, "/api/dir/claim?dir=/data/projects/p2/", nil)
+			So(code, ShouldEqual, http.StatusOK)
+
+			So(getUnplanned(""), ShouldResemble, []UnplannedDir{
+				dir("/data/projects/p1/", 2, 150, 20),
+				dir("/data/projects/p3/", 1, 10, 5),
+			})
+		})
+
+		Convey("Files in claimed descendants are not counted", func() {
+			code, _ := getResponse(s.ClaimDir, "/api/dir/claim?dir=/data/projects/p1/deep/", nil)
+			So(code, ShouldEqual, http.StatusOK)
+
+			So(getUnplanned(""), ShouldResemble, []UnplannedDir{
+				dir("/data/projects/p2/", 1, 500, 30),
+				dir("/data/projects/p1/", 1, 100, 20),
+				dir("/data/projects/p3/", 1, 10, 5),
+			})
+			So(getUnplanned("?depth=2"), ShouldBeEmpty)
+		})
+
+		Convey("Invalid parameters return an error", func() {
+			code, resp := getResponse(s.Unplanned, "/api/report/unplanned?depth=deep", nil)
+			checkErrorResponse(t, code, resp, ErrInvalidDepth)
+
+			code, resp = getResponse(s.Unplanned, "/api/report/unplanned?depth=11", nil)
+			checkErrorResponse(t, code, resp, ErrInvalidDepth)
+
+			So(getUnplanned("?depth=10"), ShouldBeEmpty)
+
+			code, resp = getResponse(s.Unplanned, "/api/report/unplanned?limit=0", nil)
+			checkErrorResponse(t, code, resp, ErrInvalidLimit)
+		})
+	})
+}
@@ -32,7 +32,9 @@ import (
 	"slices"
 )
 
-type userGroupsBOM struct {
+// UserGroupsBOM contains the users and groups produced by the UserGroups
+// endpoint.
+type UserGroupsBOM struct {
 	Users, Groups []string
 	Owners, BOM   map[string][]string
 }
@@ -68,7 +70,7 @@ func (s *Server) userGroups(w http.ResponseWriter, _ *http.Request) error {
 
 	w.Header().Set("Content-Type", "application/json")
 
-	return json.NewEncoder(w).Encode(userGroupsBOM{
+	return json.NewEncoder(w).Encode(UserGroupsBOM{
 		Users:  slices.Collect(maps.Keys(users)),
 		Groups: slices.Collect(maps.Keys(groups)),
 		Owners: owners, BOM: bom,
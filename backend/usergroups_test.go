@@ -64,7 +64,7 @@ func TestUserGroups(t *testing.T) {
 			code, resp := getResponse(s.UserGroups, "/api/usergroups", nil)
 			So(code, ShouldEqual, http.StatusOK)
 
-			var usergroups = userGroupsBOM{}
+			var usergroups = UserGroupsBOM{}
 
 			err = json.NewDecoder(strings.NewReader(resp)).Decode(&usergroups)
 			So(err, ShouldBeNil)
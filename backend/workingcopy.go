@@ -0,0 +1,71 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package backend
+
+import (
+	"time"
+
+	"github.com/wtsi-hgi/backup-plans/db"
+	"github.com/wtsi-hgi/backup-plans/ibackup"
+)
+
+// loadWorkingCopies reads the git working copy results, as stored by the backup
+// command, from the database, keying them by repo. Where a repo has been
+// checked in multiple directories, the most recent result is kept.
+//
+// Must be called with rulesMu held.
+func (s *Server) loadWorkingCopies() error {
+	workingCopies := make(map[string]*ibackup.GitWorkingCopy)
+
+	if err := s.rulesDB.ReadGitWorkingCopies().ForEach(func(wc *db.GitWorkingCopy) error {
+		if _, ok := s.dirs[uint64(wc.DirID())]; !ok { //nolint:gosec
+			return nil
+		}
+
+		checked := time.Unix(wc.Checked, 0)
+
+		if existing, ok := workingCopies[wc.Repo]; ok && existing.Checked.After(checked) {
+			return nil
+		}
+
+		workingCopies[wc.Repo] = &ibackup.GitWorkingCopy{
+			Checked:     checked,
+			Path:        wc.Path,
+			Uncommitted: wc.Uncommitted,
+			Untracked:   wc.Untracked,
+			Unpushed:    wc.Unpushed,
+			Error:       wc.Error,
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	s.workingCopies = workingCopies
+
+	return nil
+}
@@ -64,20 +64,7 @@ func Backup(planDB *db.DB, treeNode *tree.MemTree, client *ibackup.MultiClient)
 		return nil, err
 	}
 
-	root := ruletree.NewRuleTree()
-
-	for _, dr := range dirs {
-		root.Set(dr.Path, dr.Rules, false)
-	}
-
-	root.Canon()
-	root.MarkBackupDirs()
-
-	rules := root.BuildRules()
-
-	rules[0] = collectRuleGroups(root, "/", rules[0])
-
-	sm, err := ruletree.BuildMultiStateMachine(rules)
+	sm, err := buildStateMachine(dirs, db.BackupIBackup)
 	if err != nil {
 		return nil, err
 	}
@@ -98,6 +85,25 @@ func Backup(planDB *db.DB, treeNode *tree.MemTree, client *ibackup.MultiClient)
 	return addFofnsToIBackup(client, setFofns)
 }
 
+// buildStateMachine creates a statemachine from the given directory rules that
+// will only enter directories affected by rules of the given backup type.
+func buildStateMachine(dirs map[int64]*dirRules, bt db.BackupType) (ruletree.State, error) {
+	root := ruletree.NewRuleTree()
+
+	for _, dr := range dirs {
+		root.Set(dr.Path, dr.Rules, false)
+	}
+
+	root.Canon()
+	root.MarkDirsWithType(bt)
+
+	rules := root.BuildRules()
+
+	rules[0] = collectRuleGroups(root, "/", rules[0])
+
+	return ruletree.BuildMultiStateMachine(rules)
+}
+
 func readMountpoint(treeNode *tree.MemTree) (string, error) {
 	var (
 		mountpoint string
@@ -34,9 +34,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-git/go-git/v6"
 	_ "github.com/go-sql-driver/mysql"
 	. "github.com/smartystreets/goconvey/convey"
 	"github.com/wtsi-hgi/backup-plans/db"
+	gitplans "github.com/wtsi-hgi/backup-plans/git"
 	"github.com/wtsi-hgi/backup-plans/ibackup"
 	"github.com/wtsi-hgi/backup-plans/internal/directories"
 	"github.com/wtsi-hgi/backup-plans/internal/memtree"
@@ -386,3 +388,145 @@ func (f frozenTest) Backup(path, _, _ string, _ []server.PathMTime,
 
 	return nil
 }
+
+func TestVerifyManualSets(t *testing.T) {
+	Convey("Given a plan database with a manual ibackup rule and a tree of wrstat info", t, func() {
+		testDB, _ := plandb.PopulateExamplePlanDB(t)
+
+		tr, dFn, err := memtree.FromTree(exampleTree(), filepath.Join(t.TempDir(), "tree"))
+		So(err, ShouldBeNil)
+
+		Reset(dFn)
+
+		client := make(setFiles)
+
+		Convey("Files missing from the set are reported", func() {
+			client["manualSetName"] = nil
+
+			coverage, err := VerifyManualSets(testDB, tr, client)
+			So(err, ShouldBeNil)
+			So(len(coverage), ShouldEqual, 1)
+			So(coverage[0].Directory.Path, ShouldEqual, "/lustre/scratch123/humgen/a/c/")
+			So(coverage[0].SetName, ShouldEqual, "manualSetName")
+			So(coverage[0].Matched, ShouldEqual, 1)
+			So(coverage[0].Missing, ShouldResemble, []string{"/lustre/scratch123/humgen/a/c/4.txt"})
+			So(coverage[0].Stale, ShouldBeNil)
+			So(coverage[0].Extra, ShouldBeEmpty)
+			So(coverage[0].Percent(), ShouldEqual, 0)
+		})
+
+		Convey("Files uploaded before they were last modified are reported as stale", func() {
+			client["manualSetName"] = []ibackup.SetFile{
+				{Path: "/lustre/scratch123/humgen/a/c/4.txt", Uploaded: time.Unix(100, 0)},
+			}
+
+			coverage, err := VerifyManualSets(testDB, tr, client)
+			So(err, ShouldBeNil)
+			So(len(coverage), ShouldEqual, 1)
+			So(coverage[0].Missing, ShouldBeNil)
+			So(coverage[0].Stale, ShouldResemble, []string{"/lustre/scratch123/humgen/a/c/4.txt"})
+		})
+
+		Convey("Uploaded files are covered, and unmatched files in the set are reported as extra", func() {
+			client["manualSetName"] = []ibackup.SetFile{
+				{Path: "/lustre/scratch123/humgen/a/c/4.txt", Uploaded: time.Unix(20000, 0)},
+				{Path: "/lustre/scratch123/humgen/a/c/old.txt", Uploaded: time.Unix(20000, 0)},
+				{Path: "/lustre/scratch123/humgen/b/5.txt", Uploaded: time.Unix(20000, 0)},
+			}
+
+			coverage, err := VerifyManualSets(testDB, tr, client)
+			So(err, ShouldBeNil)
+			So(len(coverage), ShouldEqual, 1)
+			So(coverage[0].Missing, ShouldBeNil)
+			So(coverage[0].Stale, ShouldBeNil)
+			So(coverage[0].Extra, ShouldResemble, []string{"/lustre/scratch123/humgen/a/c/old.txt"})
+			So(coverage[0].Covered(), ShouldEqual, 1)
+			So(coverage[0].Percent(), ShouldEqual, 100)
+		})
+
+		Convey("Sets that cannot be retrieved are returned as errors", func() {
+			coverage, err := VerifyManualSets(testDB, tr, client)
+			So(err, ShouldNotBeNil)
+			So(coverage, ShouldBeEmpty)
+		})
+	})
+}
+
+type setFiles map[string][]ibackup.SetFile
+
+func (s setFiles) GetSetFiles(_, setName, _ string, _ bool) ([]ibackup.SetFile, error) {
+	files, ok := s[setName]
+	if !ok {
+		return nil, server.ErrBadSet
+	}
+
+	return files, nil
+}
+
+func TestCheckGitWorkingCopies(t *testing.T) {
+	Convey("Given a plan database with manualgit rules and a tree of wrstat info", t, func() {
+		testDB, _ := plandb.CreateTestDatabase(t)
+
+		dirA := &db.Directory{
+			Path:      "/lustre/scratch123/humgen/a/",
+			ClaimedBy: "userA",
+		}
+		dirB := &db.Directory{
+			Path:      "/lustre/scratch123/humgen/b/",
+			ClaimedBy: "userB",
+		}
+
+		So(testDB.CreateDirectory(dirA), ShouldBeNil)
+		So(testDB.CreateDirectory(dirB), ShouldBeNil)
+
+		So(testDB.CreateDirectoryRule(dirA, &db.Rule{
+			BackupType: db.BackupManualGit,
+			Match:      "*",
+			Metadata:   "https://example.com/repoA",
+		}), ShouldBeNil)
+		So(testDB.CreateDirectoryRule(dirA, &db.Rule{
+			BackupType: db.BackupManualGit,
+			Match:      "*.txt",
+			Metadata:   "https://example.com/repoA",
+		}), ShouldBeNil)
+		So(testDB.CreateDirectoryRule(dirB, &db.Rule{
+			BackupType: db.BackupManualGit,
+			Match:      "*",
+			Metadata:   "https://example.com/repoB",
+		}), ShouldBeNil)
+
+		tr, dFn, err := memtree.FromTree(exampleTree(), filepath.Join(t.TempDir(), "tree"))
+		So(err, ShouldBeNil)
+
+		Reset(dFn)
+
+		Convey("You can check the local working copy for each repo", func() {
+			checks, err := CheckGitWorkingCopies(testDB, tr, workingCopies{
+				"https://example.com/repoA": {Path: dirA.Path, Unpushed: 3},
+			})
+			So(err, ShouldBeNil)
+			So(len(checks), ShouldEqual, 2)
+
+			So(checks[0].Directory.Path, ShouldEqual, dirA.Path)
+			So(checks[0].Repo, ShouldEqual, "https://example.com/repoA")
+			So(checks[0].Path, ShouldEqual, dirA.Path)
+			So(checks[0].Unpushed, ShouldEqual, 3)
+			So(checks[0].Error, ShouldBeBlank)
+
+			So(checks[1].Directory.Path, ShouldEqual, dirB.Path)
+			So(checks[1].Repo, ShouldEqual, "https://example.com/repoB")
+			So(checks[1].Error, ShouldEqual, git.ErrRepositoryNotExists.Error())
+		})
+	})
+}
+
+type workingCopies map[string]gitplans.WorkingCopyStatus
+
+func (w workingCopies) CheckWorkingCopy(_, remoteURL string) (*gitplans.WorkingCopyStatus, error) {
+	wcs, ok := w[remoteURL]
+	if !ok {
+		return nil, git.ErrRepositoryNotExists
+	}
+
+	return &wcs, nil
+}
@@ -0,0 +1,114 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package backups
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/wtsi-hgi/backup-plans/db"
+	"github.com/wtsi-hgi/backup-plans/git"
+	"vimagination.zapto.org/tree"
+)
+
+// GitCheck contains the result of checking the local working copy for a
+// manualgit rule on a directory.
+type GitCheck struct {
+	Directory *db.Directory
+	*db.GitWorkingCopy
+}
+
+type workingCopyChecker interface {
+	CheckWorkingCopy(path, remoteURL string) (*git.WorkingCopyStatus, error)
+}
+
+// CheckGitWorkingCopies checks, for every manualgit rule in the given planDB on
+// a directory under the mountpoint of the given treeNode, the local working
+// copy in that directory against the remote repo named by the rule.
+//
+// Failures to check a working copy are recorded in the Error field of the
+// result for that rule.
+//
+// The returned results are sorted by directory path and then repo.
+func CheckGitWorkingCopies(planDB *db.DB, treeNode *tree.MemTree, checker workingCopyChecker) ([]GitCheck, error) {
+	mountpoint, err := readMountpoint(treeNode)
+	if err != nil {
+		return nil, err
+	}
+
+	dirs, _, err := readDirRules(planDB, mountpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var checks []GitCheck
+
+	for _, dr := range dirs {
+		seen := make(map[string]struct{})
+
+		for _, rule := range dr.RuleIDs {
+			if _, ok := seen[rule.Metadata]; ok || rule.BackupType != db.BackupManualGit {
+				continue
+			}
+
+			seen[rule.Metadata] = struct{}{}
+
+			checks = append(checks, GitCheck{
+				Directory:      dr.Directory,
+				GitWorkingCopy: checkWorkingCopy(checker, dr.Path, rule.Metadata),
+			})
+		}
+	}
+
+	slices.SortFunc(checks, func(a, b GitCheck) int {
+		return cmp.Or(strings.Compare(a.Directory.Path, b.Directory.Path), strings.Compare(a.Repo, b.Repo))
+	})
+
+	return checks, nil
+}
+
+func checkWorkingCopy(checker workingCopyChecker, path, repo string) *db.GitWorkingCopy {
+	wc := &db.GitWorkingCopy{
+		Repo:    repo,
+		Checked: time.Now().Unix(),
+	}
+
+	status, err := checker.CheckWorkingCopy(path, repo)
+	if err != nil {
+		wc.Error = err.Error()
+
+		return wc
+	}
+
+	wc.Path = status.Path
+	wc.Uncommitted = uint64(status.Uncommitted) //nolint:gosec
+	wc.Untracked = uint64(status.Untracked)     //nolint:gosec
+	wc.Unpushed = uint64(status.Unpushed)       //nolint:gosec
+
+	return wc
+}
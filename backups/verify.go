@@ -0,0 +1,208 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package backups
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/wtsi-hgi/backup-plans/db"
+	"github.com/wtsi-hgi/backup-plans/ibackup"
+	"github.com/wtsi-hgi/ibackup/server"
+	"github.com/wtsi-hgi/wrstat-ui/summary"
+	"vimagination.zapto.org/tree"
+)
+
+// SetCoverage describes how well a manual ibackup set covers the files matched
+// by the manualibackup rules that name it on a directory.
+//
+// Missing lists matched files that are not in the set, or have never been
+// uploaded; Stale lists matched files that have been modified since they were
+// uploaded; Extra lists files in the set, under the directory, that are not
+// matched by the rules.
+type SetCoverage struct {
+	Directory *db.Directory
+	SetName   string
+	Matched   int
+	Missing   []string
+	Stale     []string
+	Extra     []string
+}
+
+// Covered returns the number of matched files that are in the set and have
+// been uploaded since they were last modified.
+func (s *SetCoverage) Covered() int {
+	return s.Matched - len(s.Missing) - len(s.Stale)
+}
+
+// Percent returns the percentage of the matched files that are covered by the
+// set.
+func (s *SetCoverage) Percent() float64 {
+	return ibackup.CoveragePercent(uint64(s.Matched), uint64(len(s.Missing)+len(s.Stale))) //nolint:gosec
+}
+
+// DBCoverage converts the result into a form that can be stored in a plan
+// database.
+func (s *SetCoverage) DBCoverage(checked time.Time) *db.SetCoverage {
+	return &db.SetCoverage{
+		SetName: s.SetName,
+		Matched: uint64(s.Matched),      //nolint:gosec
+		Missing: uint64(len(s.Missing)), //nolint:gosec
+		Stale:   uint64(len(s.Stale)),   //nolint:gosec
+		Extra:   uint64(len(s.Extra)),   //nolint:gosec
+		Checked: checked.Unix(),
+	}
+}
+
+type setFilesClient interface {
+	GetSetFiles(path, setName, requester string, manual bool) ([]ibackup.SetFile, error)
+}
+
+type dirSet struct {
+	dir *db.Directory
+	set string
+}
+
+// VerifyManualSets walks the given treeNode, using the same rules as Backup,
+// collecting the files matched by each manualibackup rule in the given planDB.
+// It then compares those files with the files listed in the set named by each
+// rule, using the given ibackup client.
+//
+// The returned results are sorted by directory path and then set name. Sets
+// that could not be retrieved are skipped, with their errors joined and
+// returned alongside the other results.
+func VerifyManualSets(planDB *db.DB, treeNode *tree.MemTree, client setFilesClient) ([]*SetCoverage, error) {
+	mountpoint, err := readMountpoint(treeNode)
+	if err != nil {
+		return nil, err
+	}
+
+	dirs, dirRules, err := readDirRules(planDB, mountpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	sm, err := buildStateMachine(dirs, db.BackupManualIBackup)
+	if err != nil {
+		return nil, err
+	}
+
+	setFiles := make(map[dirSet][]server.PathMTime)
+
+	for _, dr := range dirs {
+		for _, rule := range dr.RuleIDs {
+			if rule.BackupType == db.BackupManualIBackup {
+				setFiles[dirSet{dr.Directory, rule.Metadata}] = nil
+			}
+		}
+	}
+
+	figureOutFOFNs(treeNode, sm, nil, func(path *summary.DirectoryPath, mtime, ruleID int64) {
+		dr, ok := dirRules[ruleID]
+		if !ok {
+			return
+		}
+
+		if rule := dr.RuleIDs[ruleID]; rule.BackupType == db.BackupManualIBackup {
+			ds := dirSet{dr.Directory, rule.Metadata}
+
+			setFiles[ds] = append(setFiles[ds], server.PathMTime{
+				Path:  string(path.AppendTo(nil)),
+				MTime: mtime,
+			})
+		}
+	})
+
+	return verifySets(client, setFiles)
+}
+
+func verifySets(client setFilesClient, setFiles map[dirSet][]server.PathMTime) ([]*SetCoverage, error) {
+	coverage := make([]*SetCoverage, 0, len(setFiles))
+
+	var errs error
+
+	for ds, files := range setFiles {
+		sc, err := verifySet(client, ds, files)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("%s (%s): %w", ds.set, ds.dir.Path, err))
+
+			continue
+		}
+
+		coverage = append(coverage, sc)
+	}
+
+	slices.SortFunc(coverage, func(a, b *SetCoverage) int {
+		return cmp.Or(strings.Compare(a.Directory.Path, b.Directory.Path), strings.Compare(a.SetName, b.SetName))
+	})
+
+	return coverage, errs
+}
+
+func verifySet(client setFilesClient, ds dirSet, files []server.PathMTime) (*SetCoverage, error) {
+	inSet, err := client.GetSetFiles(ds.dir.Path, ds.set, ds.dir.ClaimedBy, true)
+	if err != nil {
+		return nil, err
+	}
+
+	uploaded := make(map[string]time.Time, len(inSet))
+
+	for _, file := range inSet {
+		if strings.HasPrefix(file.Path, ds.dir.Path) {
+			uploaded[file.Path] = file.Uploaded
+		}
+	}
+
+	sc := &SetCoverage{
+		Directory: ds.dir,
+		SetName:   ds.set,
+		Matched:   len(files),
+	}
+
+	for _, file := range files {
+		t, ok := uploaded[file.Path]
+
+		delete(uploaded, file.Path)
+
+		if !ok || t.IsZero() {
+			sc.Missing = append(sc.Missing, file.Path)
+		} else if t.Unix() < file.MTime {
+			sc.Stale = append(sc.Stale, file.Path)
+		}
+	}
+
+	sc.Extra = slices.Sorted(maps.Keys(uploaded))
+
+	slices.Sort(sc.Missing)
+	slices.Sort(sc.Stale)
+
+	return sc, nil
+}
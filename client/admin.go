@@ -0,0 +1,81 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package client
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/wtsi-hgi/backup-plans/db"
+)
+
+// AdminReassign, as an admin, passes the claim of the given directory to
+// another user, optionally notifying the previous claimant.
+func (c *Client) AdminReassign(dir, to string, notify bool) error {
+	params := adminParams(dir, notify)
+	params.Set("to", to)
+
+	return c.postForm("/api/admin/reassign", params, nil)
+}
+
+// AdminRemoveRule, as an admin, removes the rule with the given match from the
+// given directory, optionally notifying the claimant.
+func (c *Client) AdminRemoveRule(dir, match string, notify bool) error {
+	params := adminParams(dir, notify)
+	params.Set("match", match)
+
+	return c.postForm("/api/admin/rules/remove", params, nil)
+}
+
+// AdminUnfreeze, as an admin, unfreezes the given directory, optionally
+// notifying the claimant.
+func (c *Client) AdminUnfreeze(dir string, notify bool) error {
+	return c.postForm("/api/admin/unfreeze", adminParams(dir, notify), nil)
+}
+
+// AdminRevoke, as an admin, revokes the claim of the given directory,
+// optionally notifying the claimant.
+func (c *Client) AdminRevoke(dir string, notify bool) error {
+	return c.postForm("/api/admin/revoke", adminParams(dir, notify), nil)
+}
+
+// AdminAudit returns the log of all admin actions.
+func (c *Client) AdminAudit() ([]*db.AuditEntry, error) {
+	var entries []*db.AuditEntry
+
+	if err := c.get("/api/admin/audit", nil, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func adminParams(dir string, notify bool) url.Values {
+	params := dirParams(dir)
+	params.Set("notify", strconv.FormatBool(notify))
+
+	return params
+}
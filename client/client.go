@@ -0,0 +1,186 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+// Package client provides a typed client for the HTTP API of a backup-plans
+// server.
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/wtsi-hgi/backup-plans/backend"
+)
+
+var ErrInvalidURL = errors.New("server URL must be absolute")
+
+// Client sends requests to a backup-plans server.
+type Client struct {
+	base   string
+	header http.Header
+
+	// HTTPClient is used to send requests, defaulting to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// New returns a Client for the backup-plans server at the given URL. The given
+// header, which may be nil, is sent with every request, and can be used to
+// authenticate, for example with an Authorization header.
+func New(server string, header http.Header) (*Client, error) {
+	u, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	if !u.IsAbs() || u.Host == "" {
+		return nil, ErrInvalidURL
+	}
+
+	return &Client{
+		base:       strings.TrimSuffix(u.String(), "/"),
+		header:     header,
+		HTTPClient: http.DefaultClient,
+	}, nil
+}
+
+// get sends a GET request, with the given query params, to the given API path,
+// decoding the JSON response into out.
+func (c *Client) get(path string, params url.Values, out any) error {
+	resp, err := c.send(http.MethodGet, path, params, "", nil)
+	if err != nil {
+		return err
+	}
+
+	return decode(resp, out)
+}
+
+// postForm sends a POST request, with the given params form encoded in the body,
+// to the given API path, decoding any JSON response into out.
+func (c *Client) postForm(path string, params url.Values, out any) error {
+	resp, err := c.send(http.MethodPost, path, nil, "application/x-www-form-urlencoded",
+		strings.NewReader(params.Encode()))
+	if err != nil {
+		return err
+	}
+
+	return decode(resp, out)
+}
+
+// postJSON sends a POST request, with the given query params and the given body
+// JSON encoded, to the given API path, decoding any JSON response into out.
+func (c *Client) postJSON(path string, params url.Values, body, out any) error {
+	var buf bytes.Buffer
+
+	if err := json.NewEncoder(&buf).Encode(body); err != nil {
+		return err
+	}
+
+	resp, err := c.send(http.MethodPost, path, params, "application/json", &buf)
+	if err != nil {
+		return err
+	}
+
+	return decode(resp, out)
+}
+
+// download sends a GET request, with the given query params, to the given API
+// path, copying the response body to w.
+func (c *Client) download(w io.Writer, path string, params url.Values) error {
+	resp, err := c.send(http.MethodGet, path, params, "", nil)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	_, err = io.Copy(w, resp.Body)
+
+	return err
+}
+
+// send sends a request to the server, returning an error, as a backend.Error,
+// if the server doesn't respond with a success code.
+func (c *Client) send(method, path string, params url.Values, contentType string, body io.Reader) (*http.Response, error) {
+	u := c.base + path
+
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequest(method, u, body) //nolint:noctx
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range c.header {
+		req.Header[name] = values
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.HTTPClient.Do(req) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		defer resp.Body.Close()
+
+		msg, _ := io.ReadAll(resp.Body) //nolint:errcheck
+
+		return nil, backend.Error{
+			Code: resp.StatusCode,
+			Err:  errors.New(strings.TrimSpace(string(msg))), //nolint:err113
+		}
+	}
+
+	return resp, nil
+}
+
+func decode(resp *http.Response, out any) error {
+	defer resp.Body.Close()
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func dirParams(dir string) url.Values {
+	return url.Values{"dir": {dir}}
+}
+
+func idParams(id int64) url.Values {
+	return url.Values{"id": {strconv.FormatInt(id, 10)}}
+}
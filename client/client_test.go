@@ -0,0 +1,376 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package client
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/user"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/wtsi-hgi/backup-plans/backend"
+	"github.com/wtsi-hgi/backup-plans/db"
+	"github.com/wtsi-hgi/backup-plans/internal/config"
+	"github.com/wtsi-hgi/backup-plans/internal/directories"
+	"github.com/wtsi-hgi/backup-plans/internal/testdb"
+	"github.com/wtsi-hgi/backup-plans/server"
+	"github.com/wtsi-hgi/backup-plans/users"
+	"vimagination.zapto.org/tree"
+)
+
+func TestClient(t *testing.T) {
+	Convey("Given a backup-plans server", t, func() {
+		u, err := user.Current()
+		So(err, ShouldBeNil)
+
+		username := u.Username
+		_, gids := users.GetIDs(username)
+
+		s, err := backend.New(testdb.CreateTestDatabase(t), func(*http.Request) string { return username },
+			config.NewConfig(t, nil, nil, nil, gids[0], nil))
+		So(err, ShouldBeNil)
+
+		So(s.AddTree(createTestTree(t)), ShouldBeNil)
+
+		srv := httptest.NewServer(server.Handler(s))
+
+		Reset(srv.Close)
+
+		Convey("You can't create a client with a relative URL", func() {
+			_, err := New("/api/", nil)
+			So(err, ShouldEqual, ErrInvalidURL)
+		})
+
+		c, err := New(srv.URL, nil)
+		So(err, ShouldBeNil)
+
+		Convey("You can get the authenticated user", func() {
+			user, err := c.WhoAmI()
+			So(err, ShouldBeNil)
+			So(user, ShouldEqual, username)
+		})
+
+		Convey("Errors from the server are returned as backend errors", func() {
+			_, err := c.ClaimDir("/does/not/exist")
+
+			var berr backend.Error
+
+			So(errors.As(err, &berr), ShouldBeTrue)
+			So(berr, ShouldResemble, backend.ErrInvalidDir)
+		})
+
+		Convey("You can claim a directory and manage its rules", func() {
+			claimant, err := c.ClaimDir("/some/path/MyDir/")
+			So(err, ShouldBeNil)
+			So(claimant, ShouldEqual, username)
+
+			_, err = c.ClaimDir("/some/path/MyDir/")
+			So(err, ShouldResemble, backend.ErrDirectoryClaimed)
+
+			rule := backend.BulkRule{Action: "backup", Match: []string{"*.txt"}}
+
+			preview, err := c.PreviewCreateRule("/some/path/MyDir/", rule)
+			So(err, ShouldBeNil)
+			So(preview.Before.Directory[int(db.BackupIBackup)], ShouldBeNil)
+			So(preview.After.Directory[int(db.BackupIBackup)], ShouldResemble, &backend.SizeCount{Count: 1, Size: 3})
+
+			So(c.CreateRule("/some/path/MyDir/", rule), ShouldBeNil)
+			So(c.CreateRule("/some/path/MyDir/", rule), ShouldResemble, backend.ErrRuleExists)
+
+			td, err := c.Tree("/some/path/MyDir/")
+			So(err, ShouldBeNil)
+			So(td.ClaimedBy, ShouldEqual, username)
+			So(td.Rules["/some/path/MyDir/"], ShouldHaveLength, 1)
+
+			for _, r := range td.Rules["/some/path/MyDir/"] {
+				So(r.Match, ShouldEqual, "*.txt")
+				So(r.BackupType, ShouldEqual, db.BackupIBackup)
+			}
+
+			files, err := c.Files("/some/path/MyDir/", "*.txt", Page{})
+			So(err, ShouldBeNil)
+			So(files.Total, ShouldEqual, 1)
+			So(files.Files[0].Path, ShouldEqual, "/some/path/MyDir/a.txt")
+
+			files, err = c.Files("/some/path/MyDir/", "", Page{Limit: 1})
+			So(err, ShouldBeNil)
+			So(files.Total, ShouldEqual, 1)
+			So(files.Files[0].Path, ShouldEqual, "/some/path/MyDir/b.csv")
+
+			e, err := c.Explain("/some/path/MyDir/a.txt")
+			So(err, ShouldBeNil)
+			So(e, ShouldNotBeNil)
+
+			rule.Action = "nobackup"
+
+			So(c.UpdateRule("/some/path/MyDir/", rule), ShouldBeNil)
+
+			td, err = c.Tree("/some/path/MyDir/")
+			So(err, ShouldBeNil)
+
+			for _, r := range td.Rules["/some/path/MyDir/"] {
+				So(r.BackupType, ShouldEqual, db.BackupNone)
+			}
+
+			So(c.SetDirDetails("/some/path/MyDir/", DirDetails{
+				Frequency: 7,
+				Review:    time.Now().Add(time.Hour),
+				Remove:    time.Now().Add(2 * time.Hour),
+			}), ShouldBeNil)
+			So(c.SetDirDetails("/some/path/MyDir/", DirDetails{
+				Review: time.Now().Add(2 * time.Hour),
+				Remove: time.Now().Add(time.Hour),
+			}), ShouldResemble, backend.ErrInvalidTime)
+
+			td, err = c.Tree("/some/path/MyDir/")
+			So(err, ShouldBeNil)
+			So(td.Frequency, ShouldEqual, 7)
+
+			So(c.RemoveRule("/some/path/MyDir/", "*.txt"), ShouldBeNil)
+			So(c.RemoveRule("/some/path/MyDir/", "*.txt"), ShouldResemble, backend.ErrNoRule)
+
+			So(c.RevokeDirClaim("/some/path/MyDir/"), ShouldBeNil)
+
+			td, err = c.Tree("/some/path/MyDir/")
+			So(err, ShouldBeNil)
+			So(td.ClaimedBy, ShouldBeEmpty)
+		})
+
+		Convey("You can search for directories and change rules in bulk", func() {
+			results, err := c.Search("MyDir", SearchFilter{}, Page{})
+			So(err, ShouldBeNil)
+			So(results.Total, ShouldEqual, 1)
+			So(results.Results[0].Path, ShouldEqual, "/some/path/MyDir/")
+
+			_, err = c.ClaimDir("/some/path/MyDir/")
+			So(err, ShouldBeNil)
+
+			So(c.PassDirClaim("/some/path/MyDir/", "not-a-user"), ShouldResemble, backend.ErrInvalidUser)
+			So(c.PassDirClaim("/some/path/MyDir/", username), ShouldBeNil)
+
+			rule := backend.BulkRule{Action: "backup", Match: []string{"*.txt"}}
+
+			So(c.CreateRule("/some/path/MyDir/", rule), ShouldBeNil)
+
+			rule.Action = "nobackup"
+
+			preview, err := c.PreviewUpdateRule("/some/path/MyDir/", rule)
+			So(err, ShouldBeNil)
+			So(preview.After.Directory[int(db.BackupNone)], ShouldResemble, &backend.SizeCount{Count: 1, Size: 3})
+
+			preview, err = c.PreviewRemoveRule("/some/path/MyDir/", "*.txt")
+			So(err, ShouldBeNil)
+			So(preview.After.Directory[int(db.BackupIBackup)], ShouldBeNil)
+
+			dirs, err := c.BulkRules(&backend.BulkRules{
+				Glob:    "/some/path/*/",
+				Update:  []backend.BulkRule{rule},
+				Preview: true,
+			})
+			So(err, ShouldBeNil)
+			So(dirs, ShouldResemble, []backend.BulkDirectory{{
+				Path:      "/some/path/MyDir/",
+				ClaimedBy: username,
+				Update:    []string{"*.txt"},
+			}})
+
+			td, err := c.Tree("/some/path/MyDir/")
+			So(err, ShouldBeNil)
+
+			for _, r := range td.Rules["/some/path/MyDir/"] {
+				So(r.BackupType, ShouldEqual, db.BackupIBackup)
+			}
+
+			exists, err := c.SetExists("/lustre/", "not-a-set")
+			So(err, ShouldBeNil)
+			So(exists, ShouldBeFalse)
+
+			Convey("…and, as an admin, manage the claims of others", func() {
+				So(c.AdminUnfreeze("/some/path/MyDir/", false), ShouldResemble, backend.ErrDirectoryNotFrozen)
+				So(c.AdminRemoveRule("/some/path/MyDir/", "*.txt", false), ShouldBeNil)
+				So(c.AdminReassign("/some/path/MyDir/", "not-a-user", false), ShouldResemble, backend.ErrInvalidUser)
+				So(c.AdminRevoke("/some/path/MyDir/", false), ShouldBeNil)
+
+				entries, err := c.AdminAudit()
+				So(err, ShouldBeNil)
+				So(entries, ShouldHaveLength, 2)
+				So(entries[0].Action, ShouldEqual, backend.AdminRemoveRule)
+				So(entries[1].Action, ShouldEqual, backend.AdminRevoke)
+			})
+		})
+
+		Convey("You can manage templates", func() {
+			_, gids := users.GetIDs(username)
+
+			id, err := c.CreateTemplate(&backend.TemplateRequest{
+				Name:  "Text",
+				Group: users.Group(gids[0]),
+				Rules: []backend.BulkRule{{Action: "backup", Match: []string{"*.txt"}}},
+			})
+			So(err, ShouldBeNil)
+
+			templates, err := c.Templates()
+			So(err, ShouldBeNil)
+			So(templates, ShouldHaveLength, 1)
+			So(templates[0].ID, ShouldEqual, id)
+			So(templates[0].Name, ShouldEqual, "Text")
+
+			_, err = c.ClaimDir("/some/path/MyDir/")
+			So(err, ShouldBeNil)
+
+			So(c.ApplyTemplate(id, "/some/path/MyDir/"), ShouldBeNil)
+
+			td, err := c.Tree("/some/path/MyDir/")
+			So(err, ShouldBeNil)
+			So(td.Rules["/some/path/MyDir/"], ShouldHaveLength, 1)
+
+			So(c.UpdateTemplate(id, &backend.TemplateRequest{
+				Name:  "Text",
+				Rules: []backend.BulkRule{{Action: "nobackup", Match: []string{"*.txt"}}},
+			}), ShouldBeNil)
+
+			dirs, err := c.SyncTemplate(id, true)
+			So(err, ShouldBeNil)
+			So(dirs, ShouldResemble, []backend.BulkDirectory{{
+				Path:      "/some/path/MyDir/",
+				ClaimedBy: username,
+				Update:    []string{"*.txt"},
+			}})
+
+			dirs, err = c.SyncTemplate(id, false)
+			So(err, ShouldBeNil)
+			So(dirs, ShouldHaveLength, 1)
+
+			dirs, err = c.SyncTemplate(id, false)
+			So(err, ShouldBeNil)
+			So(dirs, ShouldBeEmpty)
+
+			So(c.RemoveTemplate(id), ShouldBeNil)
+
+			templates, err = c.Templates()
+			So(err, ShouldBeNil)
+			So(templates, ShouldBeEmpty)
+		})
+
+		Convey("You can get the report summary", func() {
+			summary, err := c.Summary()
+			So(err, ShouldBeNil)
+			So(summary, ShouldNotBeNil)
+
+			export, err := c.SummaryExport()
+			So(err, ShouldBeNil)
+			So(export, ShouldNotBeNil)
+
+			ug, err := c.UserGroups()
+			So(err, ShouldBeNil)
+			So(ug, ShouldNotBeNil)
+
+			var buf bytes.Buffer
+
+			So(c.WriteSummaryCSV(&buf, ""), ShouldBeNil)
+			So(buf.Len(), ShouldBeGreaterThan, 0)
+			So(c.WriteSummaryCSV(&buf, "not-a-sheet"), ShouldResemble, backend.ErrUnknownSheet)
+
+			buf.Reset()
+
+			So(c.WriteSummaryODS(&buf), ShouldBeNil)
+			So(buf.Len(), ShouldBeGreaterThan, 0)
+
+			_, err = c.MainProgrammes()
+			So(err, ShouldBeNil)
+
+			status, err := c.ConfigStatus()
+			So(err, ShouldBeNil)
+			So(status, ShouldNotBeNil)
+
+			_, err = c.ReportHistory(time.Time{}, time.Now(), "")
+			So(err, ShouldBeNil)
+
+			_, err = c.Unplanned(1, 0)
+			So(err, ShouldBeNil)
+		})
+
+		Convey("You can get the claim stats", func() {
+			_, err := c.ClaimDir("/some/path/MyDir/")
+			So(err, ShouldBeNil)
+			So(c.CreateRule("/some/path/MyDir/", backend.BulkRule{Action: "backup", Match: []string{"*.txt"}}), ShouldBeNil)
+
+			stats, err := c.ClaimStats(ClaimStatsFilter{User: username})
+			So(err, ShouldBeNil)
+			So(stats, ShouldHaveLength, 1)
+			So(stats[0].Path, ShouldEqual, "/some/path/MyDir/")
+
+			rows, err := c.ClaimStatsExport(ClaimStatsFilter{})
+			So(err, ShouldBeNil)
+			So(rows, ShouldHaveLength, 1)
+			So(rows[0].Path, ShouldEqual, "/some/path/MyDir/")
+
+			var buf bytes.Buffer
+
+			So(c.WriteClaimStatsCSV(&buf, ClaimStatsFilter{}), ShouldBeNil)
+			So(buf.String(), ShouldContainSubstring, "/some/path/MyDir/")
+
+			buf.Reset()
+
+			So(c.WriteClaimStatsODS(&buf, ClaimStatsFilter{}), ShouldBeNil)
+			So(buf.Len(), ShouldBeGreaterThan, 0)
+
+			stats, err = c.ClaimStats(ClaimStatsFilter{User: "not-a-user"})
+			So(err, ShouldBeNil)
+			So(stats, ShouldBeEmpty)
+		})
+	})
+}
+
+func createTestTree(t *testing.T) string {
+	t.Helper()
+
+	u, err := user.Current()
+	So(err, ShouldBeNil)
+
+	uid, _ := users.GetIDs(u.Username)
+
+	treeDB := directories.NewRoot("/some/path/", time.Now().Unix())
+	treeDB.AddDirectory("MyDir").UID = uid
+	directories.AddFile(&treeDB.Directory, "MyDir/a.txt", uid, 2, 3, 4)
+	directories.AddFile(&treeDB.Directory, "MyDir/b.csv", uid, 2, 5, 6)
+	directories.AddFile(&treeDB.Directory, "YourDir/c.tsv", 21, 22, 15, 16)
+
+	treeDBPath := filepath.Join(t.TempDir(), "a.db")
+
+	f, err := os.Create(treeDBPath)
+	So(err, ShouldBeNil)
+	So(tree.Serialise(f, treeDB), ShouldBeNil)
+	So(f.Close(), ShouldBeNil)
+
+	return treeDBPath
+}
@@ -0,0 +1,224 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package client
+
+import (
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/wtsi-hgi/backup-plans/backend"
+	"github.com/wtsi-hgi/backup-plans/config"
+)
+
+// ClaimStatsFilter restricts claim stats to the directories claimed by User
+// and/or owned by the group or BOM GroupBOM; empty fields are not filtered on.
+type ClaimStatsFilter struct {
+	User, GroupBOM string
+}
+
+func (f ClaimStatsFilter) params() url.Values {
+	params := url.Values{}
+
+	if f.User != "" {
+		params.Set("user", f.User)
+	}
+
+	if f.GroupBOM != "" {
+		params.Set("groupbom", f.GroupBOM)
+	}
+
+	return params
+}
+
+// WhoAmI returns the username of the authenticated user.
+func (c *Client) WhoAmI() (string, error) {
+	var user string
+
+	if err := c.get("/api/whoami", nil, &user); err != nil {
+		return "", err
+	}
+
+	return user, nil
+}
+
+// Summary returns the summary of the reporting roots.
+func (c *Client) Summary() (*backend.ReportSummary, error) {
+	var summary backend.ReportSummary
+
+	if err := c.get("/api/report/summary", nil, &summary); err != nil {
+		return nil, err
+	}
+
+	return &summary, nil
+}
+
+// SummaryExport returns the per-directory and per-programme rows of the report
+// summary.
+func (c *Client) SummaryExport() (*backend.ReportExport, error) {
+	var export backend.ReportExport
+
+	if err := c.get("/api/report/summary.json", nil, &export); err != nil {
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+// WriteSummaryCSV writes a sheet, by default the per-directory sheet, of the
+// report summary to w as CSV.
+func (c *Client) WriteSummaryCSV(w io.Writer, sheet string) error {
+	return c.download(w, "/api/report/summary.csv", sheetParams(sheet))
+}
+
+// WriteSummaryODS writes the report summary to w as an ODS spreadsheet.
+func (c *Client) WriteSummaryODS(w io.Writer) error {
+	return c.download(w, "/api/report/summary.ods", nil)
+}
+
+// ClaimStats returns information about the filtered claimed directories and
+// their rules.
+func (c *Client) ClaimStats(filter ClaimStatsFilter) ([]backend.DirStats, error) {
+	var stats []backend.DirStats
+
+	if err := c.postForm("/api/claimstats", filter.params(), &stats); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// ClaimStatsExport returns a row for each rule of the filtered claimed
+// directories.
+func (c *Client) ClaimStatsExport(filter ClaimStatsFilter) ([]backend.ClaimStatsRow, error) {
+	var rows []backend.ClaimStatsRow
+
+	if err := c.get("/api/claimstats.json", filter.params(), &rows); err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// WriteClaimStatsCSV writes the rules of the filtered claimed directories to w
+// as CSV.
+func (c *Client) WriteClaimStatsCSV(w io.Writer, filter ClaimStatsFilter) error {
+	return c.download(w, "/api/claimstats.csv", filter.params())
+}
+
+// WriteClaimStatsODS writes the rules of the filtered claimed directories to w
+// as an ODS spreadsheet.
+func (c *Client) WriteClaimStatsODS(w io.Writer, filter ClaimStatsFilter) error {
+	return c.download(w, "/api/claimstats.ods", filter.params())
+}
+
+// UserGroups returns the known users, groups, owners and BOMs.
+func (c *Client) UserGroups() (*backend.UserGroupsBOM, error) {
+	var ug backend.UserGroupsBOM
+
+	if err := c.get("/api/usergroups", nil, &ug); err != nil {
+		return nil, err
+	}
+
+	return &ug, nil
+}
+
+// MainProgrammes returns the names of the programmes shown on the report page.
+func (c *Client) MainProgrammes() ([]string, error) {
+	var programmes []string
+
+	if err := c.get("/api/mainprogrammes", nil, &programmes); err != nil {
+		return nil, err
+	}
+
+	return programmes, nil
+}
+
+// ConfigStatus returns the status of the server configuration.
+func (c *Client) ConfigStatus() (*config.Status, error) {
+	var status config.Status
+
+	if err := c.get("/api/config/status", nil, &status); err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+func sheetParams(sheet string) url.Values {
+	if sheet == "" {
+		return nil
+	}
+
+	return url.Values{"sheet": {sheet}}
+}
+
+// ReportHistory returns the snapshots of the report totals taken between the
+// given times, oldest first. A zero from or to leaves that end unbounded, and a
+// non-empty root limits the totals to those of that reporting root.
+func (c *Client) ReportHistory(from, to time.Time, root string) ([]backend.ReportSnapshot, error) {
+	params := url.Values{}
+
+	if !from.IsZero() {
+		params.Set("from", strconv.FormatInt(from.Unix(), 10))
+	}
+
+	if !to.IsZero() {
+		params.Set("to", strconv.FormatInt(to.Unix(), 10))
+	}
+
+	if root != "" {
+		params.Set("root", root)
+	}
+
+	var snapshots []backend.ReportSnapshot
+
+	if err := c.get("/api/report/history", params, &snapshots); err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+// Unplanned returns the unclaimed directories, at the given depth below the
+// reporting roots, containing the most unplanned data, largest first. A zero
+// limit uses the server default.
+func (c *Client) Unplanned(depth, limit int) ([]backend.UnplannedDir, error) {
+	params := url.Values{"depth": {strconv.Itoa(depth)}}
+
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+
+	var dirs []backend.UnplannedDir
+
+	if err := c.get("/api/report/unplanned", params, &dirs); err != nil {
+		return nil, err
+	}
+
+	return dirs, nil
+}
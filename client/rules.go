@@ -0,0 +1,285 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package client
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/wtsi-hgi/backup-plans/backend"
+)
+
+// Page selects a page of the results of the Files and Search endpoints. A zero
+// Limit uses the server default.
+type Page struct {
+	Offset, Limit int
+}
+
+func (p Page) params(params url.Values) url.Values {
+	if p.Offset > 0 {
+		params.Set("offset", strconv.Itoa(p.Offset))
+	}
+
+	if p.Limit > 0 {
+		params.Set("limit", strconv.Itoa(p.Limit))
+	}
+
+	return params
+}
+
+// SearchFilter contains the optional filters for a directory search.
+type SearchFilter struct {
+	// Claimed, when not nil, restricts results to claimed or unclaimed
+	// directories.
+	Claimed *bool
+
+	// UID and GID, when not nil, restrict results to directories with the
+	// given owner.
+	UID, GID *uint32
+
+	// MinSize is the minimum total size of the files in the directory.
+	MinSize uint64
+
+	// Status is a backup type name (eg. backup, nobackup), or unplanned, of
+	// which the directory must contain files.
+	Status string
+}
+
+func (f SearchFilter) params(params url.Values) url.Values {
+	if f.Claimed != nil {
+		params.Set("claimed", strconv.FormatBool(*f.Claimed))
+	}
+
+	if f.UID != nil {
+		params.Set("uid", strconv.FormatUint(uint64(*f.UID), 10))
+	}
+
+	if f.GID != nil {
+		params.Set("gid", strconv.FormatUint(uint64(*f.GID), 10))
+	}
+
+	if f.MinSize > 0 {
+		params.Set("minsize", strconv.FormatUint(f.MinSize, 10))
+	}
+
+	if f.Status != "" {
+		params.Set("status", f.Status)
+	}
+
+	return params
+}
+
+// DirDetails are the backup settings of a claimed directory.
+type DirDetails struct {
+	// Frequency is the number of days between ibackup backups.
+	Frequency uint
+
+	// Frozen sets whether backed up files are only ever uploaded once, and
+	// ToggleMelt, for a frozen directory, toggles whether changed files will be
+	// uploaded once more.
+	Frozen, ToggleMelt bool
+
+	// Review and Remove are the dates at which the plan should be reviewed,
+	// and at which the backups will be removed.
+	Review, Remove time.Time
+}
+
+// Tree returns data about the given directory and its direct children.
+func (c *Client) Tree(dir string) (*backend.TreeDB, error) {
+	var t backend.TreeDB
+
+	if err := c.get("/api/tree", dirParams(dir), &t); err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// Files lists the files beneath the given directory that are matched by the
+// rule with the given match string, or by no rule when match is empty, largest
+// first.
+func (c *Client) Files(dir, match string, page Page) (*backend.FileList, error) {
+	params := dirParams(dir)
+
+	if match != "" {
+		params.Set("rule", match)
+	}
+
+	var files backend.FileList
+
+	if err := c.get("/api/files", page.params(params), &files); err != nil {
+		return nil, err
+	}
+
+	return &files, nil
+}
+
+// Explain explains which rule applies to the given file.
+func (c *Client) Explain(path string) (*backend.Explanation, error) {
+	var e backend.Explanation
+
+	if err := c.get("/api/explain", url.Values{"path": {path}}, &e); err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+// Search finds the directories, in all of the loaded trees, matching the given
+// glob or substring and filter.
+func (c *Client) Search(query string, filter SearchFilter, page Page) (*backend.SearchResults, error) {
+	var results backend.SearchResults
+
+	if err := c.get("/api/search", page.params(filter.params(url.Values{"q": {query}})), &results); err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
+
+// ClaimDir claims the given directory for the authenticated user, returning
+// their username.
+func (c *Client) ClaimDir(dir string) (string, error) {
+	var user string
+
+	if err := c.postForm("/api/dir/claim", dirParams(dir), &user); err != nil {
+		return "", err
+	}
+
+	return user, nil
+}
+
+// PassDirClaim passes the claim of the given directory to another user.
+func (c *Client) PassDirClaim(dir, passTo string) error {
+	params := dirParams(dir)
+	params.Set("passTo", passTo)
+
+	return c.postForm("/api/dir/pass", params, nil)
+}
+
+// RevokeDirClaim revokes the claim of the given directory, removing its rules.
+func (c *Client) RevokeDirClaim(dir string) error {
+	return c.postForm("/api/dir/revoke", dirParams(dir), nil)
+}
+
+// SetDirDetails changes the backup settings of the given directory.
+func (c *Client) SetDirDetails(dir string, details DirDetails) error {
+	params := dirParams(dir)
+	params.Set("frequency", strconv.FormatUint(uint64(details.Frequency), 10))
+	params.Set("frozen", strconv.FormatBool(details.Frozen))
+	params.Set("meltToggle", strconv.FormatBool(details.ToggleMelt))
+	params.Set("review", strconv.FormatInt(details.Review.Unix(), 10))
+	params.Set("remove", strconv.FormatInt(details.Remove.Unix(), 10))
+
+	return c.postForm("/api/dir/setdetails", params, nil)
+}
+
+func ruleParams(dir string, rule backend.BulkRule) url.Values {
+	params := dirParams(dir)
+	params.Set("action", rule.Action)
+	params.Set("metadata", rule.Metadata)
+	params.Set("override", strconv.FormatBool(rule.Override))
+	params["match"] = rule.Match
+
+	return params
+}
+
+// CreateRule adds a rule, for each of the matches of the given rule, to the
+// given directory.
+func (c *Client) CreateRule(dir string, rule backend.BulkRule) error {
+	return c.postForm("/api/rules/create", ruleParams(dir, rule), nil)
+}
+
+// UpdateRule changes the action and metadata of the existing rules of the given
+// directory with the matches of the given rule.
+func (c *Client) UpdateRule(dir string, rule backend.BulkRule) error {
+	return c.postForm("/api/rules/update", ruleParams(dir, rule), nil)
+}
+
+// RemoveRule removes the rule with the given match from the given directory.
+func (c *Client) RemoveRule(dir, match string) error {
+	params := dirParams(dir)
+	params.Set("match", match)
+
+	return c.postForm("/api/rules/remove", params, nil)
+}
+
+// PreviewCreateRule shows what CreateRule would do, without making the change.
+func (c *Client) PreviewCreateRule(dir string, rule backend.BulkRule) (*backend.RulePreview, error) {
+	return c.previewRule("create", ruleParams(dir, rule))
+}
+
+// PreviewUpdateRule shows what UpdateRule would do, without making the change.
+func (c *Client) PreviewUpdateRule(dir string, rule backend.BulkRule) (*backend.RulePreview, error) {
+	return c.previewRule("update", ruleParams(dir, rule))
+}
+
+// PreviewRemoveRule shows what RemoveRule would do, without making the change.
+func (c *Client) PreviewRemoveRule(dir, match string) (*backend.RulePreview, error) {
+	params := dirParams(dir)
+	params.Set("match", match)
+
+	return c.previewRule("remove", params)
+}
+
+func (c *Client) previewRule(op string, params url.Values) (*backend.RulePreview, error) {
+	params.Set("op", op)
+
+	var preview backend.RulePreview
+
+	if err := c.postForm("/api/rules/preview", params, &preview); err != nil {
+		return nil, err
+	}
+
+	return &preview, nil
+}
+
+// BulkRules applies the given rule changes to every claimed directory matching
+// the filters, returning the directories that were, or with Preview set would
+// be, changed.
+func (c *Client) BulkRules(bulk *backend.BulkRules) ([]backend.BulkDirectory, error) {
+	var dirs []backend.BulkDirectory
+
+	if err := c.postJSON("/api/rules/bulk", nil, bulk, &dirs); err != nil {
+		return nil, err
+	}
+
+	return dirs, nil
+}
+
+// SetExists returns whether the authenticated user has a manual ibackup set with
+// the given name.
+func (c *Client) SetExists(dir, setName string) (bool, error) {
+	params := dirParams(dir)
+	params.Set("metadata", setName)
+
+	var exists bool
+
+	err := c.get("/api/setExists", params, &exists)
+
+	return exists, err
+}
@@ -0,0 +1,90 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package client
+
+import (
+	"strconv"
+
+	"github.com/wtsi-hgi/backup-plans/backend"
+)
+
+// Templates returns the rule templates available to the authenticated user.
+func (c *Client) Templates() ([]backend.TemplateInfo, error) {
+	var templates []backend.TemplateInfo
+
+	if err := c.get("/api/templates", nil, &templates); err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+// CreateTemplate creates a new rule template, returning its ID.
+func (c *Client) CreateTemplate(t *backend.TemplateRequest) (int64, error) {
+	var id int64
+
+	if err := c.postJSON("/api/templates/create", nil, t, &id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// UpdateTemplate replaces the name, group and rules of the template with the
+// given ID.
+func (c *Client) UpdateTemplate(id int64, t *backend.TemplateRequest) error {
+	return c.postJSON("/api/templates/update", idParams(id), t, nil)
+}
+
+// RemoveTemplate removes the template with the given ID.
+func (c *Client) RemoveTemplate(id int64) error {
+	return c.postForm("/api/templates/remove", idParams(id), nil)
+}
+
+// ApplyTemplate adds the rules of the template with the given ID to the given
+// directory.
+func (c *Client) ApplyTemplate(id int64, dir string) error {
+	params := idParams(id)
+	params.Set("dir", dir)
+
+	return c.postForm("/api/templates/apply", params, nil)
+}
+
+// SyncTemplate updates the directories to which the template with the given ID
+// was applied, returning the directories that were, or with preview set would
+// be, changed.
+func (c *Client) SyncTemplate(id int64, preview bool) ([]backend.BulkDirectory, error) {
+	params := idParams(id)
+	params.Set("preview", strconv.FormatBool(preview))
+
+	var dirs []backend.BulkDirectory
+
+	if err := c.postForm("/api/templates/sync", params, &dirs); err != nil {
+		return nil, err
+	}
+
+	return dirs, nil
+}
@@ -27,6 +27,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -34,14 +35,20 @@ import (
 	"github.com/wtsi-hgi/backup-plans/backups"
 	"github.com/wtsi-hgi/backup-plans/config"
 	"github.com/wtsi-hgi/backup-plans/db"
+	"github.com/wtsi-hgi/backup-plans/git"
 	"github.com/wtsi-hgi/backup-plans/internal/memtree"
+	"vimagination.zapto.org/tree"
 )
 
+var ErrNoPlanDB = errors.New("--plan must be set when env variable " +
+	"'BACKUP_PLANS_CONNECTION' and the config plandb setting are not")
+
 // options for this cmd.
 var (
 	planDB     string
 	treeDB     string
 	configPath string
+	checkGit   bool
 )
 
 // serverCmd represents the server command.
@@ -59,7 +66,8 @@ For mysql, say:
   mysql:user:password@tcp(host:port)/dbname
 
 It is recommended to use the environment variable "BACKUP_PLANS_CONNECTION" for this
-to maintain password security.
+to maintain password security. If neither is set, the plandb setting from the
+config file is used, which can refer to a secret file (see the server command).
 
 --tree should be generated using the db command.
 
@@ -99,26 +107,26 @@ manual backups, for the paths specified by the regexp, use different servers.
 
 If a manualservername is unspecified, it defaults to the specified servername.
 
+--check-git will also check the local git working copy in each directory with a
+manualgit rule against the remote named by the rule, reporting uncommitted
+changes, untracked files and unpushed commits. The results are stored in the
+plan database, so that they can be shown in the report. Credentials for the
+remotes can be given in the git section of the config file, as described for
+the server command.
+
 The key of the pathtoserver map is a regexp string that will be matched
 against path; a matching path will use the server details associated with the
 regexp.
 `,
-	PreRunE: func(cmd *cobra.Command, _ []string) error {
-		envMap := map[string]string{
-			"BACKUP_PLANS_CONNECTION": "plan",
-		}
-
-		return checkEnvVarFlags(cmd, envMap)
-	},
 	RunE: func(_ *cobra.Command, _ []string) error {
 		config, err := config.Parse(configPath)
 		if err != nil {
 			return fmt.Errorf("failed to process config file: %w", err)
 		}
 
-		planDB, err := db.Init(planDB)
+		planDB, err := openPlanDB(config)
 		if err != nil {
-			return fmt.Errorf("failed to open db: %w", err)
+			return err
 		}
 		defer planDB.Close()
 
@@ -137,10 +145,59 @@ regexp.
 			cliPrintf("ibackup set '%s' created for %s with %v files\n", setIn.BackupSetName, setIn.Requestor, setIn.FileCount)
 		}
 
+		if checkGit {
+			if gerr := checkGitWorkingCopies(planDB, treeNode, config.GetGitCredentials()); gerr != nil {
+				return errors.Join(err, gerr)
+			}
+		}
+
 		return err
 	},
 }
 
+// openPlanDB opens the plan database named by the --plan flag or the
+// BACKUP_PLANS_CONNECTION environment variable, falling back to the plandb
+// setting of the config.
+func openPlanDB(c *config.Config) (*db.DB, error) {
+	conn := planDB
+	if conn == "" {
+		conn = c.GetPlanDB()
+	}
+
+	if conn == "" {
+		return nil, ErrNoPlanDB
+	}
+
+	d, err := db.Init(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open db: %w", err)
+	}
+
+	return d, nil
+}
+
+func checkGitWorkingCopies(planDB *db.DB, treeNode *tree.MemTree, creds *git.Credentials) error {
+	checks, err := backups.CheckGitWorkingCopies(planDB, treeNode, creds)
+	if err != nil {
+		return fmt.Errorf("\n failed to check git working copies: %w", err)
+	}
+
+	for _, check := range checks {
+		if check.Error != "" {
+			cliPrintf("git repo '%s' for %s could not be checked: %s\n", check.Repo, check.Directory.Path, check.Error)
+		} else {
+			cliPrintf("git repo '%s' for %s has %d uncommitted changes, %d untracked files and %d unpushed commits\n",
+				check.Repo, check.Directory.Path, check.Uncommitted, check.Untracked, check.Unpushed)
+		}
+
+		if err := planDB.SetGitWorkingCopy(check.Directory, check.GitWorkingCopy); err != nil {
+			return fmt.Errorf("failed to store git working copy status: %w", err)
+		}
+	}
+
+	return nil
+}
+
 func init() {
 	RootCmd.AddCommand(backupCmd)
 
@@ -150,19 +207,9 @@ func init() {
 	backupCmd.Flags().StringVarP(&treeDB, "tree", "t", "",
 		"Path to tree db file, usually generated using db cmd")
 	backupCmd.Flags().StringVarP(&configPath, "config", "c", "", "ibackup config")
+	backupCmd.Flags().BoolVar(&checkGit, "check-git", false,
+		"check local git working copies for manualgit rules against their remotes")
 
 	backupCmd.MarkFlagRequired("tree")   //nolint:errcheck
 	backupCmd.MarkFlagRequired("config") //nolint:errcheck
 }
-
-func checkEnvVarFlags(cmd *cobra.Command, envMap map[string]string) error {
-	for env := range envMap {
-		if v, err := cmd.Flags().GetString(envMap[env]); err != nil {
-			return fmt.Errorf("failed to get flag %s: %w", envMap[env], err)
-		} else if v == "" {
-			return fmt.Errorf("--%s must be set when env variable '%s' is not", envMap[env], env) //nolint:err113
-		}
-	}
-
-	return nil
-}
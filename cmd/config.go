@@ -0,0 +1,119 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/wtsi-hgi/backup-plans/config"
+)
+
+var (
+	ErrInvalidConfig = errors.New("invalid config")
+
+	checkConnect bool
+)
+
+// configCmd represents the config command.
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Work with backup-plans config files.",
+	Long:  `Work with backup-plans config files.`,
+}
+
+// configCheckCmd represents the config check command.
+var configCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check a config file for problems.",
+	Long: `Check a config file for problems.
+
+The config file given with --config, in the format described for the server
+command, is parsed and checked without starting the server. Every problem found
+is listed, and the command exits non-zero if there are any. The following are
+checked:
+
+  - that every ibackup pathtoserver regexp compiles;
+  - that every transformer is known to ibackup;
+  - that every server named in pathtoserver exists;
+  - that the bomfile and ownersfile can be read and parsed;
+  - that every reportingroot is a valid glob ending in /;
+  - that every nfsmtimesources source is valid;
+  - that the identity and git credential files can be read.
+
+Unknown keys, which the server ignores, are listed as warnings, but do not cause
+a non-zero exit.
+
+--connect will also try to connect to each ibackup and wrstat server.
+`,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		var problems, warnings []error
+
+		for _, err := range config.Check(configPath, checkConnect) {
+			if errors.Is(err, config.ErrUnknownKey) {
+				warnings = append(warnings, err)
+			} else {
+				problems = append(problems, err)
+			}
+		}
+
+		printConfigErrors(configPath, "warnings", warnings)
+
+		if len(problems) == 0 {
+			cliPrintf("%s: OK\n", configPath)
+
+			return nil
+		}
+
+		printConfigErrors(configPath, "problems", problems)
+
+		return fmt.Errorf("%w: %s", ErrInvalidConfig, configPath)
+	},
+}
+
+func printConfigErrors(configPath, kind string, errs []error) {
+	if len(errs) == 0 {
+		return
+	}
+
+	cliPrintf("%s: %d %s found:\n", configPath, len(errs), kind)
+
+	for _, err := range errs {
+		cliPrintf("  - %s\n", err)
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configCheckCmd)
+
+	// flags specific to this sub-command
+	configCheckCmd.Flags().StringVarP(&configPath, "config", "c", "", "path to config file")
+	configCheckCmd.Flags().BoolVar(&checkConnect, "connect", false, "test connections to ibackup and wrstat servers")
+
+	configCheckCmd.MarkFlagRequired("config") //nolint:errcheck
+}
@@ -0,0 +1,560 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/wtsi-hgi/backup-plans/backend"
+	"github.com/wtsi-hgi/backup-plans/client"
+	"github.com/wtsi-hgi/backup-plans/config"
+	"github.com/wtsi-hgi/backup-plans/ruletree"
+	"github.com/wtsi-hgi/backup-plans/server"
+)
+
+var (
+	ErrNoIdentity  = errors.New("--as must be given when using --config")
+	ErrNoPlanTrees = errors.New("--tree must be given when using --config")
+)
+
+// options for this cmd.
+var (
+	planTrees     []string
+	planUser      string
+	planJSON      bool
+	planMetadata  string
+	planOverride  bool
+	planFrequency uint
+	planFrozen    bool
+	planMelt      bool
+	planReview    string
+	planRemove    string
+)
+
+// planCmd represents the plan command.
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "View and change the backup plans of directories.",
+	Long: `View and change the backup plans of directories.
+
+The sub-commands work either against a running backup-plans server, or directly
+with a plan database.
+
+To use a server, --server gives its URL, defaulting to the BACKUP_PLANS_SERVER
+environment variable, and --header adds a header, given as "Name: value", to each
+request, and can be used to authenticate, for example:
+
+  --header "Authorization: Bearer $TOKEN"
+
+To work directly with a plan database, for admin scripting, give --config, as
+for the server command, along with --tree for each tree db to load and --as for
+the user to act as. --plan overrides the plan database connection string from
+the config file. Changes made this way will not be seen by a server using the
+same plan database until it is restarted.
+
+Either way, the same checks are made as when using the web interface.
+
+--json prints the resulting plan as JSON, for scripting, instead of as text.
+`,
+}
+
+// planLsCmd represents the plan ls command.
+var planLsCmd = &cobra.Command{
+	Use:   "ls dir",
+	Short: "Show the plan of a directory and its children.",
+	Long: `Show the plan of a directory and its children.
+
+Prints the claimant, backup settings and rules of the given directory, followed
+by each of its child directories, with their claimant and the number and size
+of the files they contain.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: withPlanClient(func(c *client.Client, args []string) error {
+		return printPlan(c, args[0], true)
+	}),
+}
+
+// planClaimCmd represents the plan claim command.
+var planClaimCmd = &cobra.Command{
+	Use:   "claim dir",
+	Short: "Claim a directory.",
+	Long: `Claim a directory.
+
+Claims the given directory for the user, who must own it, and prints its plan.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: withPlanClient(func(c *client.Client, args []string) error {
+		if _, err := c.ClaimDir(args[0]); err != nil {
+			return err
+		}
+
+		return printPlan(c, args[0], false)
+	}),
+}
+
+// planPassCmd represents the plan pass command.
+var planPassCmd = &cobra.Command{
+	Use:   "pass dir user",
+	Short: "Pass the claim of a directory to another user.",
+	Long: `Pass the claim of a directory to another user.
+
+Passes the claim of the given directory, which must be claimed by the user, to
+the given user, and prints its plan.
+`,
+	Args: cobra.ExactArgs(2), //nolint:mnd
+	RunE: withPlanClient(func(c *client.Client, args []string) error {
+		if err := c.PassDirClaim(args[0], args[1]); err != nil {
+			return err
+		}
+
+		return printPlan(c, args[0], false)
+	}),
+}
+
+// planRevokeCmd represents the plan revoke command.
+var planRevokeCmd = &cobra.Command{
+	Use:   "revoke dir",
+	Short: "Revoke the claim of a directory.",
+	Long: `Revoke the claim of a directory.
+
+Revokes the claim of the given directory, removing all of its rules, and prints
+its plan.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: withPlanClient(func(c *client.Client, args []string) error {
+		if err := c.RevokeDirClaim(args[0]); err != nil {
+			return err
+		}
+
+		return printPlan(c, args[0], false)
+	}),
+}
+
+// planRuleCmd represents the plan rule command.
+var planRuleCmd = &cobra.Command{
+	Use:   "rule",
+	Short: "Change the rules of a claimed directory.",
+	Long:  `Change the rules of a claimed directory.`,
+}
+
+// planRuleAddCmd represents the plan rule add command.
+var planRuleAddCmd = &cobra.Command{
+	Use:   "add dir action [match...]",
+	Short: "Add rules to a claimed directory.",
+	Long: `Add rules to a claimed directory.
+
+Adds a rule, with the given action, for each of the given matches, or for *
+when none are given, to the given directory, and prints its plan.
+
+The action is one of nobackup, backup, manualibackup, manualgit,
+manualunchecked, manualprefect or manualnfs. For the manual actions, --metadata
+gives the set name, repo or other location of the backup. --override makes the
+rules override those of child directories.
+`,
+	Args: cobra.MinimumNArgs(2), //nolint:mnd
+	RunE: withPlanClient(func(c *client.Client, args []string) error {
+		if err := c.CreateRule(args[0], planRule(args)); err != nil {
+			return err
+		}
+
+		return printPlan(c, args[0], false)
+	}),
+}
+
+// planRuleUpdateCmd represents the plan rule update command.
+var planRuleUpdateCmd = &cobra.Command{
+	Use:   "update dir action [match...]",
+	Short: "Change rules of a claimed directory.",
+	Long: `Change rules of a claimed directory.
+
+Changes the existing rules, for each of the given matches, or for * when none
+are given, of the given directory to the given action, and prints its plan.
+
+The action and --metadata are as for the add sub-command. Whether a rule
+overrides those of child directories can't be changed; remove the rule and add
+it again instead.
+`,
+	Args: cobra.MinimumNArgs(2), //nolint:mnd
+	RunE: withPlanClient(func(c *client.Client, args []string) error {
+		if err := c.UpdateRule(args[0], planRule(args)); err != nil {
+			return err
+		}
+
+		return printPlan(c, args[0], false)
+	}),
+}
+
+// planRuleRmCmd represents the plan rule rm command.
+var planRuleRmCmd = &cobra.Command{
+	Use:   "rm dir match",
+	Short: "Remove a rule from a claimed directory.",
+	Long: `Remove a rule from a claimed directory.
+
+Removes the rule with the given match from the given directory, and prints its
+plan.
+`,
+	Args: cobra.ExactArgs(2), //nolint:mnd
+	RunE: withPlanClient(func(c *client.Client, args []string) error {
+		if err := c.RemoveRule(args[0], args[1]); err != nil {
+			return err
+		}
+
+		return printPlan(c, args[0], false)
+	}),
+}
+
+// planSetDetailsCmd represents the plan set-details command.
+var planSetDetailsCmd = &cobra.Command{
+	Use:   "set-details dir",
+	Short: "Change the backup settings of a claimed directory.",
+	Long: `Change the backup settings of a claimed directory.
+
+Changes the given settings of the given directory, keeping the current value of
+any that are not given, and prints its plan:
+
+  --frequency: the number of days between backups;
+  --frozen:    only upload each backed up file once;
+  --melt:      for a frozen directory, upload changed files once more;
+  --review:    the date, as YYYY-MM-DD, at which the plan should be reviewed;
+  --remove:    the date, as YYYY-MM-DD, at which the backups will be removed.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: withPlanClient(func(c *client.Client, args []string) error {
+		details, err := planDetails(c, args[0])
+		if err != nil {
+			return err
+		}
+
+		if err = c.SetDirDetails(args[0], details); err != nil {
+			return err
+		}
+
+		return printPlan(c, args[0], false)
+	}),
+}
+
+// withPlanClient returns a cobra RunE function that calls the given function
+// with a client for the server, or plan database, given by the flags.
+func withPlanClient(fn func(*client.Client, []string) error) func(*cobra.Command, []string) error {
+	return func(_ *cobra.Command, args []string) error {
+		c, done, err := planClient()
+		if err != nil {
+			return err
+		}
+
+		defer done()
+
+		return fn(c, args)
+	}
+}
+
+// planClient returns a client for the server given by --server, or, when
+// --config is given, for a backend using the plan database directly, acting as
+// the user given by --as.
+func planClient() (*client.Client, func(), error) {
+	if configPath == "" {
+		c, err := newClient()
+
+		return c, func() {}, err
+	}
+
+	if planUser == "" {
+		return nil, nil, ErrNoIdentity
+	}
+
+	if len(planTrees) == 0 {
+		return nil, nil, ErrNoPlanTrees
+	}
+
+	cfg, err := config.Parse(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to process config file: %w", err)
+	}
+
+	d, err := openPlanDB(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	b, err := backend.New(d, func(*http.Request) string { return planUser }, cfg)
+	if err != nil {
+		d.Close()
+
+		return nil, nil, err
+	}
+
+	done := func() {
+		b.Stop()
+		d.Close()
+	}
+
+	for _, tree := range planTrees {
+		if err = b.AddTree(tree); err != nil {
+			done()
+
+			return nil, nil, fmt.Errorf("failed to load tree db: %w", err)
+		}
+	}
+
+	return client.NewHandler(server.Handler(b)), done, nil
+}
+
+func planRule(args []string) backend.BulkRule {
+	return backend.BulkRule{
+		Action:   args[1],
+		Metadata: planMetadata,
+		Match:    args[2:],
+		Override: planOverride,
+	}
+}
+
+// planDetails returns the current backup settings of the given directory,
+// changed by any of the set-details flags that were given.
+func planDetails(c *client.Client, dir string) (client.DirDetails, error) {
+	t, err := c.Tree(dir)
+	if err != nil {
+		return client.DirDetails{}, err
+	}
+
+	details := client.DirDetails{
+		Frequency: t.Frequency,
+		Frozen:    t.Frozen,
+		Review:    time.Unix(t.ReviewDate, 0),
+		Remove:    time.Unix(t.RemoveDate, 0),
+	}
+
+	flags := planSetDetailsCmd.Flags()
+
+	if flags.Changed("frequency") {
+		details.Frequency = planFrequency
+	}
+
+	if flags.Changed("frozen") {
+		details.Frozen = planFrozen
+	}
+
+	details.ToggleMelt = planMelt
+
+	if flags.Changed("review") {
+		if details.Review, err = time.ParseInLocation(time.DateOnly, planReview, time.Local); err != nil {
+			return client.DirDetails{}, err
+		}
+	}
+
+	if flags.Changed("remove") {
+		if details.Remove, err = time.ParseInLocation(time.DateOnly, planRemove, time.Local); err != nil {
+			return client.DirDetails{}, err
+		}
+	}
+
+	return details, nil
+}
+
+// planDir is the plan of a directory, as printed by the plan sub-commands.
+type planDir struct {
+	Path       string
+	ClaimedBy  string         `json:",omitempty"`
+	Frequency  uint           `json:",omitempty"`
+	Frozen     bool           `json:",omitempty"`
+	ReviewDate int64          `json:",omitempty"`
+	RemoveDate int64          `json:",omitempty"`
+	Rules      []planRuleInfo `json:",omitempty"`
+	Children   []planChild    `json:",omitempty"`
+}
+
+type planRuleInfo struct {
+	Match    string
+	Action   string
+	Metadata string `json:",omitempty"`
+	Override bool   `json:",omitempty"`
+}
+
+type planChild struct {
+	Path      string
+	ClaimedBy string `json:",omitempty"`
+	Files     uint64
+	Size      uint64
+}
+
+func newPlanDir(dir string, t *backend.TreeDB, children bool) *planDir {
+	p := &planDir{
+		Path:      dir,
+		ClaimedBy: t.ClaimedBy,
+	}
+
+	if t.ClaimedBy != "" {
+		p.Frequency = t.Frequency
+		p.Frozen = t.Frozen
+		p.ReviewDate = t.ReviewDate
+		p.RemoveDate = t.RemoveDate
+	}
+
+	for _, rule := range t.Rules[dir] {
+		p.Rules = append(p.Rules, planRuleInfo{
+			Match:    rule.Match,
+			Action:   rule.BackupType.Name(),
+			Metadata: rule.Metadata,
+			Override: rule.Override,
+		})
+	}
+
+	slices.SortFunc(p.Rules, func(a, b planRuleInfo) int { return strings.Compare(a.Match, b.Match) })
+
+	if !children || t.DirSummary == nil {
+		return p
+	}
+
+	for name, child := range t.Children {
+		files, size := dirTotals(child)
+
+		p.Children = append(p.Children, planChild{
+			Path:      dir + name,
+			ClaimedBy: child.ClaimedBy,
+			Files:     files,
+			Size:      size,
+		})
+	}
+
+	slices.SortFunc(p.Children, func(a, b planChild) int { return strings.Compare(a.Path, b.Path) })
+
+	return p
+}
+
+func dirTotals(d *ruletree.DirSummary) (uint64, uint64) {
+	var files, size uint64
+
+	for _, rs := range d.RuleSummaries {
+		for _, u := range rs.Users {
+			files += u.Files
+			size += u.Size
+		}
+	}
+
+	return files, size
+}
+
+// printPlan prints the plan of the given directory, as text or, with --json,
+// as JSON, including its children if requested.
+func printPlan(c *client.Client, dir string, children bool) error {
+	t, err := c.Tree(dir)
+	if err != nil {
+		return err
+	}
+
+	p := newPlanDir(dir, t, children)
+
+	if planJSON {
+		return json.NewEncoder(os.Stdout).Encode(p)
+	}
+
+	printPlanDir(p)
+
+	return nil
+}
+
+func printPlanDir(p *planDir) {
+	if p.ClaimedBy == "" {
+		cliPrintf("%s: unclaimed\n", p.Path)
+	} else {
+		cliPrintf("%s: claimed by %s\n", p.Path, p.ClaimedBy)
+		cliPrintf("\tfrequency: %d days, frozen: %t\n", p.Frequency, p.Frozen)
+		cliPrintf("\treview: %s, remove: %s\n", formatPlanDate(p.ReviewDate), formatPlanDate(p.RemoveDate))
+	}
+
+	for _, rule := range p.Rules {
+		cliPrintf("\trule: %s: %s", rule.Match, rule.Action)
+
+		if rule.Metadata != "" {
+			cliPrintf(" (%s)", rule.Metadata)
+		}
+
+		if rule.Override {
+			cliPrintf(" [override]")
+		}
+
+		cliPrintf("\n")
+	}
+
+	for _, child := range p.Children {
+		cliPrintf("%s: %d files, %d bytes", child.Path, child.Files, child.Size)
+
+		if child.ClaimedBy != "" {
+			cliPrintf(", claimed by %s", child.ClaimedBy)
+		}
+
+		cliPrintf("\n")
+	}
+}
+
+func formatPlanDate(date int64) string {
+	if date == 0 {
+		return "not set"
+	}
+
+	return time.Unix(date, 0).Format(time.DateOnly)
+}
+
+func init() {
+	RootCmd.AddCommand(planCmd)
+	planCmd.AddCommand(planLsCmd, planClaimCmd, planPassCmd, planRevokeCmd, planRuleCmd, planSetDetailsCmd)
+	planRuleCmd.AddCommand(planRuleAddCmd, planRuleUpdateCmd, planRuleRmCmd)
+
+	// flags for all plan sub-commands
+	planCmd.PersistentFlags().StringVarP(&serverURL, "server", "s", os.Getenv("BACKUP_PLANS_SERVER"),
+		"URL of the backup-plans server")
+	planCmd.PersistentFlags().StringArrayVarP(&serverHeaders, "header", "H", nil,
+		"header to send with each request, as 'Name: value'")
+	planCmd.PersistentFlags().StringVarP(&configPath, "config", "c", "",
+		"config file, to use the plan database directly instead of a server")
+	planCmd.PersistentFlags().StringVarP(&planDB, "plan", "p", os.Getenv("BACKUP_PLANS_CONNECTION"),
+		"sql connection string for your plan database")
+	planCmd.PersistentFlags().StringArrayVarP(&planTrees, "tree", "t", nil,
+		"path to a tree db file, when using the plan database directly")
+	planCmd.PersistentFlags().StringVar(&planUser, "as", "", "user to act as, when using the plan database directly")
+	planCmd.PersistentFlags().BoolVar(&planJSON, "json", false, "print the plan as JSON")
+
+	// flags specific to the rule sub-commands
+	for _, cmd := range [...]*cobra.Command{planRuleAddCmd, planRuleUpdateCmd} {
+		cmd.Flags().StringVar(&planMetadata, "metadata", "", "metadata for manual rules")
+	}
+
+	planRuleAddCmd.Flags().BoolVar(&planOverride, "override", false, "rules override child rules")
+
+	// flags specific to the set-details sub-command
+	planSetDetailsCmd.Flags().UintVar(&planFrequency, "frequency", 0, "number of days between backups")
+	planSetDetailsCmd.Flags().BoolVar(&planFrozen, "frozen", false, "only upload each file once")
+	planSetDetailsCmd.Flags().BoolVar(&planMelt, "melt", false, "upload changed files of a frozen directory once more")
+	planSetDetailsCmd.Flags().StringVar(&planReview, "review", "", "review date, as YYYY-MM-DD")
+	planSetDetailsCmd.Flags().StringVar(&planRemove, "remove", "", "removal date, as YYYY-MM-DD")
+}
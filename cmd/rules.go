@@ -0,0 +1,278 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wtsi-hgi/backup-plans/backend"
+	"github.com/wtsi-hgi/backup-plans/client"
+)
+
+var (
+	ErrInvalidRuleFlag = errors.New("rule must be given as action=match")
+	ErrInvalidHeader   = errors.New("header must be given as name: value")
+	ErrNoServer        = errors.New("--server must be set when env variable 'BACKUP_PLANS_SERVER' is not")
+)
+
+// options for this cmd.
+var (
+	serverURL     string
+	serverHeaders []string
+	bulkFilter    backend.BulkRules
+	bulkCreate    []string
+	bulkUpdate    []string
+	bulkMetadata  string
+	bulkOverride  bool
+	bulkApply     bool
+)
+
+// rulesCmd represents the rules command.
+var rulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Work with the rules of a running backup-plans server.",
+	Long:  `Work with the rules of a running backup-plans server.`,
+}
+
+// rulesBulkCmd represents the rules bulk command.
+var rulesBulkCmd = &cobra.Command{
+	Use:   "bulk",
+	Short: "Change the rules of many claimed directories at once.",
+	Long: `Change the rules of many claimed directories at once.
+
+The given rule changes are made to every claimed directory matching all of the
+given filters, of which there must be at least one:
+
+  --glob:     a glob matched against the directory path, where * only matches
+              within a single path part (eg. /lustre/*/humgen/projects/*/);
+  --group:    the group that owns the directory;
+  --bom:      the BOM of the group that owns the directory;
+  --claimant: the user that claimed the directory.
+
+--create adds a rule, given as action=match (eg. nobackup=*.tmp), to each
+directory that does not already have a rule for that match. --update changes the
+action of the existing rule for the match. --remove removes the rule for the
+given match. Each may be given multiple times. --metadata is set on any manual
+rules, and --override on any created rules.
+
+Only directories claimed by you are changed, unless you are an admin.
+
+By default, the directories that would be changed are listed, but no changes
+are made; --apply will make the changes, all in a single transaction.
+
+--server is the URL of the backup-plans server, and defaults to the
+BACKUP_PLANS_SERVER environment variable. --header adds a header, given as
+"Name: value", to each request, and can be used to authenticate, for example:
+
+  --header "Authorization: Bearer $TOKEN"
+`,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		var err error
+
+		bulkFilter.Preview = !bulkApply
+
+		if bulkFilter.Create, err = parseRuleFlags(bulkCreate); err != nil {
+			return err
+		}
+
+		if bulkFilter.Update, err = parseRuleFlags(bulkUpdate); err != nil {
+			return err
+		}
+
+		c, err := newClient()
+		if err != nil {
+			return err
+		}
+
+		dirs, err := c.BulkRules(&bulkFilter)
+		if err != nil {
+			return err
+		}
+
+		printBulkDirectories(dirs)
+
+		return nil
+	},
+}
+
+// rulesExplainCmd represents the rules explain command.
+var rulesExplainCmd = &cobra.Command{
+	Use:   "explain path",
+	Short: "Explain which rule applies to a file.",
+	Long: `Explain which rule applies to a file.
+
+Prints the rule that applies to the given file path, the directory it is defined
+on, and the resulting backup type and ibackup set name.
+
+Every other rule that matches the path is then listed, nearest directory first,
+along with the reason it did not apply:
+
+  precedence:             a more specific match on the same directory applied;
+  nearer directory:       a rule on a directory closer to the file applied;
+  override from a parent: an override rule on a parent directory applied;
+  not applied:            no rule applied to the file.
+
+--server and --header are as for the bulk sub-command.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		c, err := newClient()
+		if err != nil {
+			return err
+		}
+
+		e, err := c.Explain(args[0])
+		if err != nil {
+			return err
+		}
+
+		printExplanation(e)
+
+		return nil
+	},
+}
+
+func printExplanation(e *backend.Explanation) {
+	cliPrintf("%s: %s\n", e.Path, e.BackupType)
+
+	if e.SetName != "" {
+		cliPrintf("set: %s\n", e.SetName)
+	}
+
+	if e.Explanation == nil || e.Rule == nil {
+		cliPrintf("no rule applies\n")
+
+		return
+	}
+
+	cliPrintf("rule: %s%s", e.Rule.Directory, e.Rule.Rule.Match)
+
+	if e.Rule.AppliedAt != e.Rule.Directory {
+		cliPrintf(" (applied at %s)", e.Rule.AppliedAt)
+	}
+
+	cliPrintf("\n")
+
+	for _, c := range e.Candidates {
+		cliPrintf("\tbeat %s%s: %s\n", c.Directory, c.Rule.Match, c.Reason)
+	}
+}
+
+func parseRuleFlags(flags []string) ([]backend.BulkRule, error) {
+	rules := make([]backend.BulkRule, len(flags))
+
+	for n, flag := range flags {
+		action, match, ok := strings.Cut(flag, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidRuleFlag, flag)
+		}
+
+		rules[n] = backend.BulkRule{
+			Action:   action,
+			Metadata: bulkMetadata,
+			Match:    []string{match},
+			Override: bulkOverride,
+		}
+	}
+
+	return rules, nil
+}
+
+func printBulkDirectories(dirs []backend.BulkDirectory) {
+	verb := "would change"
+	if bulkApply {
+		verb = "changed"
+	}
+
+	for _, dir := range dirs {
+		cliPrintf("%s (%s):\n", dir.Path, dir.ClaimedBy)
+
+		for _, change := range [...]struct {
+			name    string
+			matches []string
+		}{
+			{"create", dir.Create},
+			{"update", dir.Update},
+			{"remove", dir.Remove},
+		} {
+			for _, match := range change.matches {
+				cliPrintf("\t%s: %s\n", change.name, match)
+			}
+		}
+	}
+
+	cliPrintf("%s %d directories\n", verb, len(dirs))
+}
+
+// newClient returns a client for the server given by --server, sending the
+// headers given by --header with each request.
+func newClient() (*client.Client, error) {
+	if serverURL == "" {
+		return nil, ErrNoServer
+	}
+
+	header := make(http.Header)
+
+	for _, h := range serverHeaders {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidHeader, h)
+		}
+
+		header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	return client.New(serverURL, header)
+}
+
+func init() {
+	RootCmd.AddCommand(rulesCmd)
+	rulesCmd.AddCommand(rulesBulkCmd)
+	rulesCmd.AddCommand(rulesExplainCmd)
+
+	// flags for all rules sub-commands
+	rulesCmd.PersistentFlags().StringVarP(&serverURL, "server", "s", os.Getenv("BACKUP_PLANS_SERVER"),
+		"URL of the backup-plans server")
+	rulesCmd.PersistentFlags().StringArrayVarP(&serverHeaders, "header", "H", nil,
+		"header to send with each request, as 'Name: value'")
+
+	// flags specific to this sub-command
+	rulesBulkCmd.Flags().StringVar(&bulkFilter.Glob, "glob", "", "only change directories matching this glob")
+	rulesBulkCmd.Flags().StringVar(&bulkFilter.Group, "group", "", "only change directories owned by this group")
+	rulesBulkCmd.Flags().StringVar(&bulkFilter.BOM, "bom", "", "only change directories owned by groups in this BOM")
+	rulesBulkCmd.Flags().StringVar(&bulkFilter.Claimant, "claimant", "", "only change directories claimed by this user")
+	rulesBulkCmd.Flags().StringArrayVar(&bulkCreate, "create", nil, "rule to create, as action=match")
+	rulesBulkCmd.Flags().StringArrayVar(&bulkUpdate, "update", nil, "rule to update, as action=match")
+	rulesBulkCmd.Flags().StringArrayVar(&bulkFilter.Remove, "remove", nil, "match of rule to remove")
+	rulesBulkCmd.Flags().StringVar(&bulkMetadata, "metadata", "", "metadata for manual rules")
+	rulesBulkCmd.Flags().BoolVar(&bulkOverride, "override", false, "created rules override child rules")
+	rulesBulkCmd.Flags().BoolVar(&bulkApply, "apply", false, "make the changes, instead of listing them")
+}
@@ -27,23 +27,23 @@
 package cmd
 
 import (
-	"encoding/base64"
 	"fmt"
 	"net/http"
 	"os"
-	"strings"
-	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/wtsi-hgi/backup-plans/auth"
 	"github.com/wtsi-hgi/backup-plans/config"
-	"github.com/wtsi-hgi/backup-plans/db"
 	"github.com/wtsi-hgi/backup-plans/server"
 )
 
 const defaultPort = 8080
 
 // options for this cmd.
-var serverPort uint16
+var (
+	serverPort    uint16
+	serverOptions server.Options
+)
 
 // serverCmd represents the server command.
 var serverCmd = &cobra.Command{
@@ -60,11 +60,20 @@ For mysql, say:
   mysql:user:password@tcp(host:port)/dbname
 
 It is recommended to use the environment variable "BACKUP_PLANS_CONNECTION" for this
-to maintain password security.
+to maintain password security. If neither is set, the plandb setting from the
+config file is used.
 
 --tree should be generated using the db command.
 --listen server port to listen on
 
+If --cert and --key are given, the server will use TLS, reloading the
+certificate when the files change. The --*-timeout flags set the timeouts of the
+server.
+
+On receiving a SIGTERM (or SIGINT), the server stops accepting connections,
+waits up to --shutdown-timeout for open connections to finish, then stops its
+background caches and exits.
+
 --config should be the location of a Yaml config file, which should have the
 following structure:
 
@@ -106,6 +115,47 @@ reportingroots:
 mainprogrammes:
  - Programme
  - Other Main Programme
+git:
+    httptokens:
+        gitlab.example.com:
+            username: oauth2
+            tokenfile: /path/to/gitlab/token
+    sshkey: /path/to/ssh/private/key
+    knownhosts: /path/to/known_hosts
+nfsmtimesources:
+    /nfs/fast/:
+        source: stat
+        maxdepth: 5
+        budget: 10
+    /nfs/mirror/:
+        source: tree
+identity:
+    source: files
+    passwd: /etc/passwd
+    group: /etc/group
+    positivettl: 3600
+    negativettl: 60
+policies:
+    /lustre/scratch/:
+        forbidden:
+         - backup
+    /lustre/projects/:
+        frequency: 1
+        minreview: 30
+        maxreview: 365
+        maxremoval: 90
+        nooverrides: true
+auth:
+    method: jwt
+    jwks: /path/to/jwks.json
+    issuer: https://login.example.com
+    audience: backup-plans
+    claim: email
+    stripdomain: true
+    cookie: id_token
+    logouturl: https://login.example.com/logout
+notifyurl: https://notify.example.com/backup-plans
+reporthistorydays: 365
 
 The key of the Servers map is the server name, as used in the PathToServer
 map.
@@ -133,33 +183,106 @@ BOM:
 
 
 The AdminGroup is used to specify an admin group id to allow users of that group
-visibility permissions within the DiskTree.
-
-If the ReloadTime setting is non-zero, the config will be reloaded after
-waiting that many seconds. Reloading the config will rebuild all structures,
-while keeping any caches intact.
+visibility permissions within the DiskTree. Members of the admin group can also
+reassign, unfreeze and revoke the claim on any directory, and remove the rules
+of any directory, using the /api/admin/ endpoints. Each admin action is recorded
+in the audit log, which can be seen at /api/admin/audit. If notifyurl is set,
+an admin action with notify=true will POST the JSON audit entry, which includes
+the original claimant, to that URL.
+
+The config will be reloaded when the server receives a SIGHUP, when the config
+file is modified, and, if the ReloadTime setting is non-zero, every ReloadTime
+seconds. Reloading the config will rebuild all structures, while keeping any
+caches intact. A new config is only used if it loads without error; otherwise
+the previous config remains in use. The generation and load time of the current
+config, and any error from the last reload, can be seen at /api/config/status.
 
 The ReportingRoots is a list of paths that will appear on the Top Level Report.
-`,
-	PreRunE: func(cmd *cobra.Command, _ []string) error {
-		envMap := map[string]string{
-			"BACKUP_PLANS_CONNECTION": "plan",
-		}
+A snapshot of the report totals is stored each time trees are loaded, and can be
+seen at /api/report/history; snapshots older than reporthistorydays days are
+removed, or kept forever if it is unset.
+
+The git settings are used to retrieve the status of manualgit repos. The key of
+the httptokens map is the host name of a remote; the token, read from the
+tokenfile, is sent with the username (which defaults to oauth2) using HTTP basic
+auth. The sshkey is used for ssh:// and git@host:path remotes, with host keys
+checked against knownhosts, or the default known_hosts files if unset.
+
+The nfsmtimesources map chooses how the last modification time of a manualnfs
+target is found, using the longest matching path prefix. A source of wrstat
+queries the wrstat server, tree uses the loaded tree databases, and stat walks
+the target directly, to at most maxdepth levels (unlimited if 0) and for at most
+budget seconds (default 10). Targets matching no prefix use wrstat if a wrstat
+server is configured, and tree otherwise.
+
+The policies map restricts the plans that can be made for directories, using
+the policy with the longest matching path prefix. The frequency (in days) is the
+default for newly claimed directories, replacing the default of 7. The minreview
+and maxreview settings bound the number of days from now until the review date,
+and minremoval and maxremoval the number of days from the review date until the
+removal date, with a maximum of 0 meaning no limit; the default review and
+removal dates of new claims are moved within these bounds. If allowed is given,
+rules may only use the listed backup types (nobackup, backup, manualibackup,
+manualgit, manualprefect, manualnfs or manualunchecked), and rules may never use
+a backup type listed in forbidden. If nooverrides is true, rules may not
+override the rules of child directories.
+
+The auth settings choose how users are authenticated, and are only read at
+startup. A method of cookie (the default) reads the username from the base64
+encoded user:password nginxauth cookie (or the cookie named by cookie) set by an
+authenticating nginx proxy. A method of jwt accepts a JWT, such as an OIDC ID
+token, sent as a Bearer token in the Authorization header, or in the named
+cookie; it must be signed by a key in the jwks file (which is reloaded when it
+changes), must not have expired, and must match the issuer and audience if
+given. The username is taken from the named claim (default sub). A method of
+header reads the username from the named header (default X-Remote-User), but
+only for requests from the IPs or CIDR ranges listed in trustedproxies, which is
+required. For the jwt and header methods, stripdomain removes anything from an @
+in the username, which must then be a user known to the system. On logout, users
+are redirected to logouturl, or / if unset.
+
+Any string value in the config file (but not a map key) may contain ${NAME}
+references, which are replaced by the value of the environment variable NAME,
+which must be set. A value that then starts with file: is replaced by the
+contents of the named file, with surrounding whitespace removed. For example:
+
+plandb: file:${CREDENTIALS_DIRECTORY}/plandb
+wrstat:
+    username: ${WRSTAT_USER}
 
-		return checkEnvVarFlags(cmd, envMap)
-	},
+The identity settings choose how user and group names are resolved. A source of
+os (the default) uses the system's NSS lookups; files reads the passwd and group
+files (defaulting to /etc/passwd and /etc/group); and json reads a mapping file,
+given as json, of the form:
+
+	{
+		"users": [{"name": "user", "uid": 1000, "gids": [1000, 2000]}],
+		"groups": [{"name": "group", "gid": 2000}]
+	}
+
+Files are reloaded when they change, and all of their names are loaded at
+startup. Found and missing names are cached for positivettl and negativettl
+seconds respectively, defaulting to 3600 and 60.
+`,
 	RunE: func(_ *cobra.Command, args []string) error {
 		config, err := config.Parse(configPath)
 		if err != nil {
 			return fmt.Errorf("failed to process config file: %w", err)
 		}
 
-		d, err := db.Init(planDB)
+		a, err := auth.New(config.GetAuth())
+		if err != nil {
+			return fmt.Errorf("failed to configure authentication: %w", err)
+		}
+
+		d, err := openPlanDB(config)
 		if err != nil {
 			return err
 		}
 
-		return server.Start(fmt.Sprintf(":%d", serverPort), d, getUser, http.HandlerFunc(logout), config, args...)
+		serverOptions.Addr = fmt.Sprintf(":%d", serverPort)
+
+		return server.Start(serverOptions, d, a.User, http.HandlerFunc(a.Logout), config, args...)
 	},
 }
 
@@ -172,35 +295,19 @@ func init() {
 	serverCmd.Flags().StringVarP(&planDB, "plan", "p", os.Getenv("BACKUP_PLANS_CONNECTION"),
 		"sql connection string for your plan database")
 	serverCmd.Flags().StringVarP(&configPath, "config", "c", "", "ibackup config")
+	serverCmd.Flags().StringVar(&serverOptions.CertFile, "cert", "", "path to TLS certificate file")
+	serverCmd.Flags().StringVar(&serverOptions.KeyFile, "key", "", "path to TLS key file")
+	serverCmd.Flags().DurationVar(&serverOptions.ReadHeaderTimeout, "read-header-timeout", 0,
+		"time allowed to read request headers (default 10s)")
+	serverCmd.Flags().DurationVar(&serverOptions.ReadTimeout, "read-timeout", 0,
+		"time allowed to read a request (default 1m)")
+	serverCmd.Flags().DurationVar(&serverOptions.WriteTimeout, "write-timeout", 0,
+		"time allowed to write a response (default 5m)")
+	serverCmd.Flags().DurationVar(&serverOptions.IdleTimeout, "idle-timeout", 0,
+		"time to keep idle connections open (default 2m)")
+	serverCmd.Flags().DurationVar(&serverOptions.ShutdownTimeout, "shutdown-timeout", 0,
+		"time to wait for connections to finish when shutting down (default 30s)")
 
 	serverCmd.MarkFlagRequired("tree")   //nolint:errcheck
 	serverCmd.MarkFlagRequired("config") //nolint:errcheck
 }
-
-func getUser(r *http.Request) string {
-	for _, cookie := range r.Cookies() {
-		if cookie.Name == "nginxauth" {
-			data, err := base64.StdEncoding.DecodeString(cookie.Value)
-			if err != nil {
-				return ""
-			}
-
-			user, _, _ := strings.Cut(string(data), ":")
-
-			return user
-		}
-	}
-
-	return ""
-}
-
-func logout(w http.ResponseWriter, r *http.Request) {
-	http.SetCookie(w, &http.Cookie{
-		Name:     "nginxauth",
-		Path:     "/",
-		Expires:  time.Unix(0, 0),
-		HttpOnly: true,
-	})
-
-	http.Redirect(w, r, "/", http.StatusFound)
-}
@@ -0,0 +1,136 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/wtsi-hgi/backup-plans/backups"
+	"github.com/wtsi-hgi/backup-plans/config"
+	"github.com/wtsi-hgi/backup-plans/internal/memtree"
+)
+
+var verbose bool
+
+// verifyCmd represents the verify command.
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check manual iBackup sets contain the files matched by their rules.",
+	Long: `Check manual iBackup sets contain the files matched by their rules.
+
+For every manualibackup rule in the plan database, the files in the tree that
+are matched by the rule are compared against the files in the named ibackup
+set. For each set, the following are reported:
+
+  missing: matched files that are not in the set, or have not been uploaded;
+  stale:   matched files that have been modified since they were uploaded;
+  extra:   files in the set, under the claimed directory, that are not matched.
+
+The counts are stored in the plan database so that the server can display the
+coverage of each manual set alongside its backup status.
+
+--plan, --tree and --config are as described for the backup command.
+
+--verbose will list each missing, stale and extra file, instead of just the
+counts.
+`,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		config, err := config.Parse(configPath)
+		if err != nil {
+			return fmt.Errorf("failed to process config file: %w", err)
+		}
+
+		planDB, err := openPlanDB(config)
+		if err != nil {
+			return err
+		}
+		defer planDB.Close()
+
+		treeNode, dfn, err := memtree.Open(treeDB)
+		if err != nil {
+			return fmt.Errorf("\n failed to open tree db: %w", err)
+		}
+		defer dfn()
+
+		coverage, err := backups.VerifyManualSets(planDB, treeNode, config.GetIBackupClient())
+		if err != nil {
+			err = fmt.Errorf("\n failed to verify some sets: %w", err)
+		}
+
+		now := time.Now()
+
+		for _, sc := range coverage {
+			printCoverage(sc)
+
+			if serr := planDB.SetSetCoverage(sc.Directory, sc.DBCoverage(now)); serr != nil {
+				return fmt.Errorf("failed to store set coverage: %w", serr)
+			}
+		}
+
+		return err
+	},
+}
+
+func printCoverage(sc *backups.SetCoverage) {
+	cliPrintf("ibackup set '%s' for %s: %d/%d files covered (%.1f%%), %d missing, %d stale, %d extra\n",
+		sc.SetName, sc.Directory.Path, sc.Covered(), sc.Matched, sc.Percent(),
+		len(sc.Missing), len(sc.Stale), len(sc.Extra))
+
+	if !verbose {
+		return
+	}
+
+	for _, files := range [...]struct {
+		name  string
+		paths []string
+	}{
+		{"missing", sc.Missing},
+		{"stale", sc.Stale},
+		{"extra", sc.Extra},
+	} {
+		for _, path := range files.paths {
+			cliPrintf("\t%s: %s\n", files.name, path)
+		}
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(verifyCmd)
+
+	// flags specific to this sub-command
+	verifyCmd.Flags().StringVarP(&planDB, "plan", "p", os.Getenv("BACKUP_PLANS_CONNECTION"),
+		"sql connection string for your plan database")
+	verifyCmd.Flags().StringVarP(&treeDB, "tree", "t", "",
+		"Path to tree db file, usually generated using db cmd")
+	verifyCmd.Flags().StringVarP(&configPath, "config", "c", "", "ibackup config")
+	verifyCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "list each missing, stale and extra file")
+
+	verifyCmd.MarkFlagRequired("tree")   //nolint:errcheck
+	verifyCmd.MarkFlagRequired("config") //nolint:errcheck
+}
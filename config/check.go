@@ -0,0 +1,155 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/wtsi-hgi/backup-plans/auth"
+	"github.com/wtsi-hgi/backup-plans/ibackup"
+	"github.com/wtsi-hgi/backup-plans/users"
+)
+
+var (
+	ErrInvalidReportingRoot = errors.New("invalid reporting root")
+	ErrUnknownKey           = errors.New("unknown key")
+)
+
+// Check parses the Yaml config file at the given path, as Parse would, and
+// returns all of the problems found with it, without starting any clients.
+//
+// Invalid ibackup path regexps and transformers, references to unknown ibackup
+// servers, unreadable BOM, owner, identity, JWKS and git credential files,
+// invalid reporting roots, mtime sources and policies, and unusable
+// authentication settings are reported.
+//
+// Unknown keys, which Parse ignores, are also reported, as warnings that wrap
+// ErrUnknownKey.
+//
+// If connect is true, each configured ibackup and wrstat server will also be
+// connected to.
+func Check(configPath string, connect bool) []error {
+	y, err := decode(configPath)
+	if errs, ok := err.(interface{ Unwrap() []error }); ok { //nolint:errorlint
+		return errs.Unwrap()
+	} else if err != nil {
+		return []error{err}
+	}
+
+	errs := checkUnknownKeys(configPath)
+
+	add := func(prefix string, err error) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", prefix, err))
+		}
+	}
+
+	add("nfsmtimesources", y.checkMTimeSources())
+	add("policies", y.checkPolicies())
+
+	if _, err = users.Prepare(y.Identity); err != nil {
+		add("identity", err)
+	}
+
+	if _, err = auth.New(y.Auth); err != nil {
+		add("auth", err)
+	}
+
+	for _, err := range ibackup.CheckConfig(y.IBackup, connect) {
+		add("ibackup", err)
+	}
+
+	if y.WRStat.ServerURL != "" {
+		add("wrstat", y.WRStat.Check(connect))
+	}
+
+	add("bomfile", checkCSV(y.BOMFile, parseBOM))
+	add("ownersfile", checkCSV(y.OwnersFile, parseOwnerGIDs))
+
+	for _, root := range y.ReportingRoots {
+		add("reportingroots", checkReportingRoot(root))
+	}
+
+	for _, err := range y.Git.Check() {
+		add("git", err)
+	}
+
+	return errs
+}
+
+// checkUnknownKeys decodes the config file strictly, returning any errors,
+// which, as the file has already been decoded leniently, will be caused by
+// unknown keys.
+func checkUnknownKeys(configPath string) []error {
+	f, err := os.Open(configPath)
+	if err != nil {
+		return []error{err}
+	}
+	defer f.Close()
+
+	err = yaml.NewDecoder(f, yaml.Strict()).Decode(new(yamlConfig))
+	if err == nil {
+		return nil
+	}
+
+	errs := []error{err}
+
+	if multi, ok := err.(interface{ Unwrap() []error }); ok { //nolint:errorlint
+		errs = multi.Unwrap()
+	}
+
+	for n, err := range errs {
+		errs[n] = fmt.Errorf("%w: %w", ErrUnknownKey, err)
+	}
+
+	return errs
+}
+
+func checkCSV[T any](file string, parse func(io.Reader) (map[string][]T, error)) error {
+	if _, err := loadCSV(file, parse); err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+
+	return nil
+}
+
+func checkReportingRoot(root string) error {
+	if !strings.HasSuffix(root, "/") {
+		return fmt.Errorf("%w: %q must end in / to match directories", ErrInvalidReportingRoot, root)
+	}
+
+	if _, err := filepath.Match(root, ""); err != nil {
+		return fmt.Errorf("%w: %q: %w", ErrInvalidReportingRoot, root, err)
+	}
+
+	return nil
+}
@@ -28,14 +28,20 @@ package config
 import (
 	"encoding/csv"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"os"
+	"os/signal"
 	"strconv"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/goccy/go-yaml"
+	"github.com/wtsi-hgi/backup-plans/auth"
+	"github.com/wtsi-hgi/backup-plans/git"
 	"github.com/wtsi-hgi/backup-plans/ibackup"
 	"github.com/wtsi-hgi/backup-plans/users"
 	"github.com/wtsi-hgi/backup-plans/wrstat"
@@ -43,6 +49,9 @@ import (
 
 const csvCols = 2
 
+// fileCheckInterval is how often the config file is checked for changes.
+var fileCheckInterval = 10 * time.Second //nolint:gochecknoglobals
+
 var (
 	NullWRStat        *wrstat.Client       //nolint:gochecknoglobals
 	nullIBackupClient *ibackup.MultiClient //nolint:gochecknoglobals
@@ -66,10 +75,52 @@ type yamlConfig struct {
 	AdminGroup           uint32
 	ReloadTime           uint64
 	MainProgrammes       []string
+	Git                  git.Credentials
+	NFSMTimeSources      map[string]MTimeSource
+	Identity             users.Config
+	PlanDB               string
+	Policies             map[string]Policy
+	Auth                 auth.Config
+	NotifyURL            string
+	ReportHistoryDays    uint64
+}
+
+// Sources of mtimes for manualnfs backup targets.
+const (
+	MTimeSourceStat   = "stat"
+	MTimeSourceTree   = "tree"
+	MTimeSourceWRStat = "wrstat"
+)
+
+const defaultStatBudget = 10
+
+var ErrInvalidMTimeSource = errors.New("invalid mtime source")
+
+// MTimeSource describes how to get the latest mtime of the files within a
+// manualnfs backup target.
+//
+// Source is one of "stat", to walk the locally mounted path, descending at most
+// MaxDepth directories (zero for no limit) and for at most Budget seconds
+// (defaulting to 10); "tree", to look up the path in the loaded tree DBs; or
+// "wrstat", to query the configured wrstat server.
+type MTimeSource struct {
+	Source   string
+	MaxDepth int
+	Budget   uint64
+}
+
+// BudgetDuration returns the Budget as a Duration, applying the default if
+// unset.
+func (m MTimeSource) BudgetDuration() time.Duration {
+	if m.Budget == 0 {
+		return defaultStatBudget * time.Second
+	}
+
+	return time.Duration(m.Budget) * time.Second //nolint:gosec
 }
 
 // Config represents a parsed configuration file which can be automatically
-// updated on a timeout.
+// reloaded.
 //
 // The returned values from the Get* methods are not guaranteed to be constant
 // over time and should therefore not be stored.
@@ -85,13 +136,26 @@ type Config struct {
 	boms                map[string][]string
 	owners              map[string][]string
 	yamlConfig          yamlConfig
+
+	reloadMu   sync.Mutex
+	reloadCh   chan struct{}
+	stopCh     chan struct{}
+	stopOnce   sync.Once
+	modTime    time.Time
+	reloadAt   time.Time
+	generation uint64
+	loadedAt   time.Time
+	lastError  error
 }
 
 // Parse parses the Yaml file at the given path to get server config.
 //
-// If the ReloadTime setting is non-zero, the config will be reloaded after
-// waiting that many seconds. Reloading the config will rebuild all structures,
-// while keeping any caches intact.
+// The config will be reloaded when the process receives a SIGHUP, when the
+// modification time of the file changes, and, if the ReloadTime setting is
+// non-zero, every ReloadTime seconds. Reloading the config will rebuild all
+// structures, while keeping any caches intact; the new config is only used if
+// it all loads successfully, otherwise the previous config remains in use and
+// the error is reported by Status.
 //
 // The following is the config structure:
 //
@@ -111,6 +175,45 @@ type Config struct {
 //		}
 //
 //		wrstatcacheduration: uint64
+//
+//		nfsmtimesources map[string]struct {
+//			source string
+//			maxdepth int
+//			budget uint64
+//		}
+//
+//		git {
+//			httptokens map[string]struct {
+//				username, tokenfile string
+//			}
+//			sshkey, knownhosts string
+//		}
+//
+//		identity {
+//			source, passwd, group, json string
+//			positivettl, negativettl uint64
+//		}
+//
+//		plandb string
+//
+//		notifyurl string
+//
+//		reporthistorydays uint64
+//
+//		policies map[string]struct {
+//			frequency uint
+//			minreview, maxreview, minremoval, maxremoval uint64
+//			allowed, forbidden []string
+//			nooverrides bool
+//		}
+//
+//		auth {
+//			method, cookie, jwks, issuer, audience, claim, header string
+//			trustedproxies []string
+//			stripdomain bool
+//			logouturl string
+//		}
+//
 //	    IBackupCacheDuration uint64
 //	    BOMFile              string
 //	    OwnersFile           string
@@ -126,6 +229,42 @@ type Config struct {
 // against path; a matching path will use the server details associated with the
 // regexp.
 //
+// The key of the git httptokens map is the host name of a git remote; the token
+// is read from the tokenfile each time it is needed, and sent with the username,
+// which defaults to "oauth2", using HTTP basic authentication. The sshkey is
+// the path to a private key used for SSH remotes, verified against the given
+// knownhosts file, or the default known_hosts files if unset.
+//
+// The key of the nfsmtimesources map is a path prefix; the latest mtime of a
+// manualnfs target will be retrieved using the source with the longest
+// matching prefix. The source is one of "stat", "tree" or "wrstat" (see
+// MTimeSource). Targets without a matching prefix use "wrstat" if a wrstat
+// server is configured, and "tree" otherwise.
+//
+// Any string value in the config, but not map keys, may contain ${NAME}
+// references, which are replaced with the value of the environment variable
+// NAME; it is an error for the variable to be unset. A value that then starts
+// with "file:" is replaced with the contents of the named file, with
+// surrounding whitespace removed, allowing secrets, such as the plandb
+// connection string, to be kept out of the config file and process list.
+//
+// The key of the policies map is a path prefix; the policy with the longest
+// prefix of a directory restricts the details and rules that can be set for it
+// (see Policy).
+//
+// The notifyurl is a URL to which admin actions are POSTed, as JSON, when the
+// admin asks for the claimant to be notified.
+//
+// The reporthistorydays is the number of days for which snapshots of the report
+// totals are kept; older snapshots are removed each time a new one is taken. If
+// zero, snapshots are kept forever.
+//
+// The auth settings select how the users making requests are authenticated (see
+// auth.Config); unlike the other settings, they are only read at startup.
+//
+// The identity settings select how user and group names and IDs are resolved
+// (see users.Config).
+//
 // OwnersFile and BOMFile strings are paths to CSV files with the following
 // formats:
 //
@@ -142,76 +281,309 @@ func Parse(path string) (*Config, error) {
 		ibackupClient:       nullIBackupClient,
 		ibackupCachedClient: nullIBackupCache,
 		wrstatClient:        NullWRStat,
+		reloadCh:            make(chan struct{}, 1),
+		stopCh:              make(chan struct{}),
 	}
 
 	if err := c.loadConfig(); err != nil {
 		return nil, err
 	}
 
+	go c.watch()
+
 	return c, nil
 }
 
+// loaded contains everything built from a config file that can fail, which is
+// only applied to a Config once it has all been built successfully.
+type loaded struct {
+	yamlConfig    yamlConfig
+	modTime       time.Time
+	boms          map[string][]string
+	ownerGIDs     map[string][]uint32
+	applyIdentity func()
+	applyWRStat   func()
+	wrstatClient  *wrstat.Client
+	ibackupClient *ibackup.MultiClient
+}
+
 func (c *Config) loadConfig() error {
-	defer c.scheduleReload()
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
 
-	f, err := os.Open(c.path)
+	modTime := fileModTime(c.path)
+
+	l, err := c.load()
 	if err != nil {
+		c.mu.Lock()
+		c.lastError = err
+		c.modTime = modTime
+		c.scheduleReload()
+		c.mu.Unlock()
+
 		return err
 	}
-	defer f.Close()
 
-	err = yaml.NewDecoder(f).Decode(&c.yamlConfig)
-	if err != nil {
-		return err
+	l.modTime = modTime
+
+	c.apply(l)
+
+	return nil
+}
+
+// scheduleReload sets the time of the next timed reload; c.mu must be held.
+func (c *Config) scheduleReload() {
+	c.reloadAt = time.Now().Add(time.Second * time.Duration(c.yamlConfig.ReloadTime)) //nolint:gosec
+}
+
+func (c *Config) load() (*loaded, error) { //nolint:funlen
+	var (
+		l   loaded
+		err error
+	)
+
+	if l.yamlConfig, err = decode(c.path); err != nil {
+		return nil, err
 	}
 
-	if err = c.loadIBackup(); err != nil {
-		return err
+	if err = l.yamlConfig.checkMTimeSources(); err != nil {
+		return nil, err
 	}
 
-	if err = c.loadWRStat(); err != nil {
-		return err
+	if err = l.yamlConfig.checkPolicies(); err != nil {
+		return nil, err
 	}
 
-	if err = c.loadBOMs(); err != nil {
-		return err
+	if l.boms, err = loadCSV(l.yamlConfig.BOMFile, parseBOM); err != nil {
+		return nil, err
 	}
 
-	if err = c.loadOwners(); err != nil {
-		return err
+	if l.ownerGIDs, err = loadCSV(l.yamlConfig.OwnersFile, parseOwnerGIDs); err != nil {
+		return nil, err
 	}
 
-	return nil
+	if l.applyIdentity, err = users.Prepare(l.yamlConfig.Identity); err != nil {
+		return nil, err
+	}
+
+	if err = c.loadWRStat(&l); err != nil {
+		return nil, err
+	}
+
+	if err = loadIBackup(&l); err != nil {
+		l.discard()
+
+		return nil, err
+	}
+
+	return &l, nil
 }
 
-func (c *Config) scheduleReload() {
-	if c.yamlConfig.ReloadTime == 0 {
-		return
+// discard stops a newly created wrstat client that will not be used.
+func (l *loaded) discard() {
+	if l.applyWRStat == nil && l.wrstatClient != NullWRStat {
+		l.wrstatClient.Stop()
+	}
+}
+
+func decode(path string, opts ...yaml.DecodeOption) (yamlConfig, error) {
+	var y yamlConfig
+
+	f, err := os.Open(path)
+	if err != nil {
+		return y, err
+	}
+	defer f.Close()
+
+	if err = yaml.NewDecoder(f, opts...).Decode(&y); err != nil {
+		return y, err
 	}
 
-	go c.reload()
+	return y, interpolate(&y)
 }
 
-func (c *Config) reload() {
-	time.Sleep(time.Second * time.Duration(c.yamlConfig.ReloadTime)) //nolint:gosec
+// apply swaps the newly loaded config into place, keeping any caches intact.
+func (c *Config) apply(l *loaded) {
+	l.applyIdentity()
+
+	owners := resolveOwners(l.ownerGIDs)
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if err := c.loadConfig(); err != nil {
-		slog.Warn("error reloading config", "errs", err)
+	if l.applyWRStat != nil {
+		l.applyWRStat()
+	}
+
+	if c.wrstatClient != l.wrstatClient && c.wrstatClient != NullWRStat {
+		c.wrstatClient.Stop()
 	}
+
+	c.wrstatClient = l.wrstatClient
+
+	c.applyIBackup(l)
+
+	c.boms = l.boms
+	c.owners = owners
+	c.yamlConfig = l.yamlConfig
+	c.modTime = l.modTime
+	c.generation++
+	c.loadedAt = time.Now()
+	c.lastError = nil
+
+	c.scheduleReload()
 }
 
-func (c *Config) loadIBackup() error {
-	if len(c.yamlConfig.IBackup.Servers) == 0 {
+func (c *Config) applyIBackup(l *loaded) {
+	previous := c.ibackupClient
+
+	if l.ibackupClient == nil { //nolint:gocritic,nestif
 		c.ibackupClient = nullIBackupClient
 		c.ibackupCachedClient = nullIBackupCache
+	} else if c.ibackupCachedClient == nullIBackupCache {
+		c.ibackupClient = l.ibackupClient
+		c.ibackupCachedClient = ibackup.NewMultiCache(l.ibackupClient,
+			time.Second*time.Duration(l.yamlConfig.IBackupCacheDuration)) //nolint:gosec
+	} else {
+		c.ibackupClient = l.ibackupClient
+		c.ibackupCachedClient.Update(l.ibackupClient)
+	}
+
+	if previous != c.ibackupClient {
+		previous.Stop()
+	}
+}
+
+// Reload requests that the config file be reloaded. The reload happens in the
+// background; its success can be seen with Status.
+func (c *Config) Reload() {
+	select {
+	case c.reloadCh <- struct{}{}:
+	default:
+	}
+}
+
+// Stop stops the config being reloaded.
+func (c *Config) Stop() {
+	c.stopOnce.Do(func() {
+		if c.stopCh != nil {
+			close(c.stopCh)
+		}
+	})
+}
+
+// Close stops the config being reloaded, and stops the background work of the
+// ibackup and wrstat clients.
+func (c *Config) Close() {
+	c.Stop()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.ibackupCachedClient != nullIBackupCache {
+		c.ibackupCachedClient.Stop()
+	}
+
+	if c.ibackupClient != nullIBackupClient {
+		c.ibackupClient.Stop()
+	}
+
+	if c.wrstatClient != NullWRStat {
+		c.wrstatClient.Stop()
+	}
+}
+
+// watch reloads the config when requested via Reload, when the process
+// receives a SIGHUP, when the modification time of the config file changes,
+// and every ReloadTime seconds if that is non-zero.
+func (c *Config) watch() {
+	sighup := make(chan os.Signal, 1)
+
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	fileCheck := time.NewTicker(fileCheckInterval)
+	defer fileCheck.Stop()
+
+	for {
+		timer := c.reloadTimer()
+
+		select {
+		case <-c.stopCh:
+			return
+		case <-sighup:
+			c.reload("signal")
+		case <-c.reloadCh:
+			c.reload("request")
+		case <-timer:
+			c.reload("timer")
+		case <-fileCheck.C:
+			if c.fileChanged() {
+				c.reload("file change")
+			}
+		}
+	}
+}
+
+func (c *Config) reloadTimer() <-chan time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.yamlConfig.ReloadTime == 0 {
+		return nil
+	}
+
+	return time.After(time.Until(c.reloadAt))
+}
+
+func (c *Config) fileChanged() bool {
+	modTime := fileModTime(c.path)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return !modTime.Equal(c.modTime)
+}
+
+// fileModTime returns the modification time of the file at the given path, or
+// the zero time if it cannot be found.
+func fileModTime(path string) time.Time {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return fi.ModTime()
+}
 
+func (c *Config) reload(reason string) {
+	if err := c.loadConfig(); err != nil {
+		slog.Warn("error reloading config", "reason", reason, "errs", err)
+
+		return
+	}
+
+	slog.Info("reloaded config", "reason", reason)
+}
+
+func (y *yamlConfig) checkMTimeSources() error {
+	for prefix, src := range y.NFSMTimeSources {
+		switch src.Source {
+		case MTimeSourceStat, MTimeSourceTree, MTimeSourceWRStat:
+		default:
+			return fmt.Errorf("%w for %s: %q", ErrInvalidMTimeSource, prefix, src.Source)
+		}
+	}
+
+	return nil
+}
+
+func loadIBackup(l *loaded) error {
+	if len(l.yamlConfig.IBackup.Servers) == 0 {
 		return nil
 	}
 
-	mc, err := ibackup.New(c.yamlConfig.IBackup)
+	mc, err := ibackup.New(l.yamlConfig.IBackup)
 	if err != nil {
 		if !ibackup.IsOnlyConnectionErrors(err) {
 			return err
@@ -220,63 +592,64 @@ func (c *Config) loadIBackup() error {
 		slog.Warn("ibackup connection errors", "errs", err)
 	}
 
-	if c.ibackupCachedClient == nullIBackupCache {
-		c.ibackupCachedClient = ibackup.NewMultiCache(mc, time.Second*time.Duration(c.yamlConfig.IBackupCacheDuration)) //nolint:gosec,lll
-	} else {
-		c.ibackupCachedClient.Update(mc)
-	}
-
-	c.ibackupClient.Stop()
-
-	c.ibackupClient = mc
+	l.ibackupClient = mc
 
 	return nil
 }
 
-func (c *Config) loadWRStat() error {
-	if c.yamlConfig.WRStat.ServerURL == "" {
-		c.wrstatClient = NullWRStat
+// loadWRStat reuses the current wrstat client with the new config, unless the
+// cache duration has changed, in which case a new client is created. A replaced
+// client is stopped when the new config is applied.
+func (c *Config) loadWRStat(l *loaded) error {
+	if l.yamlConfig.WRStat.ServerURL == "" {
+		l.wrstatClient = NullWRStat
 
 		return nil
 	}
 
-	if c.wrstatClient != NullWRStat {
-		return c.wrstatClient.UpdateConfig(c.yamlConfig.WRStat)
+	c.mu.RLock()
+	current := c.wrstatClient
+	cacheDuration := c.yamlConfig.WRStatCacheDuration
+	c.mu.RUnlock()
+
+	if current != NullWRStat && cacheDuration == l.yamlConfig.WRStatCacheDuration {
+		apply, err := current.PrepareConfig(l.yamlConfig.WRStat)
+		if err != nil {
+			return err
+		}
+
+		l.wrstatClient = current
+		l.applyWRStat = apply
+
+		return nil
 	}
 
 	client, err := wrstat.New(
-		time.Second*time.Duration(c.yamlConfig.WRStatCacheDuration), //nolint:gosec
-		c.yamlConfig.WRStat,
+		time.Second*time.Duration(l.yamlConfig.WRStatCacheDuration), //nolint:gosec
+		l.yamlConfig.WRStat,
 	)
 	if err != nil {
 		return err
 	}
 
-	c.wrstatClient = client
+	l.wrstatClient = client
 
 	return nil
 }
 
-func (c *Config) loadBOMs() error {
-	if c.yamlConfig.BOMFile == "" {
-		return nil
+func loadCSV[T any](file string, parse func(io.Reader) (map[string][]T, error)) (map[string][]T, error) {
+	if file == "" {
+		return nil, nil
 	}
 
-	f, err := os.Open(c.yamlConfig.BOMFile)
+	f, err := os.Open(file)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	defer f.Close()
 
-	bomMap, err := parseBOM(f)
-	if err != nil {
-		return err
-	}
-
-	c.boms = bomMap
-
-	return nil
+	return parse(f)
 }
 
 func parseBOM(r io.Reader) (map[string][]string, error) {
@@ -300,30 +673,17 @@ func parseBOM(r io.Reader) (map[string][]string, error) {
 	return bomMap, nil
 }
 
-func (c *Config) loadOwners() error {
-	if c.yamlConfig.OwnersFile == "" {
-		return nil
-	}
-
-	f, err := os.Open(c.yamlConfig.OwnersFile)
-	if err != nil {
-		return err
-	}
-
-	defer f.Close()
-
-	ownersMap, err := parseOwners(f)
+func parseOwners(r io.Reader) (map[string][]string, error) {
+	ownerGIDs, err := parseOwnerGIDs(r)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	c.owners = ownersMap
-
-	return nil
+	return resolveOwners(ownerGIDs), nil
 }
 
-func parseOwners(r io.Reader) (map[string][]string, error) {
-	ownersMap := make(map[string][]string)
+func parseOwnerGIDs(r io.Reader) (map[string][]uint32, error) {
+	ownersMap := make(map[string][]uint32)
 
 	cr := csv.NewReader(r)
 
@@ -342,12 +702,59 @@ func parseOwners(r io.Reader) (map[string][]string, error) {
 			return nil, err
 		}
 
-		ownersMap[record[1]] = append(ownersMap[record[1]], users.Group(uint32(gid)))
+		ownersMap[record[1]] = append(ownersMap[record[1]], uint32(gid))
 	}
 
 	return ownersMap, nil
 }
 
+func resolveOwners(ownerGIDs map[string][]uint32) map[string][]string {
+	if ownerGIDs == nil {
+		return nil
+	}
+
+	ownersMap := make(map[string][]string, len(ownerGIDs))
+
+	for owner, gids := range ownerGIDs {
+		for _, gid := range gids {
+			ownersMap[owner] = append(ownersMap[owner], users.Group(gid))
+		}
+	}
+
+	return ownersMap
+}
+
+// Status describes the currently loaded config.
+type Status struct {
+	// Generation is incremented each time the config is successfully loaded.
+	Generation uint64
+
+	// Loaded is the time the current config was loaded.
+	Loaded time.Time
+
+	// LastError is the error from the latest attempt to load the config, if it
+	// failed, in which case the previous config remains in use.
+	LastError string `json:",omitempty"`
+}
+
+// Status returns the generation and load time of the current config, and the
+// error from the last reload if it failed.
+func (c *Config) Status() Status {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	s := Status{
+		Generation: c.generation,
+		Loaded:     c.loadedAt,
+	}
+
+	if c.lastError != nil {
+		s.LastError = c.lastError.Error()
+	}
+
+	return s
+}
+
 // GetIBackupClient returns an ibackup client that connects to multiple ibackup
 // servers.
 func (c *Config) GetIBackupClient() *ibackup.MultiClient {
@@ -375,6 +782,46 @@ func (c *Config) GetCachedIBackupClient() *ibackup.MultiCache {
 	return c.ibackupCachedClient
 }
 
+// GetGitCredentials returns the credentials to use when accessing remote git
+// repos.
+func (c *Config) GetGitCredentials() *git.Credentials {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	creds := c.yamlConfig.Git
+
+	return &creds
+}
+
+// GetMTimeSource returns the source to use to get the latest mtime of the given
+// manualnfs target path.
+func (c *Config) GetMTimeSource(path string) MTimeSource {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var (
+		longest string
+		source  MTimeSource
+		found   bool
+	)
+
+	for prefix, src := range c.yamlConfig.NFSMTimeSources {
+		if strings.HasPrefix(path, prefix) && (!found || len(prefix) > len(longest)) {
+			longest, source, found = prefix, src, true
+		}
+	}
+
+	if found {
+		return source
+	}
+
+	if c.wrstatClient == NullWRStat {
+		return MTimeSource{Source: MTimeSourceTree}
+	}
+
+	return MTimeSource{Source: MTimeSourceWRStat}
+}
+
 // GetBOMs returns a map of BOMs to the groups owned.
 func (c *Config) GetBOMs() map[string][]string {
 	c.mu.RLock()
@@ -407,6 +854,41 @@ func (c *Config) GetAdminGroup() uint32 {
 	return c.yamlConfig.AdminGroup
 }
 
+// GetAuth returns the settings used to authenticate users.
+func (c *Config) GetAuth() auth.Config {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.yamlConfig.Auth
+}
+
+// GetNotifyURL returns the URL to which notifications of admin actions are
+// sent, if one was specified in the config.
+func (c *Config) GetNotifyURL() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.yamlConfig.NotifyURL
+}
+
+// GetReportHistory returns how long snapshots of the report totals are kept,
+// with zero meaning forever.
+func (c *Config) GetReportHistory() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return time.Duration(c.yamlConfig.ReportHistoryDays) * 24 * time.Hour //nolint:gosec,mnd
+}
+
+// GetPlanDB returns the connection string for the plan database, if one was
+// specified in the config.
+func (c *Config) GetPlanDB() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.yamlConfig.PlanDB
+}
+
 func (c *Config) GetMainProgrammes() []string {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
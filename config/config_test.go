@@ -26,15 +26,18 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"os/user"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/goccy/go-yaml"
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/wtsi-hgi/backup-plans/db"
 	"github.com/wtsi-hgi/backup-plans/ibackup"
 	ib "github.com/wtsi-hgi/backup-plans/internal/ibackup"
 	"github.com/wtsi-hgi/ibackup/server"
@@ -221,3 +224,327 @@ group3,bomB`,
 		})
 	})
 }
+
+func TestCheck(t *testing.T) {
+	Convey("Given a config file", t, func() {
+		tmp := t.TempDir()
+		cfgFile := filepath.Join(tmp, "config.yml")
+		bomFile := filepath.Join(tmp, "bom")
+
+		So(os.WriteFile(bomFile, []byte("group1,bomA\n"), 0600), ShouldBeNil)
+
+		writeConfig := func(config string) {
+			So(os.WriteFile(cfgFile, []byte(config), 0600), ShouldBeNil)
+		}
+
+		Convey("A valid config has no problems", func() {
+			writeConfig(`ibackup:
+  servers:
+    example:
+      fofndir: ` + tmp + `
+  pathtoserver:
+    ^/some/path/:
+      servername: example
+      transformer: ` + ib.CustomTransformer + `
+bomfile: ` + bomFile + `
+reportingroots:
+  - /some/*/
+`)
+
+			So(Check(cfgFile, true), ShouldBeEmpty)
+		})
+
+		Convey("All of the problems in an invalid config are returned", func() {
+			writeConfig(`ibackup:
+  servers:
+    example:
+      fofndir: ` + bomFile + `
+  pathtoserver:
+    ^/some/(path/:
+      servername: example
+      transformer: ` + ib.CustomTransformer + `
+    ^/other/path/:
+      servername: missing
+      manualservername: alsomissing
+      transformer: notATransformer
+ownersfile: ` + filepath.Join(tmp, "missing") + `
+reportingroots:
+  - /some/path
+  - /some/[/
+nfsmtimesources:
+  /nfs/:
+    source: ls
+git:
+  httptokens:
+    example.com:
+      tokenfile: ` + filepath.Join(tmp, "token") + `
+auth:
+  method: header
+`)
+
+			errs := Check(cfgFile, false)
+			So(errs, ShouldHaveLength, 10)
+
+			var msgs []string
+
+			for _, err := range errs {
+				msgs = append(msgs, err.Error())
+			}
+
+			all := strings.Join(msgs, "\n")
+
+			So(all, ShouldContainSubstring, `nfsmtimesources: invalid mtime source for /nfs/: "ls"`)
+			So(all, ShouldContainSubstring, `ibackup: pathtoserver "^/other/path/": unknown server name: missing`)
+			So(all, ShouldContainSubstring, `ibackup: pathtoserver "^/other/path/": manual unknown server name: alsomissing`)
+			So(all, ShouldContainSubstring, `ibackup: pathtoserver "^/other/path/": invalid transformer "notATransformer"`)
+			So(all, ShouldContainSubstring, `ibackup: pathtoserver "^/some/(path/": error parsing regexp`)
+			So(all, ShouldContainSubstring, "ownersfile: open ")
+			So(all, ShouldContainSubstring, `reportingroots: invalid reporting root: "/some/path" must end in /`)
+			So(all, ShouldContainSubstring, `reportingroots: invalid reporting root: "/some/[/"`)
+			So(all, ShouldContainSubstring, "git: token for example.com: authentication failed")
+			So(all, ShouldContainSubstring, "auth: no trusted proxies specified")
+
+			So(Check(cfgFile, true), ShouldHaveLength, 11)
+		})
+
+		Convey("Unknown keys are reported as warnings, alongside other problems", func() {
+			writeConfig("reportingroot:\n  - /some/path/\nreportingroots:\n  - /some/path\n")
+
+			errs := Check(cfgFile, false)
+			So(errs, ShouldHaveLength, 2)
+			So(errs[0], ShouldWrap, ErrUnknownKey)
+			So(errs[1], ShouldWrap, ErrInvalidReportingRoot)
+
+			Convey("…which Parse ignores", func() {
+				writeConfig("reportingroot:\n  - /some/path/\n")
+
+				c, err := Parse(cfgFile)
+				So(err, ShouldBeNil)
+
+				Reset(c.Stop)
+			})
+		})
+	})
+}
+
+func TestReload(t *testing.T) {
+	Convey("Given a parsed config file", t, func() {
+		tmp := t.TempDir()
+		cfgFile := filepath.Join(tmp, "config.yml")
+		bomFile := filepath.Join(tmp, "bom")
+
+		So(os.WriteFile(bomFile, []byte("group1,bomA\n"), 0600), ShouldBeNil)
+		So(os.WriteFile(cfgFile, []byte("bomfile: "+bomFile+"\nreportingroots:\n  - /a/\n"), 0600), ShouldBeNil)
+
+		config, err := Parse(cfgFile)
+		So(err, ShouldBeNil)
+
+		Reset(config.Stop)
+
+		So(config.Status().Generation, ShouldEqual, 1)
+		So(config.Status().LastError, ShouldBeBlank)
+		So(config.GetReportingRoots(), ShouldResemble, []string{"/a/"})
+
+		waitFor := func(generation uint64, hasError bool) Status {
+			var status Status
+
+			for range 100 {
+				if status = config.Status(); status.Generation == generation && (status.LastError != "") == hasError {
+					break
+				}
+
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			return status
+		}
+
+		Convey("A config that fails to load is not applied", func() {
+			So(os.WriteFile(cfgFile, []byte("bomfile: "+filepath.Join(tmp, "missing")+
+				"\nreportingroots:\n  - /b/\n"), 0600), ShouldBeNil)
+
+			config.Reload()
+
+			status := waitFor(1, true)
+			So(status.Generation, ShouldEqual, 1)
+			So(status.LastError, ShouldContainSubstring, "no such file")
+			So(config.GetReportingRoots(), ShouldResemble, []string{"/a/"})
+			So(config.GetBOMs(), ShouldResemble, map[string][]string{"bomA": {"group1"}})
+
+			Convey("Fixing the config applies it", func() {
+				So(os.WriteFile(cfgFile, []byte("reportingroots:\n  - /c/\n"), 0600), ShouldBeNil)
+
+				config.Reload()
+
+				status := waitFor(2, false)
+				So(status.Generation, ShouldEqual, 2)
+				So(status.LastError, ShouldBeBlank)
+				So(config.GetReportingRoots(), ShouldResemble, []string{"/c/"})
+				So(config.GetBOMs(), ShouldBeNil)
+			})
+		})
+
+		Convey("The wrstat client is only replaced when its cache duration changes", func() {
+			wrstatConfig := func(cacheDuration int) []byte {
+				return fmt.Appendf(nil, "wrstat:\n  serverurl: https://wrstat.example.com\n  username: user\n"+
+					"wrstatcacheduration: %d\n", cacheDuration)
+			}
+
+			So(os.WriteFile(cfgFile, wrstatConfig(10), 0600), ShouldBeNil)
+
+			config.Reload()
+			So(waitFor(2, false).Generation, ShouldEqual, 2)
+
+			client := config.GetWRStatClient()
+			So(client, ShouldNotEqual, NullWRStat)
+
+			So(os.WriteFile(cfgFile, wrstatConfig(10), 0600), ShouldBeNil)
+
+			config.Reload()
+			So(waitFor(3, false).Generation, ShouldEqual, 3)
+			So(config.GetWRStatClient(), ShouldEqual, client)
+
+			So(os.WriteFile(cfgFile, wrstatConfig(20), 0600), ShouldBeNil)
+
+			config.Reload()
+			So(waitFor(4, false).Generation, ShouldEqual, 4)
+			So(config.GetWRStatClient(), ShouldNotEqual, client)
+			So(config.GetWRStatClient(), ShouldNotEqual, NullWRStat)
+
+			So(os.WriteFile(cfgFile, []byte("reportingroots:\n  - /a/\n"), 0600), ShouldBeNil)
+
+			config.Reload()
+			So(waitFor(5, false).Generation, ShouldEqual, 5)
+			So(config.GetWRStatClient(), ShouldEqual, NullWRStat)
+		})
+
+		Convey("A change to the config file is loaded automatically", func() {
+			fileCheckInterval = 10 * time.Millisecond
+
+			Reset(func() { fileCheckInterval = 10 * time.Second })
+
+			config.Stop()
+
+			config, err = Parse(cfgFile)
+			So(err, ShouldBeNil)
+
+			Reset(config.Stop)
+
+			So(os.WriteFile(cfgFile, []byte("reportingroots:\n  - /d/\n"), 0600), ShouldBeNil)
+			So(os.Chtimes(cfgFile, time.Time{}, time.Now().Add(time.Minute)), ShouldBeNil)
+
+			So(waitFor(2, false).Generation, ShouldEqual, 2)
+			So(config.GetReportingRoots(), ShouldResemble, []string{"/d/"})
+		})
+	})
+}
+
+func TestInterpolate(t *testing.T) {
+	Convey("Given a config file with environment and secret references", t, func() {
+		tmp := t.TempDir()
+		cfgFile := filepath.Join(tmp, "config.yml")
+		secret := filepath.Join(tmp, "plandb")
+
+		So(os.WriteFile(secret, []byte("mysql:user:password@tcp(host:3306)/db\n"), 0600), ShouldBeNil)
+
+		t.Setenv("SECRETS_DIR", tmp)
+		t.Setenv("WRSTAT_USER", "wrstatUser")
+
+		So(os.WriteFile(cfgFile, []byte(`plandb: file:${SECRETS_DIR}/plandb
+wrstat:
+  username: ${WRSTAT_USER}
+reportingroots:
+  - /a/${WRSTAT_USER}/
+ibackup:
+  pathtoserver:
+    ^/${WRSTAT_USER}/$:
+      servername: s
+`), 0600), ShouldBeNil)
+
+		Convey("The references in string values are replaced", func() {
+			y, err := decode(cfgFile)
+			So(err, ShouldBeNil)
+			So(y.PlanDB, ShouldEqual, "mysql:user:password@tcp(host:3306)/db")
+			So(y.WRStat.Username, ShouldEqual, "wrstatUser")
+			So(y.ReportingRoots, ShouldResemble, []string{"/a/wrstatUser/"})
+			So(y.IBackup.PathToServer, ShouldContainKey, "^/${WRSTAT_USER}/$")
+		})
+
+		Convey("Unset variables and missing secrets are reported", func() {
+			So(os.WriteFile(cfgFile, []byte(`plandb: file:${SECRETS_DIR}/missing
+wrstat:
+  username: ${UNSET_VARIABLE}
+`), 0600), ShouldBeNil)
+
+			_, err := decode(cfgFile)
+			So(err, ShouldWrap, ErrUnsetVariable)
+			So(err.Error(), ShouldContainSubstring, "reading secret")
+
+			So(Check(cfgFile, false), ShouldHaveLength, 2)
+		})
+	})
+}
+
+func TestPolicies(t *testing.T) {
+	Convey("Given a config file with policies", t, func() {
+		cfgFile := filepath.Join(t.TempDir(), "config.yml")
+
+		So(os.WriteFile(cfgFile, []byte(`policies:
+  /lustre/:
+    frequency: 1
+  /lustre/scratch/:
+    forbidden:
+      - backup
+  /lustre/projects/:
+    minreview: 30
+    maxreview: 365
+    allowed:
+      - backup
+      - nobackup
+`), 0600), ShouldBeNil)
+
+		c, err := Parse(cfgFile)
+		So(err, ShouldBeNil)
+
+		Reset(c.Stop)
+
+		Convey("The policy with the longest matching prefix is returned", func() {
+			p := c.GetPolicy("/lustre/scratch/a/")
+			So(p.Prefix, ShouldEqual, "/lustre/scratch/")
+			So(p.AllowsBackupType(db.BackupIBackup), ShouldBeFalse)
+			So(p.AllowsBackupType(db.BackupManualGit), ShouldBeTrue)
+
+			p = c.GetPolicy("/lustre/projects/a/")
+			So(p.Prefix, ShouldEqual, "/lustre/projects/")
+			So(p.AllowsBackupType(db.BackupIBackup), ShouldBeTrue)
+			So(p.AllowsBackupType(db.BackupManualGit), ShouldBeFalse)
+
+			now := time.Now()
+			earliest, latest := p.ReviewBounds(now)
+			So(earliest.Unix(), ShouldEqual, now.Truncate(24*time.Hour).Add(30*24*time.Hour).Unix())
+			So(latest.Unix(), ShouldEqual, now.Add(365*24*time.Hour).Unix())
+
+			So(c.GetPolicy("/lustre/other/").Frequency, ShouldEqual, 1)
+			So(c.GetPolicy("/nfs/"), ShouldResemble, Policy{})
+		})
+
+		Convey("Invalid policies are rejected", func() {
+			So(os.WriteFile(cfgFile, []byte(`policies:
+  /lustre/:
+    allowed:
+      - tape
+`), 0600), ShouldBeNil)
+
+			_, err := Parse(cfgFile)
+			So(err, ShouldWrap, ErrUnknownBackupType)
+
+			So(os.WriteFile(cfgFile, []byte(`policies:
+  /lustre/:
+    minremoval: 10
+    maxremoval: 5
+`), 0600), ShouldBeNil)
+
+			So(Check(cfgFile, false), ShouldHaveLength, 1)
+		})
+	})
+}
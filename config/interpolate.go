@@ -0,0 +1,125 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const secretPrefix = "file:"
+
+var (
+	ErrUnsetVariable = errors.New("environment variable not set")
+
+	envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`) //nolint:gochecknoglobals
+)
+
+// interpolate replaces, in every string in the given config, including those
+// in nested structs, slices and map values, each ${NAME} reference with the
+// value of the environment variable NAME. Any resulting string starting with
+// "file:" is then replaced by the contents of the named file, with surrounding
+// whitespace removed.
+//
+// Map keys are left unchanged.
+func interpolate(y *yamlConfig) error {
+	var i interpolator
+
+	i.value(reflect.ValueOf(y).Elem())
+
+	return errors.Join(i.errs...)
+}
+
+type interpolator struct {
+	errs []error
+}
+
+func (i *interpolator) value(v reflect.Value) {
+	switch v.Kind() { //nolint:exhaustive
+	case reflect.String:
+		v.SetString(i.string(v.String()))
+	case reflect.Pointer:
+		if !v.IsNil() {
+			i.value(v.Elem())
+		}
+	case reflect.Struct:
+		for n := range v.NumField() {
+			if v.Type().Field(n).IsExported() {
+				i.value(v.Field(n))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for n := range v.Len() {
+			i.value(v.Index(n))
+		}
+	case reflect.Map:
+		i.mapValues(v)
+	}
+}
+
+func (i *interpolator) mapValues(v reflect.Value) {
+	iter := v.MapRange()
+
+	for iter.Next() {
+		value := reflect.New(iter.Value().Type()).Elem()
+		value.Set(iter.Value())
+
+		i.value(value)
+
+		v.SetMapIndex(iter.Key(), value)
+	}
+}
+
+func (i *interpolator) string(s string) string {
+	s = envReference.ReplaceAllStringFunc(s, func(ref string) string {
+		name := envReference.FindStringSubmatch(ref)[1]
+
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			i.errs = append(i.errs, fmt.Errorf("%w: %s", ErrUnsetVariable, name))
+		}
+
+		return value
+	})
+
+	file, ok := strings.CutPrefix(s, secretPrefix)
+	if !ok {
+		return s
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		i.errs = append(i.errs, fmt.Errorf("reading secret: %w", err))
+
+		return ""
+	}
+
+	return strings.TrimSpace(string(data))
+}
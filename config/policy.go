@@ -0,0 +1,154 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/wtsi-hgi/backup-plans/db"
+)
+
+const day = 24 * time.Hour
+
+var (
+	ErrInvalidPolicy     = errors.New("invalid policy")
+	ErrUnknownBackupType = errors.New("unknown backup type")
+)
+
+// Policy restricts the plans that can be made for directories under a path
+// prefix.
+//
+// Frequency is the default backup frequency, in days, for newly claimed
+// directories.
+//
+// MinReview and MaxReview bound the number of days between now and the review
+// date of a directory, and MinRemoval and MaxRemoval bound the number of days
+// between the review date and the removal date. A zero maximum is unbounded.
+//
+// Allowed, if non-empty, lists the only backup types (e.g. "backup",
+// "nobackup", "manualgit") that rules may use, and Forbidden lists backup types
+// that rules may not use.
+//
+// NoOverrides disallows rules that override the rules of child directories.
+type Policy struct {
+	Prefix      string `yaml:"-"`
+	Frequency   uint
+	MinReview   uint64
+	MaxReview   uint64
+	MinRemoval  uint64
+	MaxRemoval  uint64
+	Allowed     []string
+	Forbidden   []string
+	NoOverrides bool
+}
+
+// AllowsBackupType returns whether rules using the given backup type are
+// permitted by the policy.
+func (p *Policy) AllowsBackupType(bt db.BackupType) bool {
+	name := bt.Name()
+
+	if slices.Contains(p.Forbidden, name) {
+		return false
+	}
+
+	return len(p.Allowed) == 0 || slices.Contains(p.Allowed, name)
+}
+
+// ReviewBounds returns the earliest and latest review dates permitted, as of
+// the given time. A zero latest time means that there is no limit.
+//
+// The earliest date is counted from the start of the current (UTC) day, so that
+// a date chosen on a calendar is not rejected for being a few hours early.
+func (p *Policy) ReviewBounds(now time.Time) (time.Time, time.Time) {
+	return bounds(now.Truncate(day), now, p.MinReview, p.MaxReview)
+}
+
+// RemovalBounds returns the earliest and latest removal dates permitted for the
+// given review date. A zero latest time means that there is no limit.
+func (p *Policy) RemovalBounds(review time.Time) (time.Time, time.Time) {
+	return bounds(review, review, p.MinRemoval, p.MaxRemoval)
+}
+
+func bounds(from, to time.Time, minDays, maxDays uint64) (time.Time, time.Time) {
+	earliest := from.Add(time.Duration(minDays) * day) //nolint:gosec
+
+	if maxDays == 0 {
+		return earliest, time.Time{}
+	}
+
+	return earliest, to.Add(time.Duration(maxDays) * day) //nolint:gosec
+}
+
+func (p *Policy) check() error {
+	if p.MaxReview != 0 && p.MinReview > p.MaxReview {
+		return fmt.Errorf("%w: minreview greater than maxreview", ErrInvalidPolicy)
+	}
+
+	if p.MaxRemoval != 0 && p.MinRemoval > p.MaxRemoval {
+		return fmt.Errorf("%w: minremoval greater than maxremoval", ErrInvalidPolicy)
+	}
+
+	for _, name := range slices.Concat(p.Allowed, p.Forbidden) {
+		if _, ok := db.ParseBackupType(name); !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownBackupType, name)
+		}
+	}
+
+	return nil
+}
+
+func (y *yamlConfig) checkPolicies() error {
+	for prefix, policy := range y.Policies {
+		if err := policy.check(); err != nil {
+			return fmt.Errorf("policy for %s: %w", prefix, err)
+		}
+	}
+
+	return nil
+}
+
+// GetPolicy returns the policy that applies to the given directory, which is
+// the one with the longest prefix of the path. If no policy applies, a zero
+// Policy, which permits everything, is returned.
+func (c *Config) GetPolicy(path string) Policy {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var policy Policy
+
+	for prefix, p := range c.yamlConfig.Policies {
+		if strings.HasPrefix(path, prefix) && (policy.Prefix == "" || len(prefix) > len(policy.Prefix)) {
+			policy = p
+			policy.Prefix = prefix
+		}
+	}
+
+	return policy
+}
@@ -0,0 +1,105 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// AuditEntry records an action taken by an admin on a directory claimed by
+// another user.
+type AuditEntry struct {
+	id        int64
+	Directory string
+	Action    string
+	Admin     string
+	Claimant  string
+	Details   string `json:",omitempty"`
+	Created   int64
+}
+
+// ID returns the in SQL ID for the AuditEntry.
+func (a *AuditEntry) ID() int64 {
+	if a == nil {
+		return 0
+	}
+
+	return a.id
+}
+
+// AddAuditEntry records the given AuditEntry in the database.
+func (d *DB) AddAuditEntry(entry *AuditEntry) error {
+	tx, err := d.db.Begin() //nolint:noctx
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if err := addAuditEntry(tx, time.Now().Unix(), entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func addAuditEntry(tx *sql.Tx, now int64, entry *AuditEntry) error {
+	entry.Created = now
+
+	res, err := tx.Exec(createAuditEntry, entry.Directory, entry.Action, //nolint:noctx
+		entry.Admin, entry.Claimant, entry.Details, entry.Created)
+	if err != nil {
+		return err
+	}
+
+	entry.id, err = res.LastInsertId()
+
+	return err
+}
+
+// ReadAuditEntries allows iteration over the AuditEntries stored in the
+// database, oldest first.
+func (d *DBRO) ReadAuditEntries() *IterErr[*AuditEntry] {
+	return iterRows(d, scanAuditEntry, selectAllAuditEntries)
+}
+
+func scanAuditEntry(scanner scanner) (*AuditEntry, error) {
+	entry := new(AuditEntry)
+
+	if err := scanner.Scan(
+		&entry.id,
+		&entry.Directory,
+		&entry.Action,
+		&entry.Admin,
+		&entry.Claimant,
+		&entry.Details,
+		&entry.Created,
+	); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,64 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package db
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAuditLog(t *testing.T) {
+	Convey("With a test database", t, func() {
+		db := createTestDatabase(t)
+
+		Convey("You can record admin actions", func() {
+			entryA := &AuditEntry{
+				Directory: "/some/path/",
+				Action:    "reassign",
+				Admin:     "admin",
+				Claimant:  "userA",
+				Details:   "userB",
+			}
+			entryB := &AuditEntry{
+				Directory: "/some/other/path/",
+				Action:    "unfreeze",
+				Admin:     "admin",
+				Claimant:  "userB",
+			}
+
+			So(db.AddAuditEntry(entryA), ShouldBeNil)
+			So(db.AddAuditEntry(entryB), ShouldBeNil)
+			So(entryA.ID(), ShouldEqual, 1)
+			So(entryB.ID(), ShouldEqual, 2)
+			So(entryA.Created, ShouldBeGreaterThan, 0)
+
+			Convey("…and retrieve them from the DB", func() {
+				So(collectIter(t, db.ReadAuditEntries()), ShouldResemble, []*AuditEntry{entryA, entryB})
+			})
+		})
+	})
+}
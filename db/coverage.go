@@ -0,0 +1,102 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package db
+
+// SetCoverage records the result of comparing the files listed in a manual
+// ibackup set with the files matched by the rule that names it.
+type SetCoverage struct {
+	directoryID int64
+	SetName     string
+	Matched     uint64
+	Missing     uint64
+	Stale       uint64
+	Extra       uint64
+	Checked     int64
+}
+
+// DirID returns the in SQL ID for the Directory the coverage is attached to.
+func (s *SetCoverage) DirID() int64 {
+	if s == nil {
+		return 0
+	}
+
+	return s.directoryID
+}
+
+// SetSetCoverage stores the given coverage results for the given directory,
+// replacing any existing results for the same set.
+func (d *DB) SetSetCoverage(dir *Directory, coverage ...*SetCoverage) error {
+	tx, err := d.db.Begin() //nolint:noctx
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	for _, c := range coverage {
+		if _, err := tx.Exec(deleteSetCoverage, dir.id, c.SetName); err != nil { //nolint:noctx
+			return err
+		}
+
+		if _, err := tx.Exec(createSetCoverage, dir.id, c.SetName, //nolint:noctx
+			c.Matched, c.Missing, c.Stale, c.Extra, c.Checked); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, c := range coverage {
+		c.directoryID = dir.id
+	}
+
+	return nil
+}
+
+// ReadSetCoverage allows iteration over the SetCoverage results stored in the
+// database.
+func (d *DBRO) ReadSetCoverage() *IterErr[*SetCoverage] {
+	return iterRows(d, scanSetCoverage, selectAllSetCoverage)
+}
+
+func scanSetCoverage(scanner scanner) (*SetCoverage, error) {
+	c := new(SetCoverage)
+
+	if err := scanner.Scan(
+		&c.directoryID,
+		&c.SetName,
+		&c.Matched,
+		&c.Missing,
+		&c.Stale,
+		&c.Extra,
+		&c.Checked,
+	); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
@@ -0,0 +1,106 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package db
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSetCoverage(t *testing.T) {
+	Convey("With a test database", t, func() {
+		db := createTestDatabase(t)
+
+		dirA := &Directory{
+			Path:      "/some/path/",
+			ClaimedBy: "me",
+		}
+		dirB := &Directory{
+			Path:      "/some/other/path/",
+			ClaimedBy: "someone",
+		}
+
+		So(db.CreateDirectory(dirA), ShouldBeNil)
+		So(db.CreateDirectory(dirB), ShouldBeNil)
+
+		Convey("You can store set coverage for a directory", func() {
+			covA := &SetCoverage{SetName: "mySet", Matched: 10, Missing: 1, Stale: 2, Extra: 3, Checked: 100}
+			covB := &SetCoverage{SetName: "otherSet", Matched: 5, Checked: 100}
+			covC := &SetCoverage{SetName: "mySet", Matched: 1, Missing: 1, Checked: 200}
+
+			So(db.SetSetCoverage(dirA, covA, covB), ShouldBeNil)
+			So(db.SetSetCoverage(dirB, covC), ShouldBeNil)
+			So(covA.DirID(), ShouldEqual, dirA.ID())
+			So(covC.DirID(), ShouldEqual, dirB.ID())
+
+			Convey("…and retrieve them from the DB", func() {
+				So(collectIter(t, db.ReadSetCoverage()), ShouldResemble, []*SetCoverage{covA, covB, covC})
+			})
+
+			Convey("…and replace them", func() {
+				covD := &SetCoverage{SetName: "mySet", Matched: 10, Checked: 300}
+
+				So(db.SetSetCoverage(dirA, covD), ShouldBeNil)
+				So(collectIter(t, db.ReadSetCoverage()), ShouldResemble, []*SetCoverage{covB, covC, covD})
+			})
+
+			Convey("…and removing a directory removes all of its coverage", func() {
+				So(db.RemoveDirectory(dirA), ShouldBeNil)
+				So(collectIter(t, db.ReadSetCoverage()), ShouldResemble, []*SetCoverage{covC})
+			})
+		})
+
+		Convey("Coverage is removed when no rule names its set", func() {
+			ruleA := &Rule{BackupType: BackupManualIBackup, Metadata: "mySet", Match: "*.txt"}
+			ruleB := &Rule{BackupType: BackupManualIBackup, Metadata: "otherSet", Match: "*.csv"}
+
+			So(db.CreateDirectoryRule(dirA, ruleA, ruleB), ShouldBeNil)
+
+			covA := &SetCoverage{SetName: "mySet", Matched: 10, Checked: 100}
+			covB := &SetCoverage{SetName: "otherSet", Matched: 5, Checked: 100}
+
+			So(db.SetSetCoverage(dirA, covA, covB), ShouldBeNil)
+
+			Convey("…when the rule is removed", func() {
+				So(db.RemoveRule(ruleA), ShouldBeNil)
+				So(collectIter(t, db.ReadSetCoverage()), ShouldResemble, []*SetCoverage{covB})
+			})
+
+			Convey("…when the rule is changed to name another set", func() {
+				ruleB.Metadata = "newSet"
+
+				So(db.UpdateRule(ruleB), ShouldBeNil)
+				So(collectIter(t, db.ReadSetCoverage()), ShouldResemble, []*SetCoverage{covA})
+			})
+
+			Convey("…when the rule is removed as part of a set of changes", func() {
+				So(db.ApplyRuleChanges(&RuleChanges{Remove: []*Rule{ruleA, ruleB}}), ShouldBeNil)
+				So(collectIter(t, db.ReadSetCoverage()), ShouldBeEmpty)
+			})
+		})
+	})
+}
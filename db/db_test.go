@@ -70,7 +70,10 @@ func dropTables(uri string) error {
 		return err
 	}
 
-	for _, table := range [...]string{"rules", "directories"} {
+	for _, table := range [...]string{
+		"reportTotals", "reportSnapshots", "ruleTemplates", "templates", "auditLog", "gitWorkingCopies", "setCoverage",
+		"rules", "directories",
+	} {
 		if _, err = db.Exec("DROP TABLE IF EXISTS `" + table + "`;"); err != nil { //nolint:noctx
 			return err
 		}
@@ -0,0 +1,133 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package db
+
+import "time"
+
+// Kinds of ReportTotal.
+const (
+	TotalRoot  = "root"
+	TotalGroup = "group"
+	TotalBOM   = "bom"
+)
+
+// ReportTotal records the number and size of the files of a backup type, or
+// of unplanned files when BackupType is -1, within a reporting root, or owned
+// by a group or BOM, at the time of a report snapshot.
+//
+// Totals from the same snapshot share a Snapshot ID.
+type ReportTotal struct {
+	Snapshot   int64
+	Kind       string
+	Name       string
+	BackupType int
+	Count      uint64
+	Size       uint64
+	Created    int64
+}
+
+// AddReportSnapshot stores the given totals as a single snapshot, setting their
+// Snapshot ID and their Created time to now.
+func (d *DB) AddReportSnapshot(totals []*ReportTotal) error {
+	tx, err := d.db.Begin() //nolint:noctx
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	now := time.Now().Unix()
+
+	res, err := tx.Exec(createReportSnapshot, now) //nolint:noctx
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	for _, t := range totals {
+		if _, err := tx.Exec(createReportTotal, id, t.Kind, t.Name, t.BackupType, t.Count, t.Size); err != nil { //nolint:noctx,lll
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, t := range totals {
+		t.Snapshot = id
+		t.Created = now
+	}
+
+	return nil
+}
+
+// RemoveReportSnapshots removes the snapshots, along with their totals, taken
+// before the given unix time.
+func (d *DB) RemoveReportSnapshots(before int64) error {
+	tx, err := d.db.Begin() //nolint:noctx
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err := tx.Exec(deleteReportTotals, before); err != nil { //nolint:noctx
+		return err
+	}
+
+	if _, err := tx.Exec(deleteReportSnapshots, before); err != nil { //nolint:noctx
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ReadReportTotals allows iteration over the ReportTotals from the snapshots
+// taken between the given unix times, inclusive, oldest first.
+func (d *DBRO) ReadReportTotals(from, to int64) *IterErr[*ReportTotal] {
+	return iterRows(d, scanReportTotal, selectReportTotals, from, to)
+}
+
+func scanReportTotal(scanner scanner) (*ReportTotal, error) {
+	t := new(ReportTotal)
+
+	if err := scanner.Scan(
+		&t.Snapshot,
+		&t.Kind,
+		&t.Name,
+		&t.BackupType,
+		&t.Count,
+		&t.Size,
+		&t.Created,
+	); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
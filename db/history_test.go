@@ -0,0 +1,80 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package db
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestReportTotals(t *testing.T) {
+	Convey("With a test database", t, func() {
+		db := createTestDatabase(t)
+
+		Convey("You can store report snapshots", func() {
+			start := time.Now().Unix()
+
+			snapshot := []*ReportTotal{
+				{Kind: TotalRoot, Name: "/some/path/", BackupType: -1, Count: 10, Size: 1000},
+				{Kind: TotalRoot, Name: "/some/path/", BackupType: int(BackupIBackup), Count: 5, Size: 500},
+				{Kind: TotalGroup, Name: "groupA", BackupType: -1, Count: 3, Size: 300},
+				{Kind: TotalBOM, Name: "bomA", BackupType: -1, Count: 3, Size: 300},
+			}
+
+			So(db.AddReportSnapshot(snapshot), ShouldBeNil)
+			So(snapshot[0].Created, ShouldBeGreaterThanOrEqualTo, start)
+
+			Convey("…and retrieve them from the DB", func() {
+				So(collectIter(t, db.ReadReportTotals(0, math.MaxInt64)), ShouldResemble, snapshot)
+				So(collectIter(t, db.ReadReportTotals(0, start-1)), ShouldBeEmpty)
+			})
+
+			Convey("…with each snapshot having its own ID, even when taken in the same second", func() {
+				second := []*ReportTotal{{Kind: TotalRoot, Name: "/some/path/", BackupType: -1, Count: 11, Size: 1100}}
+
+				So(db.AddReportSnapshot(second), ShouldBeNil)
+				So(second[0].Snapshot, ShouldNotEqual, snapshot[0].Snapshot)
+				So(collectIter(t, db.ReadReportTotals(0, math.MaxInt64)), ShouldResemble, append(snapshot, second...))
+			})
+
+			Convey("…and remove those taken before a given time", func() {
+				So(db.RemoveReportSnapshots(start), ShouldBeNil)
+				So(collectIter(t, db.ReadReportTotals(0, math.MaxInt64)), ShouldResemble, snapshot)
+
+				So(db.RemoveReportSnapshots(snapshot[0].Created+1), ShouldBeNil)
+				So(collectIter(t, db.ReadReportTotals(0, math.MaxInt64)), ShouldBeEmpty)
+
+				var totals int
+
+				So(db.db.QueryRow("SELECT COUNT(*) FROM `reportTotals`;").Scan(&totals), ShouldBeNil) //nolint:noctx
+				So(totals, ShouldEqual, 0)
+			})
+		})
+	})
+}
@@ -26,6 +26,7 @@
 package db
 
 import (
+	"database/sql"
 	"time"
 )
 
@@ -42,6 +43,38 @@ const (
 	BackupManualNFS
 )
 
+var backupTypeNames = [...]string{ //nolint:gochecknoglobals
+	BackupNone:            "nobackup",
+	BackupIBackup:         "backup",
+	BackupManualIBackup:   "manualibackup",
+	BackupManualGit:       "manualgit",
+	BackupManualUnchecked: "manualunchecked",
+	BackupManualPrefect:   "manualprefect",
+	BackupManualNFS:       "manualnfs",
+}
+
+// Name returns the name of the backup type, as used in the API, or an empty
+// string for an unknown type.
+func (b BackupType) Name() string {
+	if int(b) < len(backupTypeNames) {
+		return backupTypeNames[b]
+	}
+
+	return ""
+}
+
+// ParseBackupType returns the backup type with the given name, and whether the
+// name was recognised.
+func ParseBackupType(name string) (BackupType, bool) {
+	for bt, n := range backupTypeNames {
+		if n == name {
+			return BackupType(bt), true //nolint:gosec
+		}
+	}
+
+	return 0, false
+}
+
 // Rule represents a defined rule.
 type Rule struct {
 	id          int64
@@ -50,6 +83,7 @@ type Rule struct {
 	Metadata    string // requester:name for manual
 	Match       string
 	Override    bool
+	Template    int64 `json:",omitempty"` // ID of the Template the rule came from
 
 	Created, Modified int64
 }
@@ -68,6 +102,16 @@ func (r *Rule) ID() int64 {
 	return r.id
 }
 
+// WithID returns a copy of the Rule with the given ID, so that a rule that has
+// not been stored in the database can be distinguished from others, such as
+// when previewing its effect.
+func (r *Rule) WithID(id int64) *Rule {
+	rule := *r
+	rule.id = id
+
+	return &rule
+}
+
 // DirID returns the in SQL ID for the Directory the rule is attached to.
 func (r *Rule) DirID() int64 {
 	if r == nil {
@@ -78,15 +122,29 @@ func (r *Rule) DirID() int64 {
 }
 
 // CreateDirectoryRule defines the given rule(s) for the given directory.
-func (d *DB) CreateDirectoryRule(dir *Directory, rules ...*Rule) error { //nolint:funlen
+func (d *DB) CreateDirectoryRule(dir *Directory, rules ...*Rule) error {
 	tx, err := d.db.Begin() //nolint:noctx
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback() //nolint:errcheck
 
-	now := time.Now().Unix()
+	if err := createRules(tx, time.Now().Unix(), dir, rules); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		rule.directoryID = dir.id
+	}
+
+	return nil
+}
 
+func createRules(tx *sql.Tx, now int64, dir *Directory, rules []*Rule) error {
 	for _, rule := range rules {
 		rule.Created = now
 		rule.Modified = now
@@ -108,14 +166,14 @@ func (d *DB) CreateDirectoryRule(dir *Directory, rules ...*Rule) error { //nolin
 		if rule.id, err = res.LastInsertId(); err != nil {
 			return err
 		}
-	}
 
-	if err := tx.Commit(); err != nil {
-		return err
-	}
+		if rule.Template == 0 {
+			continue
+		}
 
-	for _, rule := range rules {
-		rule.directoryID = dir.id
+		if _, err = tx.Exec(createRuleTemplate, rule.id, rule.Template); err != nil { //nolint:noctx
+			return err
+		}
 	}
 
 	return nil
@@ -138,6 +196,7 @@ func scanRule(scanner scanner) (*Rule, error) {
 		&rule.Override,
 		&rule.Created,
 		&rule.Modified,
+		&rule.Template,
 	); err != nil {
 		return nil, err
 	}
@@ -145,7 +204,8 @@ func scanRule(scanner scanner) (*Rule, error) {
 	return rule, nil
 }
 
-// UpdateRule will update the data stored for the given Rule(s).
+// UpdateRule will update the data stored for the given Rule(s). A Rule whose
+// Template is 0 is no longer marked as created from a Template.
 func (d *DB) UpdateRule(rules ...*Rule) error {
 	tx, err := d.db.Begin() //nolint:noctx
 	if err != nil {
@@ -153,8 +213,18 @@ func (d *DB) UpdateRule(rules ...*Rule) error {
 	}
 	defer tx.Rollback() //nolint:errcheck
 
-	now := time.Now().Unix()
+	if err := updateRules(tx, time.Now().Unix(), rules); err != nil {
+		return err
+	}
+
+	if err := pruneResults(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
 
+func updateRules(tx *sql.Tx, now int64, rules []*Rule) error {
 	for _, rule := range rules {
 		rule.Modified = now
 
@@ -168,12 +238,114 @@ func (d *DB) UpdateRule(rules ...*Rule) error {
 		); err != nil {
 			return err
 		}
+
+		if rule.Template != 0 {
+			continue
+		}
+
+		if _, err := tx.Exec(deleteRuleTemplate, rule.id); err != nil { //nolint:noctx
+			return err
+		}
 	}
 
-	return tx.Commit()
+	return nil
 }
 
-// RemoveRule will remove the given Rule from the database.
+// RemoveRule will remove the given Rule from the database, along with any
+// manual set coverage or git working copy results that no remaining rule
+// refers to.
 func (d *DB) RemoveRule(rule *Rule) error {
-	return d.exec(deleteRule, rule.id)
+	tx, err := d.db.Begin() //nolint:noctx
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err = tx.Exec(deleteRule, rule.id); err != nil { //nolint:noctx
+		return err
+	}
+
+	if err = pruneResults(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// pruneResults removes stored set coverage for sets that are no longer named
+// by a manualibackup rule on the same directory, and stored working copy
+// results for repos no longer named by a manualgit rule on the same directory.
+func pruneResults(tx *sql.Tx) error {
+	if _, err := tx.Exec(pruneSetCoverage, BackupManualIBackup); err != nil { //nolint:noctx
+		return err
+	}
+
+	_, err := tx.Exec(pruneGitWorkingCopies, BackupManualGit) //nolint:noctx
+
+	return err
+}
+
+// DirectoryRules is a Directory and a list of Rules to be created for it.
+type DirectoryRules struct {
+	Directory *Directory
+	Rules     []*Rule
+}
+
+// RuleChanges is a collection of rules to be created, updated and removed, and
+// audit entries to be recorded, all in a single transaction.
+type RuleChanges struct {
+	Create []DirectoryRules
+	Update []*Rule
+	Remove []*Rule
+	Audit  []*AuditEntry
+}
+
+// ApplyRuleChanges applies all of the given changes in a single transaction;
+// either all of the changes are made, or none are.
+func (d *DB) ApplyRuleChanges(changes *RuleChanges) error { //nolint:funlen
+	tx, err := d.db.Begin() //nolint:noctx
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	now := time.Now().Unix()
+
+	for _, dr := range changes.Create {
+		if err = createRules(tx, now, dr.Directory, dr.Rules); err != nil {
+			return err
+		}
+	}
+
+	if err = updateRules(tx, now, changes.Update); err != nil {
+		return err
+	}
+
+	for _, rule := range changes.Remove {
+		if _, err = tx.Exec(deleteRule, rule.id); err != nil { //nolint:noctx
+			return err
+		}
+	}
+
+	if err = pruneResults(tx); err != nil {
+		return err
+	}
+
+	for _, entry := range changes.Audit {
+		if err = addAuditEntry(tx, now, entry); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, dr := range changes.Create {
+		for _, rule := range dr.Rules {
+			rule.directoryID = dr.Directory.id
+		}
+	}
+
+	return nil
 }
@@ -86,6 +86,49 @@ func TestRules(t *testing.T) {
 				So(db.RemoveDirectory(dirA), ShouldBeNil)
 				So(collectIter(t, db.ReadRules()), ShouldResemble, []*Rule{ruleC})
 			})
+
+			Convey("…and apply many changes at once", func() {
+				ruleD := &Rule{
+					BackupType: BackupNone,
+					Match:      "*.tmp",
+				}
+				ruleE := &Rule{
+					BackupType: BackupNone,
+					Match:      "*.tmp",
+				}
+				entry := &AuditEntry{
+					Directory: dirB.Path,
+					Action:    "bulk",
+					Admin:     "me",
+					Claimant:  "someone",
+				}
+
+				ruleC.BackupType = BackupIBackup
+
+				So(db.ApplyRuleChanges(&RuleChanges{
+					Create: []DirectoryRules{{dirA, []*Rule{ruleD}}, {dirB, []*Rule{ruleE}}},
+					Update: []*Rule{ruleC},
+					Remove: []*Rule{ruleB},
+					Audit:  []*AuditEntry{entry},
+				}), ShouldBeNil)
+				So(ruleD.DirID(), ShouldEqual, dirA.ID())
+				So(ruleE.DirID(), ShouldEqual, dirB.ID())
+				So(collectIter(t, db.ReadRules()), ShouldResemble, []*Rule{ruleA, ruleC, ruleD, ruleE})
+				So(collectIter(t, db.ReadAuditEntries()), ShouldResemble, []*AuditEntry{entry})
+
+				Convey("…but if any change fails, none are made", func() {
+					ruleF := &Rule{
+						BackupType: BackupNone,
+						Match:      "*.log",
+					}
+
+					So(db.ApplyRuleChanges(&RuleChanges{
+						Create: []DirectoryRules{{dirB, []*Rule{ruleF}}, {dirA, []*Rule{{Match: "*.jpg"}}}},
+						Remove: []*Rule{ruleA},
+					}), ShouldNotBeNil)
+					So(collectIter(t, db.ReadRules()), ShouldResemble, []*Rule{ruleA, ruleC, ruleD, ruleE})
+				})
+			})
 		})
 	})
 }
@@ -55,9 +55,87 @@ var tables = [...]string{
 		"UNIQUE(`directoryID`, `matchHash`), " +
 		"FOREIGN KEY(`directoryID`) REFERENCES `directories`(`id`) ON DELETE CASCADE" +
 		");",
+
+	"CREATE TABLE IF NOT EXISTS `setCoverage` (" +
+		"`id` INTEGER PRIMARY KEY " + autoIncrement + ", " +
+		"`directoryID` INTEGER NOT NULL, " +
+		"`setName` TEXT NOT NULL, " +
+		"`setNameHash` " + hashColumnStart + "`setName`" + hashColumnEnd + ", " +
+		"`matched` BIGINT NOT NULL, " +
+		"`missing` BIGINT NOT NULL, " +
+		"`stale` BIGINT NOT NULL, " +
+		"`extra` BIGINT NOT NULL, " +
+		"`checked` BIGINT NOT NULL, " +
+		"UNIQUE(`directoryID`, `setNameHash`), " +
+		"FOREIGN KEY(`directoryID`) REFERENCES `directories`(`id`) ON DELETE CASCADE" +
+		");",
+
+	"CREATE TABLE IF NOT EXISTS `gitWorkingCopies` (" +
+		"`id` INTEGER PRIMARY KEY " + autoIncrement + ", " +
+		"`directoryID` INTEGER NOT NULL, " +
+		"`repo` TEXT NOT NULL, " +
+		"`repoHash` " + hashColumnStart + "`repo`" + hashColumnEnd + ", " +
+		"`path` TEXT NOT NULL, " +
+		"`uncommitted` BIGINT NOT NULL, " +
+		"`untracked` BIGINT NOT NULL, " +
+		"`unpushed` BIGINT NOT NULL, " +
+		"`error` TEXT NOT NULL, " +
+		"`checked` BIGINT NOT NULL, " +
+		"UNIQUE(`directoryID`, `repoHash`), " +
+		"FOREIGN KEY(`directoryID`) REFERENCES `directories`(`id`) ON DELETE CASCADE" +
+		");",
+
+	"CREATE TABLE IF NOT EXISTS `auditLog` (" +
+		"`id` INTEGER PRIMARY KEY " + autoIncrement + ", " +
+		"`directory` TEXT NOT NULL, " +
+		"`action` TEXT NOT NULL, " +
+		"`admin` TEXT NOT NULL, " +
+		"`claimant` TEXT NOT NULL, " +
+		"`details` TEXT NOT NULL, " +
+		"`created` BIGINT NOT NULL" +
+		");",
+
+	"CREATE TABLE IF NOT EXISTS `templates` (" +
+		"`id` INTEGER PRIMARY KEY " + autoIncrement + ", " +
+		"`name` TEXT NOT NULL, " +
+		"`nameHash` " + hashColumnStart + "`name`" + hashColumnEnd + ", " +
+		"`groupName` TEXT NOT NULL, " +
+		"`groupHash` " + hashColumnStart + "`groupName`" + hashColumnEnd + ", " +
+		"`rules` /*! MEDIUMTEXT -- */ TEXT\n/*! */ NOT NULL, " +
+		"`createdBy` TEXT NOT NULL, " +
+		"`created` BIGINT NOT NULL, " +
+		"`modified` BIGINT NOT NULL, " +
+		"UNIQUE(`groupHash`, `nameHash`)" +
+		");",
+
+	"CREATE TABLE IF NOT EXISTS `ruleTemplates` (" +
+		"`ruleID` INTEGER PRIMARY KEY, " +
+		"`templateID` INTEGER NOT NULL, " +
+		"FOREIGN KEY(`ruleID`) REFERENCES `rules`(`id`) ON DELETE CASCADE, " +
+		"FOREIGN KEY(`templateID`) REFERENCES `templates`(`id`) ON DELETE CASCADE" +
+		");",
+
+	"CREATE TABLE IF NOT EXISTS `reportSnapshots` (" +
+		"`id` INTEGER PRIMARY KEY " + autoIncrement + ", " +
+		"`created` BIGINT NOT NULL" +
+		");",
+
+	"CREATE TABLE IF NOT EXISTS `reportTotals` (" +
+		"`id` INTEGER PRIMARY KEY " + autoIncrement + ", " +
+		"`snapshotID` INTEGER NOT NULL, " +
+		"`kind` TEXT NOT NULL, " +
+		"`name` TEXT NOT NULL, " +
+		"`type` INTEGER NOT NULL, " +
+		"`count` BIGINT NOT NULL, " +
+		"`size` BIGINT NOT NULL, " +
+		"FOREIGN KEY(`snapshotID`) REFERENCES `reportSnapshots`(`id`) ON DELETE CASCADE" +
+		");",
 }
 
-var tableNames = [...]string{"directories", "rules"}
+var tableNames = [...]string{
+	"directories", "rules", "setCoverage", "gitWorkingCopies", "auditLog", "templates", "ruleTemplates",
+	"reportSnapshots", "reportTotals",
+}
 
 const (
 	autoIncrement   = "/*! AUTO_INCREMENT -- */ AUTOINCREMENT\n/*! */"
@@ -86,6 +164,23 @@ const (
 	createRule = "INSERT INTO `rules` " +
 		"(`directoryID`, `type`, `metadata`, `match`, `override`, `created`, `modified`) " +
 		"VALUES (?, ?, ?, ?, ?, ?, ?);"
+	createSetCoverage = "INSERT INTO `setCoverage` " +
+		"(`directoryID`, `setName`, `matched`, `missing`, `stale`, `extra`, `checked`) " +
+		"VALUES (?, ?, ?, ?, ?, ?, ?);"
+	createGitWorkingCopy = "INSERT INTO `gitWorkingCopies` " +
+		"(`directoryID`, `repo`, `path`, `uncommitted`, `untracked`, `unpushed`, `error`, `checked`) " +
+		"VALUES (?, ?, ?, ?, ?, ?, ?, ?);"
+	createAuditEntry = "INSERT INTO `auditLog` " +
+		"(`directory`, `action`, `admin`, `claimant`, `details`, `created`) " +
+		"VALUES (?, ?, ?, ?, ?, ?);"
+	createTemplate = "INSERT INTO `templates` " +
+		"(`name`, `groupName`, `rules`, `createdBy`, `created`, `modified`) " +
+		"VALUES (?, ?, ?, ?, ?, ?);"
+	createRuleTemplate   = "INSERT INTO `ruleTemplates` (`ruleID`, `templateID`) VALUES (?, ?);"
+	createReportSnapshot = "INSERT INTO `reportSnapshots` (`created`) VALUES (?);"
+	createReportTotal    = "INSERT INTO `reportTotals` " +
+		"(`snapshotID`, `kind`, `name`, `type`, `count`, `size`) " +
+		"VALUES (?, ?, ?, ?, ?, ?);"
 
 	selectAllDirectories = "SELECT " +
 		"`id`, " +
@@ -100,15 +195,69 @@ const (
 		"`modified` " +
 		"FROM `directories`;"
 	selectAllRules = "SELECT " +
-		"`id`, " +
+		"`rules`.`id`, " +
+		"`rules`.`directoryID`, " +
+		"`rules`.`type`, " +
+		"`rules`.`metadata`, " +
+		"`rules`.`match`, " +
+		"`rules`.`override`, " +
+		"`rules`.`created`, " +
+		"`rules`.`modified`, " +
+		"COALESCE(`ruleTemplates`.`templateID`, 0) " +
+		"FROM `rules` " +
+		"LEFT JOIN `ruleTemplates` ON `ruleTemplates`.`ruleID` = `rules`.`id` " +
+		"ORDER BY `rules`.`id`;"
+	selectAllSetCoverage = "SELECT " +
 		"`directoryID`, " +
-		"`type`, " +
-		"`metadata`, " +
-		"`match`, " +
-		"`override`, " +
+		"`setName`, " +
+		"`matched`, " +
+		"`missing`, " +
+		"`stale`, " +
+		"`extra`, " +
+		"`checked` " +
+		"FROM `setCoverage`;"
+	selectAllGitWorkingCopies = "SELECT " +
+		"`directoryID`, " +
+		"`repo`, " +
+		"`path`, " +
+		"`uncommitted`, " +
+		"`untracked`, " +
+		"`unpushed`, " +
+		"`error`, " +
+		"`checked` " +
+		"FROM `gitWorkingCopies`;"
+	selectAllAuditEntries = "SELECT " +
+		"`id`, " +
+		"`directory`, " +
+		"`action`, " +
+		"`admin`, " +
+		"`claimant`, " +
+		"`details`, " +
+		"`created` " +
+		"FROM `auditLog` " +
+		"ORDER BY `id`;"
+	selectAllTemplates = "SELECT " +
+		"`id`, " +
+		"`name`, " +
+		"`groupName`, " +
+		"`rules`, " +
+		"`createdBy`, " +
 		"`created`, " +
 		"`modified` " +
-		"FROM `rules`;"
+		"FROM `templates` " +
+		"ORDER BY `id`;"
+	selectReportTotals = "SELECT " +
+		"`reportTotals`.`snapshotID`, " +
+		"`reportTotals`.`kind`, " +
+		"`reportTotals`.`name`, " +
+		"`reportTotals`.`type`, " +
+		"`reportTotals`.`count`, " +
+		"`reportTotals`.`size`, " +
+		"`reportSnapshots`.`created` " +
+		"FROM `reportTotals` " +
+		"JOIN `reportSnapshots` ON `reportSnapshots`.`id` = `reportTotals`.`snapshotID` " +
+		"WHERE `reportSnapshots`.`created` >= ? AND `reportSnapshots`.`created` <= ? " +
+		"ORDER BY `reportTotals`.`snapshotID`, `reportTotals`.`id`;"
 
 	updateDirectory = "UPDATE `directories` SET " +
 		"`claimedBy` = ?, " +
@@ -125,8 +274,33 @@ const (
 		"`match` = ?, " +
 		"`modified` = ? " +
 		"WHERE `id` = ?;"
+	updateTemplate = "UPDATE `templates` SET " +
+		"`name` = ?, " +
+		"`rules` = ?, " +
+		"`modified` = ? " +
+		"WHERE `id` = ?;"
 	refreezeDirectory = "UPDATE `directories` SET `melt` = 0 WHERE `id` = ?;"
 
-	deleteDirectory = "DELETE FROM `directories` WHERE `id` = ?;"
-	deleteRule      = "DELETE FROM `rules` WHERE `id` = ?;"
+	deleteDirectory      = "DELETE FROM `directories` WHERE `id` = ?;"
+	deleteRule           = "DELETE FROM `rules` WHERE `id` = ?;"
+	deleteTemplate       = "DELETE FROM `templates` WHERE `id` = ?;"
+	deleteRuleTemplate   = "DELETE FROM `ruleTemplates` WHERE `ruleID` = ?;"
+	deleteSetCoverage    = "DELETE FROM `setCoverage` WHERE `directoryID` = ? AND `setName` = ?;"
+	deleteGitWorkingCopy = "DELETE FROM `gitWorkingCopies` WHERE `directoryID` = ? AND `repo` = ?;"
+	deleteReportTotals   = "DELETE FROM `reportTotals` WHERE `snapshotID` IN (" +
+		"SELECT `id` FROM `reportSnapshots` WHERE `created` < ?" +
+		");"
+	deleteReportSnapshots = "DELETE FROM `reportSnapshots` WHERE `created` < ?;"
+	pruneSetCoverage      = "DELETE FROM `setCoverage` WHERE NOT EXISTS (" +
+		"SELECT 1 FROM `rules` " +
+		"WHERE `rules`.`directoryID` = `setCoverage`.`directoryID` " +
+		"AND `rules`.`type` = ? " +
+		"AND `rules`.`metadata` = `setCoverage`.`setName`" +
+		");"
+	pruneGitWorkingCopies = "DELETE FROM `gitWorkingCopies` WHERE NOT EXISTS (" +
+		"SELECT 1 FROM `rules` " +
+		"WHERE `rules`.`directoryID` = `gitWorkingCopies`.`directoryID` " +
+		"AND `rules`.`type` = ? " +
+		"AND `rules`.`metadata` = `gitWorkingCopies`.`repo`" +
+		");"
 )
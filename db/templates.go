@@ -0,0 +1,157 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package db
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Template is a named list of rules that can be added to a directory in one go.
+//
+// A Template with an empty Group is available to everyone; otherwise, it is
+// available to the members of the Group.
+type Template struct {
+	id        int64
+	Name      string
+	Group     string
+	Rules     []TemplateRule
+	CreatedBy string
+
+	Created, Modified int64
+}
+
+// TemplateRule is the definition of a rule in a Template.
+type TemplateRule struct {
+	BackupType BackupType
+	Metadata   string
+	Match      string
+	Override   bool
+}
+
+// ID returns the in SQL ID for the Template.
+func (t *Template) ID() int64 {
+	if t == nil {
+		return 0
+	}
+
+	return t.id
+}
+
+// NewRules returns a new Rule for each of the TemplateRules, marked as having
+// come from the Template.
+func (t *Template) NewRules() []*Rule {
+	rules := make([]*Rule, len(t.Rules))
+
+	for n, tr := range t.Rules {
+		rules[n] = &Rule{
+			BackupType: tr.BackupType,
+			Metadata:   tr.Metadata,
+			Match:      tr.Match,
+			Override:   tr.Override,
+			Template:   t.id,
+		}
+	}
+
+	return rules
+}
+
+// CreateTemplate adds the given Template to the database.
+func (d *DB) CreateTemplate(t *Template) error {
+	rules, err := json.Marshal(t.Rules)
+	if err != nil {
+		return err
+	}
+
+	tx, err := d.db.Begin() //nolint:noctx
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	t.Created = time.Now().Unix()
+	t.Modified = t.Created
+
+	res, err := tx.Exec(createTemplate, t.Name, t.Group, string(rules), //nolint:noctx
+		t.CreatedBy, t.Created, t.Modified)
+	if err != nil {
+		return err
+	}
+
+	if t.id, err = res.LastInsertId(); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ReadTemplates allows iteration over the Templates stored in the database.
+func (d *DBRO) ReadTemplates() *IterErr[*Template] {
+	return iterRows(d, scanTemplate, selectAllTemplates)
+}
+
+func scanTemplate(scanner scanner) (*Template, error) {
+	var (
+		t     = new(Template)
+		rules []byte
+	)
+
+	if err := scanner.Scan(
+		&t.id,
+		&t.Name,
+		&t.Group,
+		&rules,
+		&t.CreatedBy,
+		&t.Created,
+		&t.Modified,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(rules, &t.Rules); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// UpdateTemplate will update the name and rules stored for the given Template.
+func (d *DB) UpdateTemplate(t *Template) error {
+	rules, err := json.Marshal(t.Rules)
+	if err != nil {
+		return err
+	}
+
+	t.Modified = time.Now().Unix()
+
+	return d.exec(updateTemplate, t.Name, string(rules), t.Modified, t.id)
+}
+
+// RemoveTemplate will remove the given Template from the database. Rules
+// created from the Template are kept, but are no longer marked as such.
+func (d *DB) RemoveTemplate(t *Template) error {
+	return d.exec(deleteTemplate, t.id)
+}
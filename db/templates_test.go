@@ -0,0 +1,112 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package db
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTemplates(t *testing.T) {
+	Convey("With a test database", t, func() {
+		db := createTestDatabase(t)
+
+		templateA := &Template{
+			Name: "Defaults",
+			Rules: []TemplateRule{
+				{BackupType: BackupIBackup, Match: "*.vcf.gz"},
+				{BackupType: BackupNone, Match: "*.tmp"},
+			},
+			CreatedBy: "admin",
+		}
+		templateB := &Template{
+			Name:      "Defaults",
+			Group:     "groupA",
+			Rules:     []TemplateRule{{BackupType: BackupNone, Match: "work/*"}},
+			CreatedBy: "userA",
+		}
+
+		Convey("You can add templates", func() {
+			So(db.CreateTemplate(templateA), ShouldBeNil)
+			So(db.CreateTemplate(templateB), ShouldBeNil)
+			So(db.CreateTemplate(&Template{Name: "Defaults", Group: "groupA"}), ShouldNotBeNil)
+			So(templateA.ID(), ShouldEqual, 1)
+			So(templateB.ID(), ShouldEqual, 2)
+
+			Convey("…and retrieve them from the DB", func() {
+				So(collectIter(t, db.ReadTemplates()), ShouldResemble, []*Template{templateA, templateB})
+			})
+
+			Convey("…and update them", func() {
+				templateA.Name = "Lab Defaults"
+				templateA.Rules = templateA.Rules[1:]
+
+				So(db.UpdateTemplate(templateA), ShouldBeNil)
+				So(collectIter(t, db.ReadTemplates()), ShouldResemble, []*Template{templateA, templateB})
+			})
+
+			Convey("…and create rules from them that remember the template", func() {
+				dir := &Directory{
+					Path:      "/some/path/",
+					ClaimedBy: "me",
+				}
+
+				So(db.CreateDirectory(dir), ShouldBeNil)
+
+				rules := templateA.NewRules()
+				So(len(rules), ShouldEqual, 2)
+				So(rules[0].Match, ShouldEqual, "*.vcf.gz")
+				So(rules[0].Template, ShouldEqual, templateA.ID())
+
+				manual := &Rule{BackupType: BackupIBackup, Match: "*.txt"}
+
+				So(db.CreateDirectoryRule(dir, append(rules, manual)...), ShouldBeNil)
+				So(collectIter(t, db.ReadRules()), ShouldResemble, append(rules, manual))
+
+				Convey("…which are forgotten when the template is removed", func() {
+					So(db.RemoveTemplate(templateA), ShouldBeNil)
+					So(collectIter(t, db.ReadTemplates()), ShouldResemble, []*Template{templateB})
+
+					for _, rule := range rules {
+						rule.Template = 0
+					}
+
+					So(collectIter(t, db.ReadRules()), ShouldResemble, append(rules, manual))
+				})
+
+				Convey("…which is forgotten when an update clears it", func() {
+					rules[0].Template = 0
+					rules[0].BackupType = BackupNone
+
+					So(db.UpdateRule(rules...), ShouldBeNil)
+					So(collectIter(t, db.ReadRules()), ShouldResemble, append(rules, manual))
+					So(rules[1].Template, ShouldEqual, templateA.ID())
+				})
+			})
+		})
+	})
+}
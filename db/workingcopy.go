@@ -0,0 +1,108 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package db
+
+// GitWorkingCopy records the result of checking a local git working copy, in
+// or below a directory, against the remote repo named by a manualgit rule.
+//
+// If the check failed, Error will contain the reason and the counts will be
+// zero.
+type GitWorkingCopy struct {
+	directoryID int64
+	Repo        string
+	Path        string
+	Uncommitted uint64
+	Untracked   uint64
+	Unpushed    uint64
+	Error       string
+	Checked     int64
+}
+
+// DirID returns the in SQL ID for the Directory the working copy is attached
+// to.
+func (g *GitWorkingCopy) DirID() int64 {
+	if g == nil {
+		return 0
+	}
+
+	return g.directoryID
+}
+
+// SetGitWorkingCopy stores the given working copy results for the given
+// directory, replacing any existing results for the same repo.
+func (d *DB) SetGitWorkingCopy(dir *Directory, workingCopies ...*GitWorkingCopy) error {
+	tx, err := d.db.Begin() //nolint:noctx
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	for _, g := range workingCopies {
+		if _, err := tx.Exec(deleteGitWorkingCopy, dir.id, g.Repo); err != nil { //nolint:noctx
+			return err
+		}
+
+		if _, err := tx.Exec(createGitWorkingCopy, dir.id, g.Repo, g.Path, //nolint:noctx
+			g.Uncommitted, g.Untracked, g.Unpushed, g.Error, g.Checked); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, g := range workingCopies {
+		g.directoryID = dir.id
+	}
+
+	return nil
+}
+
+// ReadGitWorkingCopies allows iteration over the GitWorkingCopy results stored
+// in the database.
+func (d *DBRO) ReadGitWorkingCopies() *IterErr[*GitWorkingCopy] {
+	return iterRows(d, scanGitWorkingCopy, selectAllGitWorkingCopies)
+}
+
+func scanGitWorkingCopy(scanner scanner) (*GitWorkingCopy, error) {
+	g := new(GitWorkingCopy)
+
+	if err := scanner.Scan(
+		&g.directoryID,
+		&g.Repo,
+		&g.Path,
+		&g.Uncommitted,
+		&g.Untracked,
+		&g.Unpushed,
+		&g.Error,
+		&g.Checked,
+	); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
@@ -0,0 +1,109 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package db
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGitWorkingCopy(t *testing.T) {
+	Convey("With a test database", t, func() {
+		db := createTestDatabase(t)
+
+		dirA := &Directory{
+			Path:      "/some/path/",
+			ClaimedBy: "me",
+		}
+		dirB := &Directory{
+			Path:      "/some/other/path/",
+			ClaimedBy: "someone",
+		}
+
+		So(db.CreateDirectory(dirA), ShouldBeNil)
+		So(db.CreateDirectory(dirB), ShouldBeNil)
+
+		Convey("You can store working copy results for a directory", func() {
+			wcA := &GitWorkingCopy{
+				Repo: "https://a/repo", Path: "/some/path/",
+				Uncommitted: 1, Untracked: 2, Unpushed: 3, Checked: 100,
+			}
+			wcB := &GitWorkingCopy{Repo: "https://b/repo", Error: "repository does not exist", Checked: 100}
+			wcC := &GitWorkingCopy{Repo: "https://a/repo", Path: "/some/other/path/repo", Unpushed: 1, Checked: 200}
+
+			So(db.SetGitWorkingCopy(dirA, wcA, wcB), ShouldBeNil)
+			So(db.SetGitWorkingCopy(dirB, wcC), ShouldBeNil)
+			So(wcA.DirID(), ShouldEqual, dirA.ID())
+			So(wcC.DirID(), ShouldEqual, dirB.ID())
+
+			Convey("…and retrieve them from the DB", func() {
+				So(collectIter(t, db.ReadGitWorkingCopies()), ShouldResemble, []*GitWorkingCopy{wcA, wcB, wcC})
+			})
+
+			Convey("…and replace them", func() {
+				wcD := &GitWorkingCopy{Repo: "https://a/repo", Path: "/some/path/", Checked: 300}
+
+				So(db.SetGitWorkingCopy(dirA, wcD), ShouldBeNil)
+				So(collectIter(t, db.ReadGitWorkingCopies()), ShouldResemble, []*GitWorkingCopy{wcB, wcC, wcD})
+			})
+
+			Convey("…and removing a directory removes all of its results", func() {
+				So(db.RemoveDirectory(dirA), ShouldBeNil)
+				So(collectIter(t, db.ReadGitWorkingCopies()), ShouldResemble, []*GitWorkingCopy{wcC})
+			})
+		})
+
+		Convey("Working copy results are removed when no rule names their repo", func() {
+			ruleA := &Rule{BackupType: BackupManualGit, Metadata: "https://a/repo", Match: "*"}
+			ruleB := &Rule{BackupType: BackupManualGit, Metadata: "https://b/repo", Match: "*.txt"}
+
+			So(db.CreateDirectoryRule(dirA, ruleA, ruleB), ShouldBeNil)
+
+			wcA := &GitWorkingCopy{Repo: "https://a/repo", Path: "/some/path/", Checked: 100}
+			wcB := &GitWorkingCopy{Repo: "https://b/repo", Path: "/some/path/", Checked: 100}
+
+			So(db.SetGitWorkingCopy(dirA, wcA, wcB), ShouldBeNil)
+
+			Convey("…when the rule is removed", func() {
+				So(db.RemoveRule(ruleA), ShouldBeNil)
+				So(collectIter(t, db.ReadGitWorkingCopies()), ShouldResemble, []*GitWorkingCopy{wcB})
+			})
+
+			Convey("…when the rule is changed to name another repo", func() {
+				ruleB.Metadata = "https://c/repo"
+
+				So(db.UpdateRule(ruleB), ShouldBeNil)
+				So(collectIter(t, db.ReadGitWorkingCopies()), ShouldResemble, []*GitWorkingCopy{wcA})
+			})
+
+			Convey("…when the rule is removed as part of a set of changes", func() {
+				So(db.ApplyRuleChanges(&RuleChanges{Remove: []*Rule{ruleA, ruleB}}), ShouldBeNil)
+				So(collectIter(t, db.ReadGitWorkingCopies()), ShouldBeEmpty)
+			})
+		})
+	})
+}
@@ -1,6 +1,6 @@
 import { div, h2, p, button, table, thead, tbody, th, td, tr, fieldset, legend, input, datalist, option } from "./lib/html.js";
 import { getClaimStats, user } from "./rpc.js";
-import { formatBytes, longAgoStr, createSpinner } from "./lib/utils.js";
+import { formatBytes, longAgoStr, createSpinner, workingCopyWarnings, branchDates, isStale, sourceLastMod, setCoverage, incompleteCoverage } from "./lib/utils.js";
 import { BackupType, ibackupStatusColumns } from "./consts.js";
 import { load } from './load.js';
 import { amendNode, clearNode } from "./lib/dom.js";
@@ -142,7 +142,7 @@ function getStatusTd(lastMod: number, row: row) {
     const tooltip = [
         `Last Modified: ${lastMod === 0 ? "None" : new Date(lastMod * 1000).toLocaleString()}`,
         `Last Backup: ${sba.LastSuccess === "0001-01-01T00:00:00Z" ? "None" : new Date(sba.LastSuccess).toLocaleString()}`
-    ];
+    ].concat(sourceLastMod(sba), sba.Error ? [`Error: ${sba.Error}`] : [], setCoverage(sba), workingCopyWarnings(sba), branchDates(sba));
 
     return [
         sba.LastSuccess === "0001-01-01T00:00:00Z" ?
@@ -155,7 +155,7 @@ function getStatusTd(lastMod: number, row: row) {
             ] : [
                 td(longAgoStr(sba.LastSuccess)),
                 sba.Failures === -1 ? td({ "class": "tooltip status", "data-tooltip": tooltip.join("\n") || false },
-                    +new Date(lastMod * 1000) > +new Date(sba.LastSuccess) ? svg(use({ "href": "#crossIcon" })) : svg(use({ "href": "#tickIcon" }))
+                    isStale(lastMod, sba) || workingCopyWarnings(sba).length ? svg(use({ "href": "#crossIcon" })) : svg(use({ "href": "#tickIcon" }))
                 ) : td({
                     "class": "tooltip status",
                     "data-tooltip": (
@@ -164,7 +164,7 @@ function getStatusTd(lastMod: number, row: row) {
                             .map(c => `${c}: ${sba[c].toLocaleString()}`))
                             .join("\n") || false
                     )
-                }, sba.Failures > 0 ? svg(use({ "href": "#crossIcon" })) : svg(use({ "href": "#tickIcon" }))
+                }, sba.Failures > 0 || incompleteCoverage(sba) ? svg(use({ "href": "#crossIcon" })) : svg(use({ "href": "#tickIcon" }))
                 )
             ]
     ]
@@ -1,4 +1,5 @@
 import { BackupType } from "../consts.js";
+import type { SetBackupActivity } from "../types.js";
 import type { Children } from "./dom.js";
 import { button, dialog, div, wbr } from "./html.js";
 
@@ -94,8 +95,51 @@ export const formatBytes = (size: bigint) => {
 		}
 	};
 
+export const setCoverage = (sba: SetBackupActivity) => {
+	const c = sba.Coverage;
+
+	if (!c) {
+		return [];
+	}
+
+	return [`Set Coverage: ${c.Percent.toFixed(1)}% of ${c.Matched.toLocaleString()} files (checked ${new Date(c.Checked).toLocaleString()})`]
+		.concat(([
+			[c.Missing, "missing from the set"],
+			[c.Stale, "modified since upload"],
+			[c.Extra, "in the set but not matched"]
+		] as const).filter(([n]) => n).map(([n, desc]) => `${n.toLocaleString()} files ${desc}`));
+};
+
+export const incompleteCoverage = (sba: SetBackupActivity) => (sba.Coverage?.Percent ?? 100) < 100;
+
 export function createSpinner(): HTMLElement {
 	const spinner = document.createElement("div");
 	spinner.className = "spinner";
 	return spinner;
-};
\ No newline at end of file
+};
+
+export const workingCopyWarnings = (sba: SetBackupActivity) => {
+	const wc = sba.WorkingCopy;
+
+	if (!wc) {
+		return [];
+	}
+
+	if (wc.Error) {
+		return [`Working copy: ${wc.Error}`];
+	}
+
+	return ([
+		[wc.Uncommitted, "uncommitted changes"],
+		[wc.Untracked, "untracked files"],
+		[wc.Unpushed, "unpushed commits"]
+	] as const).filter(([n]) => n).map(([n, desc]) => `git backup rule, but ${n.toLocaleString()} ${desc}`);
+};
+
+export const isStale = (lastMod: number, sba: SetBackupActivity) => sba.Stale ?? new Date(lastMod * 1000) > new Date(sba.LastSuccess);
+
+export const sourceLastMod = (sba: SetBackupActivity) => sba.SourceLastMod ? [`Newest Source File: ${new Date(sba.SourceLastMod).toLocaleString()}`] : [];
+
+export const branchDates = (sba: SetBackupActivity) => Object.entries(sba.Branches ?? {})
+	.sort(([, a], [, b]) => +new Date(b) - +new Date(a))
+	.map(([branch, date]) => `${branch}: ${new Date(date).toLocaleString()}`);
@@ -3,7 +3,7 @@ import type { Children } from "./lib/dom.js";
 import { amendNode } from "./lib/dom.js";
 import { a, br, button, datalist, details, div, fieldset, h1, h2, input, label, legend, li, option, span, summary, table, tbody, td, th, thead, tr, ul } from "./lib/html.js";
 import { svg, title, use } from "./lib/svg.js";
-import { action, formatBytes, longAgo, longAgoStr, secondsInWeek, setAndReturn, splitLongPath, stringSort, createSpinner } from "./lib/utils.js";
+import { action, formatBytes, longAgo, longAgoStr, secondsInWeek, setAndReturn, splitLongPath, stringSort, createSpinner, workingCopyWarnings, branchDates, isStale, sourceLastMod } from "./lib/utils.js";
 import { getReportSummary } from "./rpc.js";
 import { BackupType, MainProgrammes, ibackupStatusColumns } from "./consts.js";
 import { render } from "./disktree.js";
@@ -299,14 +299,14 @@ function getStatus(latestMTime: number, backup: SetBackupActivity) {
 		backup.LastSuccess === "0001-01-01T00:00:00Z" ?
 			backup.Failures === -1 ? [
 				td("None"),
-				td({ "class": "status" }, svg(use({ "href": "#crossIcon" })))
+				td({ "class": backup.Error ? "tooltip status" : "status", "data-tooltip": backup.Error || false }, svg(use({ "href": "#crossIcon" })))
 			] : [
 				td("Pending"),
 				td("-")
 			] : [
 				td(longAgoStr(backup.LastSuccess)),
-				backup.Failures === -1 ? td({ "class": "status" },
-					new Date(latestMTime * 1000) > new Date(backup.LastSuccess) ? svg(use({ "href": "#crossIcon" })) : svg(use({ "href": "#tickIcon" }))
+				backup.Failures === -1 ? td({ "class": "tooltip status", "data-tooltip": sourceLastMod(backup).concat(workingCopyWarnings(backup), branchDates(backup)).join("\n") || false },
+					isStale(latestMTime, backup) || workingCopyWarnings(backup).length ? svg(use({ "href": "#crossIcon" })) : svg(use({ "href": "#tickIcon" }))
 				) : td({
 					"class": "tooltip status",
 					"data-tooltip":
@@ -34,6 +34,19 @@ export type Tree = DirSummary & dirDetails & {
 	Rules: Rules;
 	Unauthorised: string[];
 	CanClaim: boolean;
+	Policy?: Policy;
+};
+
+export type Policy = {
+	Prefix: string;
+	Frequency: number;
+	MinReview: number;
+	MaxReview: number;
+	MinRemoval: number;
+	MaxRemoval: number;
+	Allowed: string[] | null;
+	Forbidden: string[] | null;
+	NoOverrides: boolean;
 };
 
 export type DirectoryRules = {
@@ -46,6 +59,17 @@ export type Rule = {
 	Metadata: string;
 	Match: string;
 	Override: boolean;
+	Template?: number;
+};
+
+export type Template = {
+	ID: number;
+	Name: string;
+	Group: string;
+	Rules: Omit<Rule, "Template">[];
+	CreatedBy: string;
+	Created: number;
+	Modified: number;
 };
 
 export type dirDetails = {
@@ -116,6 +140,30 @@ export type SetBackupActivity = {
 	Orphaned: number;
 	Hardlinks: number;
 	Skipped: number;
+	Coverage?: SetCoverage;
+	WorkingCopy?: GitWorkingCopy;
+	Branches?: Record<string, string>;
+	Error?: string;
+	SourceLastMod?: string;
+	Stale?: boolean;
+};
+
+export type SetCoverage = {
+	Checked: string;
+	Matched: number;
+	Missing: number;
+	Stale: number;
+	Extra: number;
+	Percent: number;
+};
+
+export type GitWorkingCopy = {
+	Checked: string;
+	Path: string;
+	Uncommitted: number;
+	Untracked: number;
+	Unpushed: number;
+	Error?: string;
 };
 
 export type UserGroups = {
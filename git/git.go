@@ -26,105 +26,494 @@
 package git
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
 	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
 	"github.com/go-git/go-git/v6/plumbing/protocol/packp"
 	"github.com/go-git/go-git/v6/plumbing/transport"
 	transporthttp "github.com/go-git/go-git/v6/plumbing/transport/http"
+	"github.com/go-git/go-git/v6/plumbing/transport/ssh"
 	"github.com/go-git/go-git/v6/storage/memory"
 	"github.com/wtsi-hgi/activecache"
 )
 
-// GetLatestCommitDate returns the commit date for the HEAD of the supplied
-// repo.
+const defaultTokenUsername = "oauth2"
+
+var (
+	ErrAuth     = errors.New("authentication failed")
+	ErrNotFound = errors.New("repository not found")
+	ErrNetwork  = errors.New("network error")
+)
+
+// Credentials contains the authentication details used to access remote
+// repositories.
+//
+// HTTPTokens is keyed by host name, and the token will be used for HTTP(S)
+// basic authentication to that host.
+//
+// SSHKey is the path to a private key file that will be used for SSH remotes,
+// including scp-like git@host:path URLs. KnownHosts is the path to a
+// known_hosts file that will be used to verify the SSH hosts; if blank, the
+// default known_hosts locations will be used.
+type Credentials struct {
+	HTTPTokens map[string]HTTPToken
+	SSHKey     string
+	KnownHosts string
+}
+
+// HTTPToken contains the path to a file containing an access token, which is
+// read each time the token is used, and an optional username to send with it,
+// which defaults to "oauth2".
+type HTTPToken struct {
+	Username  string
+	TokenFile string
+}
+
+// GetLatestCommitDate returns the latest commit date of the branches of the
+// supplied repo, accessing it anonymously.
 func GetLatestCommitDate(url string) (time.Time, error) {
-	repo, err := getRepo(url)
+	return (*Credentials)(nil).GetLatestCommitDate(url)
+}
+
+// GetLatestCommitDate returns the latest commit date of the branches of the
+// supplied repo, authenticating with these credentials if any match the repo
+// URL.
+//
+// Errors caused by failed authentication, missing repos, or network issues
+// will wrap ErrAuth, ErrNotFound, or ErrNetwork respectively.
+func (c *Credentials) GetLatestCommitDate(url string) (time.Time, error) {
+	status, err := c.GetRepoStatus(url)
 	if err != nil {
 		return time.Time{}, err
 	}
 
-	refs, err := repo.References()
+	return status.Latest, nil
+}
+
+// RepoStatus contains the commit date of the tip of each branch of a remote
+// repo, keyed by branch name, and the latest of those dates.
+//
+// A RepoStatus should not be modified.
+type RepoStatus struct {
+	Latest   time.Time
+	Branches map[string]time.Time
+
+	hashes map[string]plumbing.Hash
+}
+
+func (r *RepoStatus) dates() map[plumbing.Hash]time.Time {
+	if r == nil {
+		return nil
+	}
+
+	dates := make(map[plumbing.Hash]time.Time, len(r.hashes))
+
+	for branch, hash := range r.hashes {
+		dates[hash] = r.Branches[branch]
+	}
+
+	return dates
+}
+
+// GetRepoStatus returns the commit dates of the branches of the supplied repo,
+// authenticating with these credentials if any match the repo URL.
+//
+// Errors are classified as for GetLatestCommitDate.
+func (c *Credentials) GetRepoStatus(url string) (*RepoStatus, error) {
+	return c.updateRepoStatus(url, nil)
+}
+
+// updateRepoStatus lists the refs advertised by the given repo and only
+// fetches the commits for branches whose hash has no date in the previous
+// status.
+func (c *Credentials) updateRepoStatus(url string, previous *RepoStatus) (*RepoStatus, error) {
+	r, err := c.newRemote(url)
 	if err != nil {
-		return time.Time{}, err
+		return nil, err
 	}
 
-	var latestCommitDate time.Time
+	refs, err := r.list()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &RepoStatus{
+		Branches: make(map[string]time.Time),
+		hashes:   make(map[string]plumbing.Hash),
+	}
+	known := previous.dates()
 
-	err = refs.ForEach(func(r *plumbing.Reference) error {
-		if r.Type() == plumbing.SymbolicReference {
-			return nil
+	var toFetch []*plumbing.Reference
+
+	for _, ref := range refs {
+		if !ref.Name().IsBranch() {
+			continue
 		}
 
-		commit, errr := repo.CommitObject(r.Hash())
-		if errr != nil {
-			return errr
+		branch := ref.Name().Short()
+		status.hashes[branch] = ref.Hash()
+
+		if when, ok := known[ref.Hash()]; ok {
+			status.Branches[branch] = when
+		} else {
+			toFetch = append(toFetch, ref)
 		}
+	}
+
+	if err := r.fetchDates(toFetch, status); err != nil {
+		return nil, err
+	}
 
-		if commit.Author.When.After(latestCommitDate) {
-			latestCommitDate = commit.Author.When
+	for _, when := range status.Branches {
+		if when.After(status.Latest) {
+			status.Latest = when
 		}
+	}
+
+	return status, nil
+}
+
+type remote struct {
+	*git.Remote
+	storage *memory.Storage
+	auth    transport.AuthMethod
+}
+
+func (c *Credentials) newRemote(url string) (*remote, error) {
+	auth, err := c.auth(url)
+	if err != nil {
+		return nil, err
+	}
+
+	storage := memory.NewStorage()
 
+	return &remote{
+		Remote: git.NewRemote(storage, &config.RemoteConfig{
+			Name: "origin",
+			URLs: []string{url},
+		}),
+		storage: storage,
+		auth:    auth,
+	}, nil
+}
+
+func (r *remote) list() ([]*plumbing.Reference, error) {
+	refs, err := r.List(&git.ListOptions{Auth: r.auth})
+	if err != nil {
+		return nil, classifyError(err)
+	}
+
+	return refs, nil
+}
+
+// fetchDates fetches only the tip commits of the given refs, and sets their
+// dates in the given status.
+func (r *remote) fetchDates(refs []*plumbing.Reference, status *RepoStatus) error {
+	if len(refs) == 0 {
 		return nil
-	})
+	}
+
+	specs := make([]config.RefSpec, len(refs))
 
-	return latestCommitDate, err
+	for n, ref := range refs {
+		specs[n] = config.RefSpec("+" + ref.Name() + ":" + ref.Name())
+	}
+
+	if err := r.fetch(specs); err != nil {
+		return err
+	}
+
+	for _, ref := range refs {
+		commit, err := object.GetCommit(r.storage, ref.Hash())
+		if err != nil {
+			return err
+		}
+
+		status.Branches[ref.Name().Short()] = commit.Author.When
+	}
+
+	return nil
 }
 
-func getRepo(url string) (*git.Repository, error) {
-	repo, err := git.Clone(memory.NewStorage(), nil, &git.CloneOptions{
-		URL:    url,
-		Depth:  1,
-		Bare:   true,
-		Filter: packp.FilterBlobNone(),
+func (r *remote) fetch(specs []config.RefSpec) error {
+	err := r.Fetch(&git.FetchOptions{
+		RefSpecs: specs,
+		Auth:     r.auth,
+		Depth:    1,
+		Tags:     plumbing.NoTags,
+		Filter:   packp.FilterTreeDepth(0),
 	})
 	if errors.Is(err, transport.ErrFilterNotSupported) {
-		repo, err = git.Clone(memory.NewStorage(), nil, &git.CloneOptions{
-			URL:   url,
-			Depth: 1,
-			Bare:  true,
+		err = r.Fetch(&git.FetchOptions{
+			RefSpecs: specs,
+			Auth:     r.auth,
+			Depth:    1,
+			Tags:     plumbing.NoTags,
 		})
 	}
 
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil
+	}
+
+	if err != nil {
+		return classifyError(err)
+	}
+
+	return nil
+}
+
+func (c *Credentials) auth(url string) (transport.AuthMethod, error) { //nolint:ireturn
+	if c == nil {
+		return nil, nil //nolint:nilnil
+	}
+
+	ep, err := transport.NewEndpoint(url)
+	if err != nil {
+		return nil, err
+	}
+
+	switch ep.Scheme {
+	case "http", "https":
+		return c.httpAuth(ep.Hostname())
+	case "ssh":
+		return c.sshAuth(ep.User.Username())
+	}
+
+	return nil, nil //nolint:nilnil
+}
+
+func (c *Credentials) httpAuth(host string) (transport.AuthMethod, error) { //nolint:ireturn
+	token, ok := c.HTTPTokens[host]
+	if !ok {
+		return nil, nil //nolint:nilnil
+	}
+
+	data, err := os.ReadFile(token.TokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAuth, err)
+	}
+
+	username := token.Username
+	if username == "" {
+		username = defaultTokenUsername
+	}
+
+	return &transporthttp.BasicAuth{
+		Username: username,
+		Password: strings.TrimSpace(string(data)),
+	}, nil
+}
+
+func (c *Credentials) sshAuth(user string) (transport.AuthMethod, error) { //nolint:ireturn
+	if c.SSHKey == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	if user == "" {
+		user = ssh.DefaultUsername
+	}
+
+	keys, err := ssh.NewPublicKeysFromFile(user, c.SSHKey, "")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAuth, err)
+	}
+
+	if c.KnownHosts != "" {
+		if keys.HostKeyCallback, err = ssh.NewKnownHostsCallback(c.KnownHosts); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrAuth, err)
+		}
+	}
+
+	return keys, nil
+}
+
+// Check returns an error for each configured token file, SSH key and
+// known_hosts file that cannot be read or parsed.
+func (c *Credentials) Check() []error {
+	var errs []error
+
+	for _, host := range slices.Sorted(maps.Keys(c.HTTPTokens)) {
+		if _, err := c.httpAuth(host); err != nil {
+			errs = append(errs, fmt.Errorf("token for %s: %w", host, err))
+		}
+	}
+
+	if _, err := c.sshAuth(""); err != nil {
+		errs = append(errs, fmt.Errorf("ssh key: %w", err))
+	}
+
+	return errs
+}
+
+func classifyError(err error) error {
 	var httperr *transporthttp.Err
 
 	if errors.As(err, &httperr) {
 		httperr.Reason = ""
-		err = httperr
 	}
 
-	if err != nil {
-		return nil, err
+	switch {
+	case errors.Is(err, transport.ErrAuthenticationRequired),
+		errors.Is(err, transport.ErrAuthorizationFailed),
+		isSSHAuthError(err):
+		return fmt.Errorf("%w: %w", ErrAuth, err)
+	case errors.Is(err, transport.ErrRepositoryNotFound):
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case isNetworkError(err):
+		return fmt.Errorf("%w: %w", ErrNetwork, err)
 	}
 
-	return repo, nil
+	return err
+}
+
+func isSSHAuthError(err error) bool {
+	msg := err.Error()
+
+	return strings.Contains(msg, "ssh: unable to authenticate") || strings.Contains(msg, "knownhosts: ")
+}
+
+func isNetworkError(err error) bool {
+	var netErr net.Error
+
+	return errors.As(err, &netErr) || errors.Is(err, transport.ErrTimeoutExceeded)
 }
 
-// Cache wraps the GetLatestCommitDate method, caching, and on a schedule
+// Cache wraps the GetRepoStatus method, caching, and on a schedule
 // re-retrieving, requested repo information.
+//
+// On each re-retrieval, only the refs of a repo are listed; commits are only
+// fetched for branches whose hash has changed since the previous retrieval.
+//
+// Repos that are not requested for an entire re-retrieval period are dropped
+// from the cache.
 type Cache struct {
-	cache *activecache.Cache[string, time.Time]
+	credentials func() *Credentials
+	cache       *activecache.Cache[string, *RepoStatus]
+	stop        func()
+
+	mu        sync.Mutex
+	statuses  map[string]*RepoStatus
+	requested map[string]bool
 }
 
-// NewCache creates a cache storing the last commit date for git repos, that
+// NewCache creates a cache storing the branch commit dates for git repos, that
 // will re-retrieve commit information on a timeout specified by the given
 // Duration.
 //
+// The given credentials func will be called on each retrieval to get the
+// current credentials; it may be nil, in which case repos will be accessed
+// anonymously.
+//
 // The Stop() method must be before replacing (or otherwise losing this pointer
 // to) this cache.
-func NewCache(d time.Duration) *Cache {
-	return &Cache{activecache.New(d, GetLatestCommitDate)}
+func NewCache(d time.Duration, credentials func() *Credentials) *Cache {
+	ctx, stop := context.WithCancel(context.Background())
+
+	c := &Cache{
+		credentials: credentials,
+		stop:        stop,
+		statuses:    make(map[string]*RepoStatus),
+		requested:   make(map[string]bool),
+	}
+	c.cache = activecache.New(d, c.getRepoStatus)
+
+	if d > 0 {
+		go c.runPrune(ctx, d)
+	}
+
+	return c
 }
 
-// GetLatestCommitDate retrieves a cache using the given path, and then calls the
-// normal GetBackupActivity method.
+func (c *Cache) runPrune(ctx context.Context, d time.Duration) {
+	ticker := time.NewTicker(d)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.prune()
+		}
+	}
+}
+
+// prune drops the repos that have not been requested since the previous prune.
+func (c *Cache) prune() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for repo, requested := range c.requested {
+		if requested {
+			c.requested[repo] = false
+
+			continue
+		}
+
+		delete(c.requested, repo)
+		delete(c.statuses, repo)
+		c.cache.Remove(repo)
+	}
+}
+
+func (c *Cache) getRepoStatus(repo string) (*RepoStatus, error) {
+	var creds *Credentials
+
+	if c.credentials != nil {
+		creds = c.credentials()
+	}
+
+	c.mu.Lock()
+	previous := c.statuses[repo]
+	c.mu.Unlock()
+
+	status, err := creds.updateRepoStatus(repo, previous)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.statuses[repo] = status
+	c.mu.Unlock()
+
+	return status, nil
+}
+
+// GetLatestCommitDate retrieves the cached status of the given repo, and returns
+// the latest commit date of its branches.
 func (c *Cache) GetLatestCommitDate(repo string) (time.Time, error) {
+	status, err := c.GetRepoStatus(repo)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return status.Latest, nil
+}
+
+// GetRepoStatus retrieves the cached status of the given repo.
+func (c *Cache) GetRepoStatus(repo string) (*RepoStatus, error) {
+	c.mu.Lock()
+	c.requested[repo] = true
+	c.mu.Unlock()
+
 	return c.cache.Get(repo)
 }
 
 // Stop stops the concurrent retrieval of backup statuses.
 func (c *Cache) Stop() {
+	c.stop()
 	c.cache.Stop()
 }
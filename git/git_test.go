@@ -28,7 +28,11 @@ package git
 import (
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -61,6 +65,8 @@ func TestGetLatestCommitDate(t *testing.T) {
 
 		var mux http.ServeMux
 
+		worktrees := make(map[string]*git.Worktree)
+
 		for repo, commits := range repos {
 			r, err := git.Init(memory.NewStorage(), git.WithWorkTree(memfs.New()))
 			So(err, ShouldBeNil)
@@ -85,9 +91,13 @@ func TestGetLatestCommitDate(t *testing.T) {
 
 			So(wt.Checkout(&git.CheckoutOptions{Branch: "refs/heads/master"}), ShouldBeNil)
 
+			worktrees[repo] = wt
+
 			mux.Handle("/"+repo+"/", githttp.NewBackend(&loader{r.Storer}))
 		}
 
+		mux.Handle("/private/", http.StripPrefix("/private", requireToken(&mux, "secret")))
+
 		server := httptest.NewServer(&mux)
 
 		Convey("You can query latest commit times", func() {
@@ -102,6 +112,127 @@ func TestGetLatestCommitDate(t *testing.T) {
 				So(commitTime, ShouldEqual, latest)
 			}
 		})
+
+		Convey("You can get per-branch dates, only fetching commits for changed branches", func() {
+			var fetches int
+
+			counting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "git-upload-pack") {
+					fetches++
+				}
+
+				mux.ServeHTTP(w, r)
+			}))
+
+			Reset(counting.Close)
+
+			var creds *Credentials
+
+			status, err := creds.updateRepoStatus(counting.URL+"/B/", nil)
+			So(err, ShouldBeNil)
+			So(status.Latest, ShouldEqual, repos["B"][1])
+			So(len(status.Branches), ShouldEqual, 2)
+			So(status.Branches["master"], ShouldEqual, repos["B"][1])
+			So(status.Branches["old"], ShouldEqual, old)
+			So(fetches, ShouldEqual, 1)
+
+			status, err = creds.updateRepoStatus(counting.URL+"/B/", status)
+			So(err, ShouldBeNil)
+			So(status.Latest, ShouldEqual, repos["B"][1])
+			So(fetches, ShouldEqual, 1)
+
+			newest := time.Date(2010, 1, 1, 1, 1, 1, 0, time.UTC)
+
+			addCommit(t, worktrees["B"], "new", newest)
+
+			status, err = creds.updateRepoStatus(counting.URL+"/B/", status)
+			So(err, ShouldBeNil)
+			So(status.Latest, ShouldEqual, newest)
+			So(len(status.Branches), ShouldEqual, 2)
+			So(status.Branches["master"], ShouldEqual, newest)
+			So(status.Branches["old"], ShouldEqual, old)
+			So(fetches, ShouldEqual, 2)
+
+			cache := NewCache(time.Hour, nil)
+
+			Reset(cache.Stop)
+
+			status, err = cache.GetRepoStatus(server.URL + "/A/")
+			So(err, ShouldBeNil)
+			So(len(status.Branches), ShouldEqual, 2)
+			So(status.Branches["master"], ShouldEqual, repos["A"][0])
+			So(status.Branches["old"], ShouldEqual, old)
+
+			cache.prune()
+			So(cache.statuses, ShouldContainKey, server.URL+"/A/")
+
+			cache.prune()
+			So(cache.statuses, ShouldBeEmpty)
+			So(cache.cache.Remove(server.URL+"/A/"), ShouldBeFalse)
+		})
+
+		Convey("Errors are classified", func() {
+			_, err := GetLatestCommitDate(server.URL + "/private/A/")
+			So(err, ShouldWrap, ErrAuth)
+
+			_, err = GetLatestCommitDate(server.URL + "/D/")
+			So(err, ShouldWrap, ErrNotFound)
+
+			closed := httptest.NewServer(&mux)
+			closed.Close()
+
+			_, err = GetLatestCommitDate(closed.URL + "/A/")
+			So(err, ShouldWrap, ErrNetwork)
+		})
+
+		Convey("You can query private repos using token credentials", func() {
+			u, err := url.Parse(server.URL)
+			So(err, ShouldBeNil)
+
+			tokenFile := filepath.Join(t.TempDir(), "token")
+			So(os.WriteFile(tokenFile, []byte("secret\n"), 0600), ShouldBeNil)
+
+			creds := &Credentials{
+				HTTPTokens: map[string]HTTPToken{
+					u.Hostname(): {TokenFile: tokenFile},
+				},
+			}
+
+			commitTime, err := creds.GetLatestCommitDate(server.URL + "/private/B/")
+			So(err, ShouldBeNil)
+			So(commitTime, ShouldEqual, repos["B"][1])
+
+			cache := NewCache(time.Hour, func() *Credentials { return creds })
+
+			Reset(cache.Stop)
+
+			commitTime, err = cache.GetLatestCommitDate(server.URL + "/private/B/")
+			So(err, ShouldBeNil)
+			So(commitTime, ShouldEqual, repos["B"][1])
+
+			So(os.WriteFile(tokenFile, []byte("wrong"), 0600), ShouldBeNil)
+
+			_, err = creds.GetLatestCommitDate(server.URL + "/private/B/")
+			So(err, ShouldWrap, ErrAuth)
+
+			creds.HTTPTokens[u.Hostname()] = HTTPToken{TokenFile: filepath.Join(t.TempDir(), "missing")}
+
+			_, err = creds.GetLatestCommitDate(server.URL + "/private/B/")
+			So(err, ShouldWrap, ErrAuth)
+		})
+	})
+}
+
+func requireToken(h http.Handler, token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != defaultTokenUsername || pass != token {
+			w.Header().Set("WWW-Authenticate", `Basic realm="git"`)
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		h.ServeHTTP(w, r)
 	})
 }
 
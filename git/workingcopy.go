@@ -0,0 +1,283 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// WorkingCopyStatus describes the state of a local git working copy compared
+// to its remote.
+//
+// Uncommitted is the number of tracked files with changes that have not been
+// committed; Untracked is the number of files not tracked by the repo; and
+// Unpushed is the number of commits reachable from HEAD that are not reachable
+// from any ref on the remote, or from any local remote-tracking ref for it. If
+// none of those refs can be found locally, such as when a clone is behind its
+// remote and has not been fetched, Unpushed will be 0.
+type WorkingCopyStatus struct {
+	Path        string
+	Uncommitted int
+	Untracked   int
+	Unpushed    int
+}
+
+// CheckWorkingCopy finds a local git repository in the given path, either
+// containing the path, or in a direct child of it, and reports on any work that
+// has not been committed and pushed to the given remote URL.
+//
+// When searching the children of the path, only a repo with a remote using the
+// given URL will be checked.
+//
+// Remote refs are listed using these credentials, if any match the remote URL.
+func (c *Credentials) CheckWorkingCopy(path, remoteURL string) (*WorkingCopyStatus, error) {
+	repo, repoPath, err := findRepo(path, remoteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	wcs := &WorkingCopyStatus{Path: repoPath}
+
+	if err = countChanges(repo, wcs); err != nil {
+		return nil, err
+	}
+
+	remoteHashes, err := c.listRemoteHashes(remoteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	trackingHashes, err := listTrackingHashes(repo, remoteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	wcs.Unpushed, err = countUnpushed(repo, remoteHashes, trackingHashes)
+	if err != nil {
+		return nil, err
+	}
+
+	return wcs, nil
+}
+
+func findRepo(path, remoteURL string) (*git.Repository, string, error) {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	if err == nil {
+		return repo, path, nil
+	} else if !errors.Is(err, git.ErrRepositoryNotExists) {
+		return nil, "", err
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		child := filepath.Join(path, entry.Name())
+
+		repo, err := git.PlainOpen(child)
+		if err == nil && hasRemote(repo, remoteURL) {
+			return repo, child, nil
+		}
+	}
+
+	return nil, "", git.ErrRepositoryNotExists
+}
+
+func hasRemote(repo *git.Repository, remoteURL string) bool {
+	names, err := remoteNames(repo, remoteURL)
+
+	return err == nil && len(names) > 0
+}
+
+func remoteNames(repo *git.Repository, remoteURL string) ([]string, error) {
+	remotes, err := repo.Remotes()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+
+	for _, remote := range remotes {
+		if slices.Contains(remote.Config().URLs, remoteURL) {
+			names = append(names, remote.Config().Name)
+		}
+	}
+
+	return names, nil
+}
+
+func countChanges(repo *git.Repository, wcs *WorkingCopyStatus) error {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	status, err := wt.Status()
+	if err != nil {
+		return err
+	}
+
+	for _, fs := range status {
+		switch {
+		case fs.Worktree == git.Untracked:
+			wcs.Untracked++
+		case fs.Worktree != git.Unmodified, fs.Staging != git.Unmodified:
+			wcs.Uncommitted++
+		}
+	}
+
+	return nil
+}
+
+func (c *Credentials) listRemoteHashes(remoteURL string) ([]plumbing.Hash, error) {
+	r, err := c.newRemote(remoteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := r.list()
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]plumbing.Hash, 0, len(refs))
+
+	for _, ref := range refs {
+		if ref.Type() == plumbing.HashReference {
+			hashes = append(hashes, ref.Hash())
+		}
+	}
+
+	return hashes, nil
+}
+
+// listTrackingHashes returns the hashes of the local remote-tracking refs, ie.
+// refs/remotes/<name>/*, of any remote using the given URL.
+func listTrackingHashes(repo *git.Repository, remoteURL string) ([]plumbing.Hash, error) {
+	names, err := remoteNames(repo, remoteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := repo.References()
+	if err != nil {
+		return nil, err
+	}
+
+	var hashes []plumbing.Hash
+
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference || !ref.Name().IsRemote() {
+			return nil
+		}
+
+		for _, name := range names {
+			if strings.HasPrefix(ref.Name().String(), "refs/remotes/"+name+"/") {
+				hashes = append(hashes, ref.Hash())
+
+				break
+			}
+		}
+
+		return nil
+	})
+
+	return hashes, err
+}
+
+func countUnpushed(repo *git.Repository, remoteHashes, trackingHashes []plumbing.Hash) (int, error) {
+	head, err := repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	pushed, err := reachableCommits(repo, slices.Concat(remoteHashes, trackingHashes))
+	if err != nil {
+		return 0, err
+	}
+
+	if len(pushed) == 0 && len(remoteHashes) > 0 {
+		return 0, nil
+	}
+
+	headCommit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return 0, err
+	}
+
+	var unpushed int
+
+	err = object.NewCommitPreorderIter(headCommit, pushed, nil).ForEach(func(*object.Commit) error {
+		unpushed++
+
+		return nil
+	})
+
+	return unpushed, err
+}
+
+func reachableCommits(repo *git.Repository, hashes []plumbing.Hash) (map[plumbing.Hash]bool, error) {
+	seen := make(map[plumbing.Hash]bool)
+
+	for _, hash := range hashes {
+		if seen[hash] {
+			continue
+		}
+
+		commit, err := repo.CommitObject(hash)
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		if err = object.NewCommitPreorderIter(commit, seen, nil).ForEach(func(c *object.Commit) error {
+			seen[c.Hash] = true
+
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return seen, nil
+}
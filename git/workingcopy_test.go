@@ -0,0 +1,135 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package git
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v6/memfs"
+	"github.com/go-git/go-git/v6"
+	githttp "github.com/go-git/go-git/v6/backend/http"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/storage/memory"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCheckWorkingCopy(t *testing.T) {
+	Convey("Given a remote repo and a local clone", t, func() {
+		r, err := git.Init(memory.NewStorage(), git.WithWorkTree(memfs.New()))
+		So(err, ShouldBeNil)
+
+		wt, err := r.Worktree()
+		So(err, ShouldBeNil)
+
+		addCommit(t, wt, "a", time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC))
+
+		var mux http.ServeMux
+
+		mux.Handle("/repo/", githttp.NewBackend(&loader{r.Storer}))
+
+		server := httptest.NewServer(&mux)
+
+		Reset(server.Close)
+
+		remote := server.URL + "/repo/"
+		base := t.TempDir()
+		local := filepath.Join(base, "clone")
+
+		_, err = git.PlainClone(local, &git.CloneOptions{URL: remote})
+		So(err, ShouldBeNil)
+
+		var creds *Credentials
+
+		Convey("A clean clone has nothing to report", func() {
+			wcs, err := creds.CheckWorkingCopy(local, remote)
+			So(err, ShouldBeNil)
+			So(wcs, ShouldResemble, &WorkingCopyStatus{Path: local})
+		})
+
+		Convey("A clone that is behind its remote has no unpushed commits", func() {
+			addCommit(t, wt, "b", time.Date(2002, 2, 3, 4, 5, 6, 0, time.UTC))
+
+			wcs, err := creds.CheckWorkingCopy(local, remote)
+			So(err, ShouldBeNil)
+			So(wcs, ShouldResemble, &WorkingCopyStatus{Path: local})
+
+			Convey("…even without remote-tracking refs", func() {
+				lr, err := git.PlainOpen(local)
+				So(err, ShouldBeNil)
+
+				ref := plumbing.NewRemoteReferenceName("origin", "master")
+
+				_, err = lr.Reference(ref, false)
+				So(err, ShouldBeNil)
+				So(lr.Storer.RemoveReference(ref), ShouldBeNil)
+
+				wcs, err := creds.CheckWorkingCopy(local, remote)
+				So(err, ShouldBeNil)
+				So(wcs, ShouldResemble, &WorkingCopyStatus{Path: local})
+			})
+		})
+
+		Convey("Changes, untracked files and unpushed commits are counted", func() {
+			lr, err := git.PlainOpen(local)
+			So(err, ShouldBeNil)
+
+			lwt, err := lr.Worktree()
+			So(err, ShouldBeNil)
+
+			addCommit(t, lwt, "b", time.Date(2002, 2, 3, 4, 5, 6, 0, time.UTC))
+			addCommit(t, lwt, "c", time.Date(2003, 2, 3, 4, 5, 6, 0, time.UTC))
+
+			So(os.WriteFile(filepath.Join(local, "a"), []byte("changed"), 0600), ShouldBeNil)
+			So(os.WriteFile(filepath.Join(local, "new1"), []byte("new"), 0600), ShouldBeNil)
+			So(os.WriteFile(filepath.Join(local, "new2"), []byte("new"), 0600), ShouldBeNil)
+
+			expected := &WorkingCopyStatus{
+				Path:        local,
+				Uncommitted: 1,
+				Untracked:   2,
+				Unpushed:    2,
+			}
+
+			wcs, err := creds.CheckWorkingCopy(local, remote)
+			So(err, ShouldBeNil)
+			So(wcs, ShouldResemble, expected)
+
+			Convey("A clone in a child directory can be found by its remote", func() {
+				wcs, err := creds.CheckWorkingCopy(base, remote)
+				So(err, ShouldBeNil)
+				So(wcs, ShouldResemble, expected)
+
+				_, err = creds.CheckWorkingCopy(base, server.URL+"/other/")
+				So(err, ShouldEqual, git.ErrRepositoryNotExists)
+			})
+		})
+	})
+}
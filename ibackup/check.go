@@ -0,0 +1,102 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package ibackup
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+
+	"github.com/wtsi-hgi/ibackup/transformer"
+)
+
+// CheckConfig returns all of the problems found in the given Config: invalid
+// path regexps, unknown transformers and references to unknown servers.
+//
+// If connect is true, a connection will also be attempted to each server, and
+// each FOFNDir checked to be a directory.
+func CheckConfig(c Config, connect bool) []error {
+	var errs []error
+
+	for _, re := range slices.Sorted(maps.Keys(c.PathToServer)) {
+		errs = append(errs, checkPathToServer(c, re, c.PathToServer[re])...)
+	}
+
+	if !connect {
+		return errs
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(c.Servers)) {
+		if err := checkServer(c.Servers[name]); err != nil {
+			errs = append(errs, &ServerConnectionError{name, err})
+		}
+	}
+
+	return errs
+}
+
+func checkPathToServer(c Config, re string, server ServerTransformer) []error {
+	var errs []error
+
+	if _, err := regexp.Compile(re); err != nil {
+		errs = append(errs, fmt.Errorf("pathtoserver %q: %w", re, err))
+	}
+
+	if _, ok := c.Servers[server.ServerName]; !ok {
+		errs = append(errs, fmt.Errorf("pathtoserver %q: %w", re, UnknownServerError(server.ServerName)))
+	}
+
+	if _, ok := c.Servers[server.ManualServerName]; !ok && server.ManualServerName != "" {
+		errs = append(errs, fmt.Errorf("pathtoserver %q: manual %w", re, UnknownServerError(server.ManualServerName)))
+	}
+
+	if _, err := transformer.MakePathTransformer(server.Transformer); err != nil {
+		errs = append(errs, fmt.Errorf("pathtoserver %q: invalid transformer %q: %w", re, server.Transformer, err))
+	}
+
+	return errs
+}
+
+func checkServer(details ServerDetails) error {
+	if details.FOFNDir != "" {
+		fi, err := os.Stat(details.FOFNDir)
+		if err != nil {
+			return err
+		}
+
+		if !fi.IsDir() {
+			return fmt.Errorf("%s: %w", details.FOFNDir, ErrNotDirectory)
+		}
+
+		return nil
+	}
+
+	_, err := connect(jwtBasename(details.Token), details.Token, details.Addr, details.Cert, details.Username)
+
+	return err
+}
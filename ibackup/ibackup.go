@@ -49,8 +49,12 @@ var (
 	ErrInvalidPath   = errors.New("cannot determine transformer from path")
 	ErrUnknownClient = errors.New("cannot determine client from path")
 	ErrNoUpdate      = errors.New("frequency 0 set is already backed up")
+	ErrNoFileList    = errors.New("ibackup client cannot list set files")
+	ErrNotDirectory  = errors.New("not a directory")
 )
 
+const percent = 100
+
 // ServerDetails contains the connection details for a particular ibackup
 // server.
 type ServerDetails struct {
@@ -477,6 +481,53 @@ type SetBackupActivity struct {
 	Orphaned    uint64
 	Hardlinks   uint64
 	Skipped     uint64
+	Coverage    *SetCoverage         `json:",omitempty"`
+	WorkingCopy *GitWorkingCopy      `json:",omitempty"`
+	Branches    map[string]time.Time `json:",omitempty"`
+	Error       string               `json:",omitempty"`
+
+	// SourceLastMod is the newest modification time of the files backed up to
+	// a manualnfs or manualgit target, and Stale is set when the target was
+	// last modified before it.
+	SourceLastMod time.Time `json:",omitzero"`
+	Stale         bool      `json:",omitempty"`
+}
+
+// SetCoverage summarises, as of the Checked time, how many of the files matched
+// by a rule were found in a manual ibackup set and uploaded since they were
+// last modified.
+type SetCoverage struct {
+	Checked time.Time
+	Matched uint64
+	Missing uint64
+	Stale   uint64
+	Extra   uint64
+	Percent float64
+}
+
+// CoveragePercent returns the percentage of the matched files that are not
+// uncovered, ie. missing from, or stale in, a set. If no files were matched,
+// coverage is complete.
+func CoveragePercent(matched, uncovered uint64) float64 {
+	if matched == 0 {
+		return percent
+	}
+
+	uncovered = min(uncovered, matched)
+
+	return percent * float64(matched-uncovered) / float64(matched)
+}
+
+// GitWorkingCopy summarises, as of the Checked time, the state of a local git
+// working copy compared to the remote repo of a manualgit rule. If the working
+// copy could not be checked, Error will contain the reason.
+type GitWorkingCopy struct {
+	Checked     time.Time
+	Path        string
+	Uncommitted uint64
+	Untracked   uint64
+	Unpushed    uint64
+	Error       string `json:",omitempty"`
 }
 
 // GetBackupActivity queries an ibackup server to get the last completed backup
@@ -509,6 +560,62 @@ func GetBackupActivity(client Client, setName, requester string) (*SetBackupActi
 	return &sba, nil
 }
 
+// SetFile is a file listed in an ibackup set, along with the time it was last
+// successfully uploaded; the time is zero when it has not been uploaded.
+type SetFile struct {
+	Path     string
+	Uploaded time.Time
+}
+
+type fileLister interface {
+	GetFiles(setID string) ([]*set.Entry, error)
+}
+
+// GetSetFiles retrieves a client using the given path, and then calls the
+// normal GetSetFiles function.
+func (m *MultiClient) GetSetFiles(path, setName, requester string, manual bool) ([]SetFile, error) {
+	c := m.getClient(path)
+	if c == nil {
+		return nil, ErrInvalidPath
+	}
+
+	return GetSetFiles(c.Client(manual).Load(), setName, requester)
+}
+
+// GetSetFiles queries an ibackup server to get the list of files in the given
+// set for the given requester.
+//
+// Returns ErrNoFileList if the client is unable to list set files.
+func GetSetFiles(client Client, setName, requester string) ([]SetFile, error) {
+	fl, ok := client.(fileLister)
+	if !ok {
+		return nil, ErrNoFileList
+	}
+
+	got, err := client.GetSetByName(requester, setName)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := fl.GetFiles(got.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]SetFile, len(entries))
+
+	for n, entry := range entries {
+		files[n].Path = entry.Path
+
+		switch entry.Status { //nolint:exhaustive
+		case set.Uploaded, set.Replaced, set.Skipped:
+			files[n].Uploaded = entry.LastAttempt
+		}
+	}
+
+	return files, nil
+}
+
 type setRequester struct {
 	set, requester string
 }
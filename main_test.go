@@ -126,6 +126,62 @@ func TestCommands(t *testing.T) {
 			So(err, ShouldBeNil)
 		})
 
+		Convey("The plan command can view and change plans directly in a plan database", func() {
+			_, dbPath := plandb.PopulateExamplePlanDB(t)
+
+			tmp := t.TempDir()
+			identity := filepath.Join(tmp, "identity.json")
+			planConfig := filepath.Join(tmp, "plan.yaml")
+
+			So(os.WriteFile(identity, []byte(`{
+	"users": [{"name": "userA", "uid": 1, "gids": [1, 2]}],
+	"groups": [{"name": "groupA", "gid": 1}, {"name": "groupB", "gid": 2}]
+}`), 0600), ShouldBeNil)
+			So(os.WriteFile(planConfig, []byte("identity:\n  source: json\n  json: "+identity+"\n"), 0600), ShouldBeNil)
+
+			plan := func(args ...string) (string, error) {
+				out, err := exec.Command(appExe, append([]string{"plan", "--plan", dbPath, //nolint:noctx
+					"--tree", "testdata/tree.db", "--config", planConfig}, args...)...).CombinedOutput()
+
+				return string(out), err
+			}
+
+			out, err := plan("ls", "/lustre/scratch123/humgen/a/b/")
+			So(err, ShouldNotBeNil)
+			So(out, ShouldContainSubstring, "--as must be given")
+
+			out, err = plan("--as", "userA", "--json", "ls", "/lustre/scratch123/humgen/a/b/")
+			So(err, ShouldBeNil)
+			So(out, ShouldContainSubstring, `"ClaimedBy":"userA"`)
+			So(out, ShouldContainSubstring, `{"Match":"*.jpg","Action":"backup"}`)
+
+			out, err = plan("--as", "userA", "rule", "add", "/lustre/scratch123/humgen/a/b/", "sometimes", "*.txt")
+			So(err, ShouldNotBeNil)
+			So(out, ShouldContainSubstring, "invalid action")
+
+			out, err = plan("--as", "userA", "rule", "add", "/lustre/scratch123/humgen/a/b/", "nobackup", "*.txt")
+			So(err, ShouldBeNil)
+			So(out, ShouldContainSubstring, "rule: *.txt: nobackup\n")
+
+			out, err = plan("--as", "userA", "rule", "update", "/lustre/scratch123/humgen/a/b/", "backup", "*.txt",
+				"--override")
+			So(err, ShouldNotBeNil)
+			So(out, ShouldContainSubstring, "unknown flag: --override")
+
+			out, err = plan("--as", "userA", "rule", "update", "/lustre/scratch123/humgen/a/b/", "backup", "*.txt")
+			So(err, ShouldBeNil)
+			So(out, ShouldContainSubstring, "rule: *.txt: backup\n")
+
+			out, err = plan("--as", "userA", "set-details", "/lustre/scratch123/humgen/a/b/",
+				"--review", "2000-01-01")
+			So(err, ShouldNotBeNil)
+			So(out, ShouldContainSubstring, "invalid time")
+
+			out, err = plan("--as", "userA", "--json", "rule", "rm", "/lustre/scratch123/humgen/a/b/", "*.txt")
+			So(err, ShouldBeNil)
+			So(out, ShouldNotContainSubstring, `"*.txt"`)
+		})
+
 		Convey("The backups command produces FOFNs when configured to do so", func() {
 			fofnDir := t.TempDir()
 
@@ -0,0 +1,153 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+// Package mtime retrieves the latest modification time of the files within a
+// locally mounted directory by walking it directly.
+package mtime
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/wtsi-hgi/activecache"
+)
+
+var ErrBudgetExceeded = errors.New("time budget exceeded")
+
+// Walk returns the latest mtime of the given path and of everything beneath
+// it, descending at most maxDepth directories below the path; a maxDepth of
+// zero or less imposes no limit.
+//
+// Directories that cannot be read are skipped.
+//
+// If the walk takes longer than the given budget, the latest mtime found so far
+// is returned along with ErrBudgetExceeded; a budget of zero or less imposes no
+// limit.
+func Walk(path string, maxDepth int, budget time.Duration) (time.Time, error) {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	w := walker{
+		latest:   fi.ModTime(),
+		maxDepth: maxDepth,
+	}
+
+	if budget > 0 {
+		w.deadline = time.Now().Add(budget)
+	}
+
+	if fi.IsDir() {
+		err = w.walk(path, 1)
+	}
+
+	return w.latest, err
+}
+
+type walker struct {
+	latest   time.Time
+	maxDepth int
+	deadline time.Time
+}
+
+func (w *walker) walk(dir string, depth int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil //nolint:nilerr
+	}
+
+	for _, entry := range entries {
+		if !w.deadline.IsZero() && time.Now().After(w.deadline) {
+			return ErrBudgetExceeded
+		}
+
+		fi, err := entry.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if fi.ModTime().After(w.latest) {
+			w.latest = fi.ModTime()
+		}
+
+		if !entry.IsDir() || (w.maxDepth > 0 && depth >= w.maxDepth) {
+			continue
+		}
+
+		if err := w.walk(filepath.Join(dir, entry.Name()), depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type walkKey struct {
+	path     string
+	maxDepth int
+	budget   time.Duration
+}
+
+// Cache wraps the Walk function, caching, and on a schedule re-retrieving,
+// requested mtimes.
+type Cache struct {
+	cache *activecache.Cache[walkKey, time.Time]
+}
+
+// NewCache creates a cache storing the results of Walk, that will re-walk the
+// requested paths on a timeout specified by the given Duration.
+//
+// The Stop() method must be before replacing (or otherwise losing this pointer
+// to) this cache.
+func NewCache(d time.Duration) *Cache {
+	return &Cache{activecache.New(d, func(key walkKey) (time.Time, error) {
+		t, err := Walk(key.path, key.maxDepth, key.budget)
+		if errors.Is(err, ErrBudgetExceeded) {
+			return t, nil
+		}
+
+		return t, err
+	})}
+}
+
+// Walk retrieves the cached result of walking the given path with the given
+// limits, calling Walk if it has not previously been requested.
+//
+// Unlike Walk, a partial result due to an exceeded budget is not returned as an
+// error.
+func (c *Cache) Walk(path string, maxDepth int, budget time.Duration) (time.Time, error) {
+	return c.cache.Get(walkKey{path: filepath.Clean(path), maxDepth: maxDepth, budget: budget})
+}
+
+// Stop stops the concurrent re-walking of paths.
+func (c *Cache) Stop() {
+	c.cache.Stop()
+}
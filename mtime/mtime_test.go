@@ -0,0 +1,108 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package mtime
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWalk(t *testing.T) {
+	Convey("Given a directory tree with known mtimes", t, func() {
+		base := t.TempDir()
+
+		old := time.Unix(1000, 0)
+		mid := time.Unix(2000, 0)
+		deep := time.Unix(3000, 0)
+
+		So(os.MkdirAll(filepath.Join(base, "a", "b"), 0700), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(base, "file"), nil, 0600), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(base, "a", "file"), nil, 0600), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(base, "a", "b", "file"), nil, 0600), ShouldBeNil)
+
+		for path, mtime := range map[string]time.Time{
+			"a/b/file": deep,
+			"a/b":      old,
+			"a/file":   mid,
+			"a":        old,
+			"file":     old,
+			"":         old,
+		} {
+			So(os.Chtimes(filepath.Join(base, path), mtime, mtime), ShouldBeNil)
+		}
+
+		Convey("You can get the latest mtime of the whole tree", func() {
+			mt, err := Walk(base, 0, 0)
+			So(err, ShouldBeNil)
+			So(mt, ShouldEqual, deep)
+		})
+
+		Convey("You can limit the depth of the walk", func() {
+			mt, err := Walk(base, 2, 0)
+			So(err, ShouldBeNil)
+			So(mt, ShouldEqual, mid)
+
+			mt, err = Walk(base, 1, 0)
+			So(err, ShouldBeNil)
+			So(mt, ShouldEqual, old)
+		})
+
+		Convey("A walk that exceeds its time budget returns an error", func() {
+			_, err := Walk(base, 0, time.Nanosecond)
+			So(err, ShouldEqual, ErrBudgetExceeded)
+		})
+
+		Convey("Walking a missing path returns an error", func() {
+			_, err := Walk(filepath.Join(base, "missing"), 0, 0)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("You can cache walks", func() {
+			c := NewCache(time.Hour)
+
+			Reset(c.Stop)
+
+			mt, err := c.Walk(base, 0, 0)
+			So(err, ShouldBeNil)
+			So(mt, ShouldEqual, deep)
+
+			newer := time.Unix(4000, 0)
+
+			So(os.Chtimes(filepath.Join(base, "file"), newer, newer), ShouldBeNil)
+
+			mt, err = c.Walk(base+"/", 0, 0)
+			So(err, ShouldBeNil)
+			So(mt, ShouldEqual, deep)
+
+			_, err = c.Walk(base, 0, time.Nanosecond)
+			So(err, ShouldBeNil)
+		})
+	})
+}
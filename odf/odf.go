@@ -0,0 +1,162 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+// Package odf writes OpenDocument spreadsheets.
+package odf
+
+import (
+	"bytes"
+	"compress/flate"
+	"hash/crc32"
+	"io"
+
+	"vimagination.zapto.org/byteio"
+)
+
+const (
+	mime     = "application/vnd.oasis.opendocument.spreadsheet"
+	mimeName = "mimetype"
+	manifest = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.4"><manifest:file-entry manifest:full-path="/" manifest:media-type="application/vnd.oasis.opendocument.spreadsheet"/><manifest:file-entry manifest:full-path="styles.xml" manifest:media-type="text/xml"/><manifest:file-entry manifest:full-path="content.xml" manifest:media-type="text/xml"/></manifest:manifest>` //nolint:lll
+	manifestName = "META-INF/manifest.xml"
+	style        = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<office:document-styles xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" xmlns:number="urn:oasis:names:tc:opendocument:xmlns:datastyle:1.0" office:version="1.4"><office:styles><number:number-style style:name="Z" style:volatile="true"><number:text>0B</number:text></number:number-style><number:number-style style:name="TB" style:volatile="true"><number:text>  </number:text><number:number number:decimal-places="0" number:min-decimal-places="0" number:min-integer-digits="1" number:grouping="true" number:display-factor="1000000000000"/><number:text>TB</number:text></number:number-style><number:number-style style:name="GB" style:volatile="true"><number:text>  </number:text><number:number number:decimal-places="0" number:min-decimal-places="0" number:min-integer-digits="1" number:grouping="true" number:display-factor="1000000000"/><number:text>GB</number:text></number:number-style><number:text-style style:name="Bytes"><number:text-content/><style:map style:condition="value()=0" style:apply-style-name="Z"/><style:map style:condition="value()&gt;=1000000000000" style:apply-style-name="TB"/><style:map style:condition="value()&lt;1000000000000" style:apply-style-name="GB"/></number:text-style></office:styles></office:document-styles>` //nolint:lll
+	styleName   = "styles.xml"
+	contentName = "content.xml"
+
+	compressionDeflate = 8
+	numFiles           = 4
+)
+
+// Offsets contains the positions, within a written archive, of the data of the
+// content.xml file, and of the CRC and sizes of it in both the local file
+// header and the central directory file header.
+type Offsets struct {
+	ContentMeta int64
+	ContentData int64
+	CDFHMeta    int64
+}
+
+// Write writes an ODS archive, containing the given content.xml, to the given
+// writer, returning the offsets of the content within the archive.
+//
+// The content is stored uncompressed.
+func Write(w io.Writer, content []byte) (Offsets, error) {
+	var buf, cdBuf bytes.Buffer
+
+	lw := byteio.StickyLittleEndianWriter{Writer: &buf}
+	cdlw := byteio.StickyLittleEndianWriter{Writer: &cdBuf}
+
+	writeFile(&lw, &cdlw, mimeName, []byte(mime), false)
+	writeFile(&lw, &cdlw, styleName, []byte(style), true)
+	writeFile(&lw, &cdlw, manifestName, []byte(manifest), true)
+
+	contentStart := lw.Count
+	cdfhStart := cdlw.Count
+
+	writeFile(&lw, &cdlw, contentName, content, false)
+
+	cdStart := lw.Count
+	offsets := Offsets{
+		ContentMeta: contentStart + 14,             //nolint:mnd
+		ContentData: cdStart - int64(len(content)), //nolint:mnd
+		CDFHMeta:    cdStart + cdfhStart + 16,      //nolint:mnd
+	}
+
+	lw.Write(cdBuf.Bytes())
+
+	writeEOCD(&lw, &cdlw, cdStart)
+
+	if lw.Err != nil {
+		return offsets, lw.Err
+	}
+
+	_, err := w.Write(buf.Bytes())
+
+	return offsets, err
+}
+
+//nolint:gosec
+func writeFile(lw, cdlw *byteio.StickyLittleEndianWriter, name string, contents []byte, compress bool) {
+	data := contents
+	crc := crc32.ChecksumIEEE(data)
+
+	var method uint16
+
+	if compress {
+		var buf bytes.Buffer
+
+		f, _ := flate.NewWriter(&buf, flate.BestCompression) //nolint:errcheck
+
+		f.Write(contents) //nolint:errcheck
+		f.Close()         //nolint:errcheck
+
+		data = buf.Bytes()
+		method = compressionDeflate
+	}
+
+	cdlw.WriteUint32(0x02014B50)            // Central directory file Header
+	cdlw.WriteUint16(0xa)                   // Version
+	cdlw.WriteUint16(0xa)                   // Minimum Version
+	cdlw.WriteUint16(0)                     // General Purpose Flags
+	cdlw.WriteUint16(method)                // Compression Method
+	cdlw.WriteUint32(0)                     // Modified time/date
+	cdlw.WriteUint32(crc)                   // CRC
+	cdlw.WriteUint32(uint32(len(data)))     // Compressed Size
+	cdlw.WriteUint32(uint32(len(contents))) // Uncompressed Size
+	cdlw.WriteUint16(uint16(len(name)))     // Name length
+	cdlw.WriteUint16(0)                     // Extra Fields
+	cdlw.WriteUint16(0)                     // File Comments
+	cdlw.WriteUint16(0)                     // Disk Num.
+	cdlw.WriteUint16(0)                     // Int. File Attrs.
+	cdlw.WriteUint32(0)                     // Ext. File Attrs.
+	cdlw.WriteUint32(uint32(lw.Count))      // Offset
+	cdlw.WriteString(name)
+
+	lw.WriteUint32(0x04034B50)            // LocalFile Header
+	lw.WriteUint16(0xa)                   // Version
+	lw.WriteUint16(0)                     // General Purpose Flags
+	lw.WriteUint16(method)                // Compression Method
+	lw.WriteUint32(0)                     // Modified time/date
+	lw.WriteUint32(crc)                   // CRC
+	lw.WriteUint32(uint32(len(data)))     // Compressed Size
+	lw.WriteUint32(uint32(len(contents))) // Uncompressed Size
+	lw.WriteUint16(uint16(len(name)))     // Name length
+	lw.WriteUint16(0)                     // Extra fields
+	lw.WriteString(name)
+	lw.Write(data)
+}
+
+//nolint:gosec
+func writeEOCD(lw, cdlw *byteio.StickyLittleEndianWriter, cdStart int64) {
+	lw.WriteUint32(0x06054B50)         // EOCD
+	lw.WriteUint16(0)                  // Disk Num.
+	lw.WriteUint16(0)                  // Disk Start
+	lw.WriteUint16(numFiles)           // Number of records
+	lw.WriteUint16(numFiles)           // Total number of records
+	lw.WriteUint32(uint32(cdlw.Count)) // Total Central Records length
+	lw.WriteUint32(uint32(cdStart))    // Start of Central Records
+	lw.WriteUint16(0)
+}
@@ -0,0 +1,140 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package odf
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWrite(t *testing.T) {
+	Convey("Write produces a valid archive, with the offsets of the content", t, func() {
+		content := []byte("<content/>")
+
+		var buf bytes.Buffer
+
+		offsets, err := Write(&buf, content)
+		So(err, ShouldBeNil)
+
+		data := buf.Bytes()
+
+		So(data[offsets.ContentData:offsets.ContentData+int64(len(content))], ShouldResemble, content)
+		So(binary.LittleEndian.Uint32(data[offsets.ContentMeta:]), ShouldEqual, crc32.ChecksumIEEE(content))
+		So(binary.LittleEndian.Uint32(data[offsets.CDFHMeta+4:]), ShouldEqual, len(content))
+
+		files := readArchive(data)
+		So(files["mimetype"], ShouldEqual, mime)
+		So(files["styles.xml"], ShouldEqual, style)
+		So(files["META-INF/manifest.xml"], ShouldEqual, manifest)
+		So(files["content.xml"], ShouldEqual, string(content))
+	})
+}
+
+func TestWriteSpreadsheet(t *testing.T) {
+	Convey("WriteSpreadsheet writes tables of cells as an ODS file", t, func() {
+		var buf bytes.Buffer
+
+		So(WriteSpreadsheet(&buf, Table{
+			Name:    "A & B",
+			Columns: []Column{{Repeat: 2}, {Style: StyleBytes}},
+			Rows: [][]Cell{
+				{String("Name"), String("Count"), String("Size")},
+				{String("<dir>"), Count(3), Bytes(2048)},
+				{{Text: "Red", Style: StyleRed}, Float(0.5), Bytes(0)},
+			},
+			Filter: 2,
+		}, Table{Name: "Empty"}), ShouldBeNil)
+
+		content := readArchive(buf.Bytes())["content.xml"]
+		So(content, ShouldStartWith, contentHeader)
+		So(content, ShouldEndWith, contentFooter)
+		So(content[len(contentHeader):len(content)-len(contentFooter)], ShouldEqual, ``+
+			`<table:table table:name="A &amp; B">`+
+			`<table:table-column table:number-columns-repeated="2"/>`+
+			`<table:table-column table:number-columns-repeated="1" table:default-cell-style-name="bytes"/>`+
+			`<table:table-row>`+
+			`<table:table-cell office:value-type="string"><text:p>Name</text:p></table:table-cell>`+
+			`<table:table-cell office:value-type="string"><text:p>Count</text:p></table:table-cell>`+
+			`<table:table-cell office:value-type="string"><text:p>Size</text:p></table:table-cell>`+
+			`</table:table-row>`+
+			`<table:table-row>`+
+			`<table:table-cell office:value-type="string"><text:p>&lt;dir&gt;</text:p></table:table-cell>`+
+			`<table:table-cell office:value="3" office:value-type="float"><text:p>3</text:p></table:table-cell>`+
+			`<table:table-cell office:value="2048" office:value-type="float"><text:p>2KiB</text:p></table:table-cell>`+
+			`</table:table-row>`+
+			`<table:table-row>`+
+			`<table:table-cell office:value-type="string" table:style-name="r"><text:p>Red</text:p></table:table-cell>`+
+			`<table:table-cell office:value="0.5" office:value-type="float"><text:p>0.5</text:p></table:table-cell>`+
+			`<table:table-cell office:value="0" office:value-type="float"><text:p>0B</text:p></table:table-cell>`+
+			`</table:table-row>`+
+			`</table:table>`+
+			`<table:table table:name="Empty"></table:table>`+
+			`<table:named-expressions/>`+
+			`<table:database-ranges>`+
+			`<table:database-range table:name="__Anonymous_Sheet_DB__0" `+
+			`table:target-range-address="&#39;A &amp; B&#39;.A1:&#39;A &amp; B&#39;.B3" table:display-filter-buttons="true"/>`+
+			`</table:database-ranges>`)
+	})
+
+	Convey("Sizes are formatted with binary prefixes", t, func() {
+		So(FormatBytes(0), ShouldEqual, "0B")
+		So(FormatBytes(1023), ShouldEqual, "1023B")
+		So(FormatBytes(1024), ShouldEqual, "1KiB")
+		So(FormatBytes(5<<30), ShouldEqual, "5GiB")
+	})
+
+	Convey("Column names are lettered as in a spreadsheet", t, func() {
+		So(columnName(1), ShouldEqual, "A")
+		So(columnName(26), ShouldEqual, "Z")
+		So(columnName(27), ShouldEqual, "AA")
+		So(columnName(53), ShouldEqual, "BA")
+	})
+}
+
+func readArchive(data []byte) map[string]string {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	So(err, ShouldBeNil)
+
+	files := make(map[string]string, len(zr.File))
+
+	for _, f := range zr.File {
+		r, err := f.Open()
+		So(err, ShouldBeNil)
+
+		contents, err := io.ReadAll(r)
+		So(err, ShouldBeNil)
+
+		files[f.Name] = string(contents)
+	}
+
+	return files
+}
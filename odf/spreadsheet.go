@@ -0,0 +1,236 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package odf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+// Cell styles available to spreadsheet cells and columns.
+const (
+	StyleBytes = "bytes"
+	StyleRed   = "r"
+	StyleAmber = "a"
+	StyleGreen = "g"
+	StyleBlue  = "b"
+)
+
+const (
+	contentHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0" office:version="1.4"><office:automatic-styles><style:style style:name="bytes" style:family="table-cell" style:parent-style-name="Default" style:data-style-name="Bytes"/><style:style style:name="r" style:family="table-cell" style:parent-style-name="Default"><style:table-cell-properties fo:background-color="#ff0000"/></style:style><style:style style:name="g" style:family="table-cell" style:parent-style-name="Default"><style:table-cell-properties fo:background-color="#00aa00"/></style:style><style:style style:name="b" style:family="table-cell" style:parent-style-name="Default"><style:table-cell-properties fo:background-color="#44aaf7"/></style:style><style:style style:name="a" style:family="table-cell" style:parent-style-name="Default"><style:table-cell-properties fo:background-color="#ffaa00"/></style:style></office:automatic-styles><office:body><office:spreadsheet>` //nolint:lll
+	contentFooter = `</office:spreadsheet></office:body></office:document-content>`
+
+	byteUnit = 1024
+)
+
+var byteSizes = [...]string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"} //nolint:gochecknoglobals
+
+// Cell is a single cell of a spreadsheet table. A Cell with an empty Value is
+// a string cell; otherwise Value holds the numeric value of the cell and Text
+// is how it is displayed.
+type Cell struct {
+	Text  string
+	Value string
+	Style string
+}
+
+// String returns a string cell.
+func String(s string) Cell {
+	return Cell{Text: s}
+}
+
+// Count returns a numeric cell displaying a plain number.
+func Count(n uint64) Cell {
+	v := strconv.FormatUint(n, 10)
+
+	return Cell{Text: v, Value: v}
+}
+
+// Float returns a numeric cell displaying a floating point number.
+func Float(f float64) Cell {
+	v := strconv.FormatFloat(f, 'f', -1, 64)
+
+	return Cell{Text: v, Value: v}
+}
+
+// Bytes returns a numeric cell displaying a size in bytes.
+func Bytes(n uint64) Cell {
+	return Cell{Text: FormatBytes(n), Value: strconv.FormatUint(n, 10)}
+}
+
+// FormatBytes formats a size in bytes using binary unit prefixes.
+func FormatBytes(n uint64) string {
+	for _, suffix := range byteSizes {
+		if n < byteUnit {
+			return strconv.FormatUint(n, 10) + suffix
+		}
+
+		n /= byteUnit
+	}
+
+	return "∞"
+}
+
+// Column describes a run of columns in a table.
+type Column struct {
+	Repeat int
+	Style  string
+}
+
+// Table is a single named sheet of a spreadsheet.
+type Table struct {
+	Name    string
+	Columns []Column
+	Rows    [][]Cell
+
+	// Filter, when non-zero, adds filter buttons to the given number of
+	// leading columns, using the first row as the header row.
+	Filter int
+}
+
+// WriteSpreadsheet writes an ODS spreadsheet containing the given tables to
+// the given writer.
+func WriteSpreadsheet(w io.Writer, tables ...Table) error {
+	var buf bytes.Buffer
+
+	buf.WriteString(contentHeader)
+
+	for _, t := range tables {
+		writeTable(&buf, t)
+	}
+
+	writeFilters(&buf, tables)
+	buf.WriteString(contentFooter)
+
+	_, err := Write(w, buf.Bytes())
+
+	return err
+}
+
+func writeTable(buf *bytes.Buffer, t Table) {
+	buf.WriteString(`<table:table table:name="`)
+	escape(buf, t.Name)
+	buf.WriteString(`">`)
+
+	for _, c := range t.Columns {
+		buf.WriteString(`<table:table-column table:number-columns-repeated="`)
+		buf.WriteString(strconv.Itoa(max(c.Repeat, 1)))
+		buf.WriteString(`"`)
+
+		if c.Style != "" {
+			buf.WriteString(` table:default-cell-style-name="`)
+			escape(buf, c.Style)
+			buf.WriteString(`"`)
+		}
+
+		buf.WriteString(`/>`)
+	}
+
+	for _, row := range t.Rows {
+		buf.WriteString(`<table:table-row>`)
+
+		for _, c := range row {
+			writeCell(buf, c)
+		}
+
+		buf.WriteString(`</table:table-row>`)
+	}
+
+	buf.WriteString(`</table:table>`)
+}
+
+func writeCell(buf *bytes.Buffer, c Cell) {
+	if c.Value == "" {
+		buf.WriteString(`<table:table-cell office:value-type="string"`)
+	} else {
+		buf.WriteString(`<table:table-cell office:value="`)
+		escape(buf, c.Value)
+		buf.WriteString(`" office:value-type="float"`)
+	}
+
+	if c.Style != "" {
+		buf.WriteString(` table:style-name="`)
+		escape(buf, c.Style)
+		buf.WriteString(`"`)
+	}
+
+	buf.WriteString(`><text:p>`)
+	escape(buf, c.Text)
+	buf.WriteString(`</text:p></table:table-cell>`)
+}
+
+func writeFilters(buf *bytes.Buffer, tables []Table) {
+	buf.WriteString(`<table:named-expressions/>`)
+
+	var n int
+
+	for _, t := range tables {
+		if t.Filter <= 0 {
+			continue
+		}
+
+		if n == 0 {
+			buf.WriteString(`<table:database-ranges>`)
+		}
+
+		buf.WriteString(`<table:database-range table:name="__Anonymous_Sheet_DB__`)
+		buf.WriteString(strconv.Itoa(n))
+		buf.WriteString(`" table:target-range-address="`)
+		escape(buf, rangeAddress(t))
+		buf.WriteString(`" table:display-filter-buttons="true"/>`)
+
+		n++
+	}
+
+	if n > 0 {
+		buf.WriteString(`</table:database-ranges>`)
+	}
+}
+
+func rangeAddress(t Table) string {
+	sheet := "'" + t.Name + "'."
+
+	return sheet + "A1:" + sheet + columnName(t.Filter) + strconv.Itoa(max(len(t.Rows), 1))
+}
+
+func columnName(n int) string {
+	const letters = 26
+
+	var name []byte
+
+	for ; n > 0; n = (n - 1) / letters {
+		name = append([]byte{byte('A' + (n-1)%letters)}, name...)
+	}
+
+	return string(name)
+}
+
+func escape(buf *bytes.Buffer, s string) {
+	xml.EscapeText(buf, []byte(s)) //nolint:errcheck
+}
@@ -0,0 +1,198 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package ruletree
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/wtsi-hgi/backup-plans/db"
+)
+
+// Reasons a candidate rule lost to the rule that applies to a path, or, when no
+// rule applies, ReasonNotApplied.
+const (
+	ReasonPrecedence = "precedence"
+	ReasonOverride   = "override from a parent"
+	ReasonNearer     = "nearer directory"
+	ReasonNotApplied = "not applied"
+)
+
+// Candidate is a rule that matches a path.
+type Candidate struct {
+	// Directory the rule is defined on.
+	Directory string
+
+	// AppliedAt is the directory the rule is applied at, after the rules have
+	// been resolved; this differs from Directory for overrides and matches
+	// containing slashes.
+	AppliedAt string
+
+	Rule *db.Rule
+
+	// Reason the rule did not apply, if it did not.
+	Reason string `json:",omitempty"`
+}
+
+// Explanation describes which rule applies to a path, and which other rules
+// also match the path.
+type Explanation struct {
+	// Rule that applies, or nil if no rule applies.
+	Rule *Candidate
+
+	// Candidates are the other matching rules, nearest directory first.
+	Candidates []*Candidate
+}
+
+// Explain returns the rule that applies to the given file path, along with the
+// other rules that match it and the reason each did not apply.
+func (r *RootDir) Explain(path string) (*Explanation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	mount := r.GetMountPoint(path)
+	if mount == "" {
+		return nil, ErrNotFound
+	}
+
+	sm, _, err := generateStatemachineFor(mount, nil, r.directoryRules)
+	if err != nil {
+		return nil, err
+	}
+
+	slash := strings.LastIndexByte(path, '/')
+	state, id := stateForDir(sm.GetStateString(mount), strings.TrimPrefix(path[:slash+1], mount))
+	ruleID := fileRuleID(state, id, path[slash+1:])
+
+	var e Explanation
+
+	for _, c := range r.candidates(mount, path) {
+		if c.Rule.ID() == ruleID {
+			e.Rule = c
+		} else {
+			e.Candidates = append(e.Candidates, c)
+		}
+	}
+
+	for _, c := range e.Candidates {
+		c.Reason = e.Rule.beat(c)
+	}
+
+	return &e, nil
+}
+
+// candidates returns the resolved rules that match the given path, nearest
+// directory first and then in order of precedence.
+func (r *RootDir) candidates(mount, path string) []*Candidate {
+	root := NewRuleTree()
+	dirs := make(map[int64]string)
+
+	for dir, dr := range r.directoryRules {
+		if strings.HasPrefix(dir, mount) {
+			root.Set(dir, dr.Rules, false)
+
+			dirs[dr.ID()] = dir
+		}
+	}
+
+	root.Canon()
+
+	var (
+		levels [][]*Candidate
+		dir    = "/"
+	)
+
+	for curr := root; curr != nil; {
+		var level []*Candidate
+
+		for match, rule := range orderRulesByPrecedence(curr.Rules) {
+			if rule.ID() != 0 && wildcardMatch(dir+match, path) {
+				level = append(level, &Candidate{Directory: dirs[rule.DirID()], AppliedAt: dir, Rule: rule})
+			}
+		}
+
+		levels = append(levels, level)
+
+		part, _ := splitMatch(path[len(dir):])
+		if !strings.HasSuffix(part, "/") {
+			break
+		}
+
+		curr = curr.children[part]
+		dir += part
+	}
+
+	var candidates []*Candidate
+
+	for _, level := range slices.Backward(levels) {
+		for _, c := range level {
+			if !slices.ContainsFunc(candidates, func(e *Candidate) bool { return e.Rule.ID() == c.Rule.ID() }) {
+				candidates = append(candidates, c)
+			}
+		}
+	}
+
+	return candidates
+}
+
+// beat returns the reason the candidate rule c lost to this one, which will be
+// nil when no rule applies.
+func (w *Candidate) beat(c *Candidate) string {
+	switch {
+	case w == nil:
+		return ReasonNotApplied
+	case w.Rule.Override && w.Directory != c.Directory && strings.HasPrefix(c.Directory, w.Directory):
+		return ReasonOverride
+	case len(w.AppliedAt) > len(c.AppliedAt):
+		return ReasonNearer
+	default:
+		return ReasonPrecedence
+	}
+}
+
+// wildcardMatch returns whether the given string matches the pattern, where
+// '*' in the pattern matches any number of characters, including '/'.
+func wildcardMatch(pattern, str string) bool {
+	p, s, star, mark := 0, 0, -1, 0
+
+	for s < len(str) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star, mark = p, s
+			p++
+		case p < len(pattern) && pattern[p] == str[s]:
+			p++
+			s++
+		case star >= 0:
+			mark++
+			p, s = star+1, mark
+		default:
+			return false
+		}
+	}
+
+	return strings.Trim(pattern[p:], "*") == ""
+}
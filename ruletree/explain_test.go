@@ -0,0 +1,120 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package ruletree
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/wtsi-hgi/backup-plans/db"
+	"github.com/wtsi-hgi/backup-plans/internal/testdb"
+)
+
+func TestExplain(t *testing.T) {
+	Convey("Given a tree DB", t, func() {
+		tdb := testdb.CreateTestDatabase(t)
+
+		root, err := NewRoot(nil)
+		So(err, ShouldBeNil)
+
+		_, err = root.AddTree(createTree(t, buildTreeDB(t, []string{
+			"/path/dir/a/file1.txt",
+			"/path/dir/a/b/file2.txt",
+		})))
+		So(err, ShouldBeNil)
+
+		explain := func(path string) (uint64, []string) {
+			e, err := root.Explain(path)
+			So(err, ShouldBeNil)
+
+			var (
+				winner     uint64
+				candidates []string
+			)
+
+			if e.Rule != nil {
+				So(e.Rule.Reason, ShouldBeBlank)
+
+				winner = uint64(e.Rule.Rule.ID()) //nolint:gosec
+			}
+
+			for _, c := range e.Candidates {
+				candidates = append(candidates, c.Directory+c.Rule.Match+": "+c.Reason)
+			}
+
+			return winner, candidates
+		}
+
+		Convey("Paths without a matching rule have no explanation", func() {
+			winner, candidates := explain("/path/dir/a/file1.txt")
+			So(winner, ShouldEqual, 0)
+			So(candidates, ShouldBeNil)
+
+			_, err := root.Explain("/other/file")
+			So(err, ShouldEqual, ErrNotFound)
+		})
+
+		Convey("You can see which rules lost by precedence or to a nearer directory", func() {
+			createRule(t, tdb, root, "/path/dir/", "*.txt")
+			r2 := createRule(t, tdb, root, "/path/dir/", "*1.txt")
+			r3 := createRule(t, tdb, root, "/path/dir/a/b/", "*")
+
+			winner, candidates := explain("/path/dir/a/file1.txt")
+			So(winner, ShouldEqual, r2)
+			So(candidates, ShouldResemble, []string{"/path/dir/*.txt: " + ReasonPrecedence})
+
+			winner, candidates = explain("/path/dir/a/b/file2.txt")
+			So(winner, ShouldEqual, r3)
+			So(candidates, ShouldResemble, []string{"/path/dir/*.txt: " + ReasonNearer})
+		})
+
+		Convey("You can see which rules lost to an override from a parent", func() {
+			r1 := createRule(t, tdb, root, "/path/dir/", "b/*", true)
+			r2 := createRule(t, tdb, root, "/path/dir/a/", "*")
+
+			winner, candidates := explain("/path/dir/a/b/file2.txt")
+			So(winner, ShouldEqual, r1)
+			So(candidates, ShouldResemble, []string{"/path/dir/a/*: " + ReasonOverride})
+
+			e, err := root.Explain("/path/dir/a/b/file2.txt")
+			So(err, ShouldBeNil)
+			So(e.Rule.Directory, ShouldEqual, "/path/dir/")
+			So(e.Rule.AppliedAt, ShouldEqual, "/path/dir/a/b/")
+
+			winner, candidates = explain("/path/dir/a/file1.txt")
+			So(winner, ShouldEqual, r2)
+			So(candidates, ShouldBeNil)
+		})
+	})
+
+	Convey("Candidates are not said to lose by precedence when no rule applies", t, func() {
+		a := &Candidate{Directory: "/path/dir/", AppliedAt: "/path/dir/", Rule: &db.Rule{Match: "*.txt"}}
+		b := &Candidate{Directory: "/path/dir/", AppliedAt: "/path/dir/", Rule: &db.Rule{Match: "*"}}
+
+		So((*Candidate)(nil).beat(b), ShouldEqual, ReasonNotApplied)
+		So(a.beat(b), ShouldEqual, ReasonPrecedence)
+	})
+}
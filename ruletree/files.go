@@ -0,0 +1,231 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package ruletree
+
+import (
+	"slices"
+	"strings"
+
+	"vimagination.zapto.org/tree"
+)
+
+// Files calls the given function with the path and stats of every file beneath
+// the given directory that is matched by the rule with the given ID. A rule ID
+// of 0 matches the files without a rule.
+//
+// The files are walked without holding the rule lock, so rules can be changed
+// while a walk is in progress; the walk uses the rules as they were when it
+// started.
+func (r *RootDir) Files(path string, ruleID int64, fn func(string, File)) error {
+	return r.walkFiles(path, &fileWalker{ruleID: ruleID, fn: fn})
+}
+
+// LastMod returns the newest modification time of the files beneath the given
+// directory that are matched by the rule with the given ID, ignoring the
+// contents of any directories with the given names (eg. ".git/").
+func (r *RootDir) LastMod(path string, ruleID int64, skipDirs ...string) (uint64, error) {
+	var lastMod uint64
+
+	err := r.walkFiles(path, &fileWalker{
+		ruleID:   ruleID,
+		skipDirs: skipDirs,
+		fn: func(_ string, file File) {
+			lastMod = max(lastMod, file.MTime)
+		},
+	})
+
+	return lastMod, err
+}
+
+func (r *RootDir) walkFiles(path string, fw *fileWalker) error {
+	mount, node, sm, err := r.startFileWalk(path)
+	if err != nil {
+		return err
+	}
+
+	defer r.treeMu.RUnlock()
+
+	rel := strings.TrimPrefix(path, mount)
+
+	for part := range pathParts(rel) {
+		if node, err = node.Child(part); err != nil {
+			return ErrNotFound
+		}
+	}
+
+	state, id := stateForDir(sm.GetStateString(mount), rel)
+
+	fw.walk(node, state, id, path)
+
+	return nil
+}
+
+// startFileWalk returns the mountpoint of the given path, along with the tree
+// DB and rule statemachine for that mountpoint, building the statemachine if the
+// rules have changed since it was last built.
+//
+// On success, treeMu is held for reading, stopping the tree DB from being
+// closed, and must be released once the walk is complete.
+func (r *RootDir) startFileWalk(path string) (string, *tree.MemTree, State, error) {
+	r.mu.RLock()
+	mount, lower, sm, ok, err := r.fileWalkState(path)
+
+	if ok {
+		r.treeMu.RLock()
+	}
+
+	r.mu.RUnlock()
+
+	if ok || err != nil {
+		return mount, lower, sm, err
+	}
+
+	r.buildMu.Lock()
+	defer r.buildMu.Unlock()
+
+	r.mu.RLock()
+	mount, lower, sm, ok, err = r.fileWalkState(path)
+	r.mu.RUnlock()
+
+	if err != nil {
+		return "", nil, State{}, err
+	}
+
+	if !ok {
+		if sm, _, err = generateStatemachineFor(mount, nil, r.directoryRules); err != nil {
+			return "", nil, State{}, err
+		}
+
+		r.mu.Lock()
+		r.fileStates[mount] = sm
+		r.mu.Unlock()
+	}
+
+	r.treeMu.RLock()
+
+	return mount, lower, sm, nil
+}
+
+// fileWalkState returns the mountpoint, tree DB and cached rule statemachine for
+// the given path. The returned bool is false when there is no cached
+// statemachine.
+//
+// Must be called with mu held.
+func (r *RootDir) fileWalkState(path string) (string, *tree.MemTree, State, bool, error) {
+	mount := r.GetMountPoint(path)
+
+	_, child, _, err := r.getOverlay(mount)
+	if err != nil {
+		return "", nil, State{}, false, err
+	}
+
+	sm, ok := r.fileStates[mount]
+
+	return mount, child.lower, sm, ok, nil
+}
+
+// stateForDir continues the given state through the parts of the given directory
+// path, returning the resulting state and the rule ID for the directory.
+func stateForDir(state State, dir string) (State, int64) {
+	id := processRules
+
+	for part := range pathParts(dir) {
+		if id != processRules {
+			break
+		}
+
+		state = state.GetStateString(part)
+		id = dirRuleID(state)
+	}
+
+	return state, id
+}
+
+// dirRuleID returns the rule ID for all files beneath a directory, as
+// determined by the ruleProcessor when there is no existing overlay, or
+// processRules if the files need to be matched individually.
+func dirRuleID(state State) int64 {
+	id := state.GetGroup()
+	if id == nil {
+		return processRules
+	}
+
+	if *id < 0 && *id != processRules {
+		return -*id - 1
+	}
+
+	return *id
+}
+
+type fileWalker struct {
+	ruleID   int64
+	skipDirs []string
+	fn       func(string, File)
+}
+
+func (f *fileWalker) walk(node *tree.MemTree, sm State, id int64, path string) {
+	if id != processRules && id != f.ruleID {
+		return
+	}
+
+	for name, child := range node.Children() {
+		childNode := child.(*tree.MemTree) //nolint:errcheck,forcetypeassert
+
+		if strings.HasSuffix(name, "/") {
+			if slices.Contains(f.skipDirs, name) {
+				continue
+			}
+
+			if id == processRules {
+				state := sm.GetStateString(name)
+
+				f.walk(childNode, state, dirRuleID(state), path+name)
+			} else {
+				f.walk(childNode, sm, id, path+name)
+			}
+
+			continue
+		}
+
+		if fileRuleID(sm, id, name) == f.ruleID {
+			f.fn(path+name, ReadFileStats(childNode))
+		}
+	}
+}
+
+// fileRuleID returns the ID of the rule matching the named file, given the
+// state and rule ID of its directory.
+func fileRuleID(sm State, id int64, name string) int64 {
+	if id != processRules {
+		return id
+	}
+
+	if rule := sm.GetStateString(name).GetGroup(); rule != nil {
+		return *rule
+	}
+
+	return 0
+}
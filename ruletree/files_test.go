@@ -0,0 +1,143 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package ruletree
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/wtsi-hgi/backup-plans/internal/directories"
+	"github.com/wtsi-hgi/backup-plans/internal/testdb"
+)
+
+func TestFiles(t *testing.T) {
+	Convey("Given a tree DB with rules", t, func() {
+		tdb := testdb.CreateTestDatabase(t)
+
+		root, err := NewRoot(nil)
+		So(err, ShouldBeNil)
+
+		_, err = root.AddTree(createTree(t, buildTreeDB(t, []string{
+			"/path/a.txt",
+			"/path/dir/b.txt",
+			"/path/dir/c.log",
+			"/path/dir/sub/d.txt",
+			"/path/dir/sub/e.log",
+			"/path/dir/other/f.txt",
+			"/path/other/g.txt",
+		})))
+		So(err, ShouldBeNil)
+
+		txt := int64(createRule(t, tdb, root, "/path/dir/", "*.txt"))   //nolint:gosec
+		all := int64(createRule(t, tdb, root, "/path/dir/other/", "*")) //nolint:gosec
+
+		files := func(path string, ruleID int64) []string {
+			var paths []string
+
+			So(root.Files(path, ruleID, func(path string, file File) {
+				So(file.Size, ShouldEqual, 3)
+
+				paths = append(paths, path)
+			}), ShouldBeNil)
+
+			slices.Sort(paths)
+
+			return paths
+		}
+
+		Convey("You can list the files matched by a rule", func() {
+			So(files("/path/dir/", txt), ShouldResemble, []string{
+				"/path/dir/b.txt",
+				"/path/dir/sub/d.txt",
+			})
+			So(files("/path/dir/sub/", txt), ShouldResemble, []string{"/path/dir/sub/d.txt"})
+			So(files("/path/dir/", all), ShouldResemble, []string{"/path/dir/other/f.txt"})
+			So(files("/path/", all), ShouldResemble, []string{"/path/dir/other/f.txt"})
+		})
+
+		Convey("You can list the files without a rule", func() {
+			So(files("/path/", 0), ShouldResemble, []string{
+				"/path/a.txt",
+				"/path/dir/c.log",
+				"/path/dir/sub/e.log",
+				"/path/other/g.txt",
+			})
+		})
+
+		Convey("Listings reflect rules changed since an earlier listing", func() {
+			So(files("/path/other/", 0), ShouldResemble, []string{"/path/other/g.txt"})
+
+			other := int64(createRule(t, tdb, root, "/path/other/", "*.txt")) //nolint:gosec
+
+			So(files("/path/other/", 0), ShouldBeEmpty)
+			So(files("/path/other/", other), ShouldResemble, []string{"/path/other/g.txt"})
+		})
+
+		Convey("Unknown directories return an error", func() {
+			So(root.Files("/path/missing/", 0, func(string, File) {}), ShouldEqual, ErrNotFound)
+			So(root.Files("/elsewhere/", 0, func(string, File) {}), ShouldEqual, ErrNotFound)
+		})
+	})
+}
+
+func TestLastMod(t *testing.T) {
+	Convey("Given a tree DB with a rule", t, func() {
+		tdb := testdb.CreateTestDatabase(t)
+
+		root, err := NewRoot(nil)
+		So(err, ShouldBeNil)
+
+		treeDB := directories.NewRoot("/path/", time.Now().Unix())
+
+		directories.AddFile(&treeDB.Directory, "repo/a.txt", 1, 2, 3, 100)
+		directories.AddFile(&treeDB.Directory, "repo/sub/b.txt", 1, 2, 3, 200)
+		directories.AddFile(&treeDB.Directory, "repo/c.log", 1, 2, 3, 300)
+		directories.AddFile(&treeDB.Directory, "repo/.git/index", 1, 2, 3, 400)
+
+		_, err = root.AddTree(createTree(t, treeDB))
+		So(err, ShouldBeNil)
+
+		txt := int64(createRule(t, tdb, root, "/path/repo/", "*.txt")) //nolint:gosec
+
+		Convey("You can get the newest modification time of the files matched by a rule", func() {
+			lastMod, err := root.LastMod("/path/repo/", txt)
+			So(err, ShouldBeNil)
+			So(lastMod, ShouldEqual, 200)
+
+			lastMod, err = root.LastMod("/path/", 0)
+			So(err, ShouldBeNil)
+			So(lastMod, ShouldEqual, 400)
+		})
+
+		Convey("You can ignore the files in named directories", func() {
+			lastMod, err := root.LastMod("/path/", 0, ".git/")
+			So(err, ShouldBeNil)
+			So(lastMod, ShouldEqual, 300)
+		})
+	})
+}
@@ -26,6 +26,7 @@
 package ruletree
 
 import (
+	"maps"
 	"path/filepath"
 	"slices"
 	"strings"
@@ -70,6 +71,77 @@ func (r *RootDir) GlobPaths(patterns ...string) []string {
 	return slices.Compact(res)
 }
 
+// Directories calls the given function with the path of every directory in the
+// trees, shallowest first, stopping when the function returns false.
+//
+// The rule lock is only held while the top-level directories are read, so rules
+// can be changed while the trees are walked.
+func (r *RootDir) Directories(fn func(string) bool) {
+	queue := []dirNode{r.topLevelDirNode()}
+
+	defer r.treeMu.RUnlock()
+
+	for len(queue) > 0 {
+		dir := queue[0]
+		queue = queue[1:]
+
+		if !fn(dir.path) {
+			return
+		}
+
+		queue = append(queue, dir.children...)
+
+		if dir.lower == nil {
+			continue
+		}
+
+		for name, child := range dir.lower.Children() {
+			if strings.HasSuffix(name, "/") {
+				queue = append(queue, dirNode{path: dir.path + name, lower: child.(*tree.MemTree)}) //nolint:errcheck,forcetypeassert,lll
+			}
+		}
+	}
+}
+
+// dirNode is a directory to be walked by Directories; either a top-level
+// directory, with its children, or a directory in a tree DB.
+type dirNode struct {
+	path     string
+	children []dirNode
+	lower    *tree.MemTree
+}
+
+// topLevelDirNode returns a copy of the top-level directories, with the tree DBs
+// beneath them.
+//
+// On return, treeMu is held for reading, stopping the tree DBs from being
+// closed, and must be released once the walk is complete.
+func (r *RootDir) topLevelDirNode() dirNode {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	r.treeMu.RLock()
+
+	return r.topLevelDir.dirNode("/")
+}
+
+func (t *topLevelDir) dirNode(path string) dirNode {
+	node := dirNode{path: path}
+
+	for _, name := range slices.Sorted(maps.Keys(t.children)) {
+		switch child := t.children[name].(type) {
+		case *topLevelDir:
+			node.children = append(node.children, child.dirNode(path+name))
+		case *ruleOverlay:
+			node.children = append(node.children, dirNode{path: path + name, lower: child.lower})
+		default:
+			node.children = append(node.children, dirNode{path: path + name})
+		}
+	}
+
+	return node
+}
+
 func (t *topLevelDir) glob(match string) []string {
 	if match == "" {
 		return []string{""}
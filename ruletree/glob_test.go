@@ -71,5 +71,34 @@ func TestGlob(t *testing.T) {
 				"/some/path/YourDir/c.tsv",
 			})
 		})
+
+		Convey("You can list the directories in it, shallowest first", func() {
+			var dirs []string
+
+			root.Directories(func(dir string) bool {
+				dirs = append(dirs, dir)
+
+				return true
+			})
+
+			So(dirs, ShouldResemble, []string{
+				"/",
+				"/some/",
+				"/some/path/",
+				"/some/path/MyDir/",
+				"/some/path/OtherDir/",
+				"/some/path/YourDir/",
+			})
+
+			dirs = dirs[:0]
+
+			root.Directories(func(dir string) bool {
+				dirs = append(dirs, dir)
+
+				return len(dirs) < 3
+			})
+
+			So(dirs, ShouldResemble, []string{"/", "/some/", "/some/path/"})
+		})
 	})
 }
@@ -52,11 +52,7 @@ func (d *DirSummary) setLastMod() {
 	newMod := uint64(0)
 
 	for _, ruleStats := range d.RuleSummaries {
-		for _, stat := range ruleStats.Users {
-			if stat.MTime > newMod {
-				newMod = stat.MTime
-			}
-		}
+		newMod = max(newMod, ruleStats.LastMod())
 	}
 
 	d.LastMod = newMod
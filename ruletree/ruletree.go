@@ -45,6 +45,18 @@ type Rule struct {
 	Users, Groups RuleStats
 }
 
+// LastMod returns the newest modification time of the files matched by the
+// rule.
+func (r *Rule) LastMod() uint64 {
+	var lastMod uint64
+
+	for _, stat := range r.Users {
+		lastMod = max(lastMod, stat.MTime)
+	}
+
+	return lastMod
+}
+
 func (r *Rule) writeTo(sw *byteio.StickyLittleEndianWriter) {
 	sw.WriteUintX(r.ID)
 	sw.WriteUintX(uint64(len(r.Users)))
@@ -569,6 +569,39 @@ func TestRuletree(t *testing.T) {
 			So(ruleIDCount(t, root, "/path/dir/a/"), ShouldResemble, map[uint64]uint64{0: 1, r2: 1})
 			So(ruleIDCount(t, root, "/path/dir/a/this/"), ShouldResemble, map[uint64]uint64{0: 1})
 		})
+
+		Convey("You can replace all of the rules at once", func() {
+			root, err := NewRoot(nil)
+			So(err, ShouldBeNil)
+
+			treeDB := buildTreeDB(t, []string{
+				"/path/dir/a/file.txt",
+				"/path/dir/b/file.csv",
+			})
+
+			treeDBPath := createTree(t, treeDB)
+			_, err = root.AddTree(treeDBPath)
+			So(err, ShouldBeNil)
+
+			r1 := createRule(t, tdb, root, "/path/dir/a/", "*.txt")
+			So(ruleIDCount(t, root, "/path/dir/"), ShouldResemble, map[uint64]uint64{0: 1, r1: 1})
+
+			dirB := &db.Directory{Path: "/path/dir/b/"}
+			So(tdb.CreateDirectory(dirB), ShouldBeNil)
+
+			ruleB := &db.Rule{Match: "*.csv"}
+			So(tdb.CreateDirectoryRule(dirB, ruleB), ShouldBeNil)
+
+			r2 := uint64(ruleB.ID()) //nolint:gosec
+
+			So(root.ReplaceRules([]DirRule{{Directory: dirB, Rule: ruleB}}), ShouldBeNil)
+			So(ruleIDCount(t, root, "/path/dir/"), ShouldResemble, map[uint64]uint64{0: 1, r2: 1})
+			So(ruleIDCount(t, root, "/path/dir/a/"), ShouldResemble, map[uint64]uint64{0: 1})
+			So(ruleIDCount(t, root, "/path/dir/b/"), ShouldResemble, map[uint64]uint64{r2: 1})
+
+			So(root.ReplaceRules(nil), ShouldBeNil)
+			So(ruleIDCount(t, root, "/path/dir/"), ShouldResemble, map[uint64]uint64{0: 2})
+		})
 	})
 }
 
@@ -392,12 +392,18 @@ func (r *RuleTree) resolveSlashes() { //nolint:gocognit,gocyclo,funlen
 // This is to allow for efficient tree traversal, only entering sub-trees that
 // contain relevant rules.
 func (r *RuleTree) MarkBackupDirs() {
-	r.markBackupDirs(false)
+	r.MarkDirsWithType(db.BackupIBackup)
 }
 
-func (r *RuleTree) markBackupDirs(parentWithBackup bool) {
+// MarkDirsWithType acts like MarkBackupDirs, but marks the directories that
+// contain rules of the given backup type.
+func (r *RuleTree) MarkDirsWithType(bt db.BackupType) {
+	r.markBackupDirs(false, bt)
+}
+
+func (r *RuleTree) markBackupDirs(parentWithBackup bool, bt db.BackupType) {
 	for _, rule := range r.Rules {
-		if parentWithBackup || rule.BackupType == db.BackupIBackup {
+		if parentWithBackup || rule.BackupType == bt {
 			r.HasBackup = true
 			parentWithBackup = true
 
@@ -406,7 +412,7 @@ func (r *RuleTree) markBackupDirs(parentWithBackup bool) {
 	}
 
 	for _, child := range r.children {
-		child.markBackupDirs(parentWithBackup)
+		child.markBackupDirs(parentWithBackup, bt)
 
 		if child.HasBackup || child.HasChildWithBackup {
 			r.HasChildWithBackup = true
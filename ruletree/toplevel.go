@@ -32,6 +32,7 @@ import (
 	"iter"
 	"maps"
 	"os"
+	"slices"
 	"strings"
 	"sync"
 
@@ -65,9 +66,11 @@ type RootDir struct {
 	mu             sync.RWMutex
 	directoryRules map[string]*DirRules
 	wildcards      map[string]group.State[int64]
+	fileStates     map[string]State
 	closers        map[string]func()
 
 	buildMu sync.Mutex
+	treeMu  sync.RWMutex
 }
 
 // DirRule is a combined Directory reference and Rule reference.
@@ -100,7 +103,8 @@ func NewRoot(rules []DirRule) (*RootDir, error) {
 				RuleSummaries: make([]Rule, 0),
 			},
 		},
-		wildcards: make(map[string]group.State[int64]),
+		wildcards:  make(map[string]group.State[int64]),
+		fileStates: make(map[string]State),
 	}
 
 	for _, dr := range rules {
@@ -156,16 +160,7 @@ func updateRule[T any](r *RootDir, dir *db.Directory, rule T,
 		return err
 	}
 
-	if err := r.regenRules(r.GetMountPoint(dir.Path), directoryRules, dir.Path); err != nil {
-		return err
-	}
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	r.directoryRules = directoryRules
-
-	return nil
+	return r.regenMounts(directoryRules, map[string][]string{r.GetMountPoint(dir.Path): {dir.Path}})
 }
 
 func (r *RootDir) cloneDirectoryRules() map[string]*DirRules {
@@ -230,38 +225,188 @@ func removeRule(directoryRules map[string]*DirRules, dir *db.Directory, rule *db
 	return nil
 }
 
-func (r *RootDir) regenRules(mount string, directoryRules map[string]*DirRules, dirs ...string) error {
+// RemoveRules removes the given rules from the given directory and regenerates
+// the rule summaries.
+func (r *RootDir) RemoveRules(dir *db.Directory, rules []*db.Rule) error {
+	return updateRule(r, dir, rules, removeRules)
+}
+
+func removeRules(directoryRules map[string]*DirRules, dir *db.Directory, rules []*db.Rule) error {
+	for _, rule := range rules {
+		if err := removeRule(directoryRules, dir, rule); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ApplyRules adds and removes the given rules, regenerating the rule summaries
+// once for each affected mountpoint.
+func (r *RootDir) ApplyRules(add, remove []DirRule) error {
+	r.buildMu.Lock()
+	defer r.buildMu.Unlock()
+
+	directoryRules := r.cloneDirectoryRules()
+	mounts := make(map[string][]string)
+
+	for _, dr := range remove {
+		if err := removeRule(directoryRules, dr.Directory, dr.Rule); err != nil {
+			return err
+		}
+
+		mounts[r.GetMountPoint(dr.Path)] = append(mounts[r.GetMountPoint(dr.Path)], dr.Path)
+	}
+
+	for _, dr := range add {
+		if err := addRule(directoryRules, dr.Directory, dr.Rule); err != nil {
+			return err
+		}
+
+		mounts[r.GetMountPoint(dr.Path)] = append(mounts[r.GetMountPoint(dr.Path)], dr.Path)
+	}
+
+	return r.regenMounts(directoryRules, mounts)
+}
+
+// ReplaceRules replaces all of the rules with the given rules, regenerating the
+// rule summaries for each mountpoint whose rules have changed.
+func (r *RootDir) ReplaceRules(rules []DirRule) error {
+	directoryRules := make(map[string]*DirRules)
+
+	for _, dr := range rules {
+		if err := addRule(directoryRules, dr.Directory, dr.Rule); err != nil {
+			return err
+		}
+	}
+
+	r.buildMu.Lock()
+	defer r.buildMu.Unlock()
+
+	r.mu.RLock()
+	paths := slices.Concat(slices.Collect(maps.Keys(r.directoryRules)), slices.Collect(maps.Keys(directoryRules)))
+	r.mu.RUnlock()
+
+	mounts := make(map[string][]string)
+
+	for _, path := range paths {
+		if mount := r.GetMountPoint(path); mount != "" {
+			mounts[mount] = append(mounts[mount], path)
+		}
+	}
+
+	return r.regenMounts(directoryRules, mounts)
+}
+
+// regenMounts regenerates the rule summaries for the given directories of each
+// of the given mountpoints, and only once all of them have been built swaps
+// them in, along with the given rules, so that no reader can see a partially
+// applied set of rules.
+//
+// Must be called with buildMu held.
+func (r *RootDir) regenMounts(directoryRules map[string]*DirRules, mounts map[string][]string) error {
+	regens := make([]*regen, 0, len(mounts))
+
+	for mount, dirs := range mounts {
+		slices.Sort(dirs)
+
+		rg, err := r.prepareRegen(mount, directoryRules, slices.Compact(dirs))
+		if err != nil {
+			return err
+		}
+
+		regens = append(regens, rg)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.directoryRules = directoryRules
+
+	for _, rg := range regens {
+		rg.overlay.upper = rg.upper
+		r.wildcards[rg.mount] = rg.wildcards
+
+		delete(r.fileStates, rg.mount)
+	}
+
+	for _, rg := range regens {
+		if err := rg.parent.setChild(rg.name, rg.overlay); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// regen holds the regenerated rule summaries for a mountpoint, ready to be
+// swapped in.
+type regen struct {
+	parent    *topLevelDir
+	overlay   *ruleOverlay
+	name      string
+	mount     string
+	upper     *tree.MemTree
+	wildcards group.State[int64]
+}
+
+func (r *RootDir) prepareRegen(mount string, directoryRules map[string]*DirRules, dirs []string) (*regen, error) {
+	t, child, name, err := r.getOverlay(mount)
+	if err != nil {
+		return nil, err
+	}
+
+	sm, wcs, err := generateStatemachineFor(mount, dirs, directoryRules)
+	if err != nil {
+		return nil, err
+	}
+
+	processed, err := processUpper(child, sm.GetStateString(mount))
+	if err != nil {
+		return nil, err
+	}
+
+	return &regen{
+		parent:    t,
+		overlay:   child,
+		name:      name,
+		mount:     mount,
+		upper:     processed,
+		wildcards: wcs.GetState(nil),
+	}, nil
+}
+
+// getOverlay returns the ruleOverlay for the given mountpoint, along with its
+// parent and its name in that parent.
+func (r *RootDir) getOverlay(mount string) (*topLevelDir, *ruleOverlay, string, error) {
+	if mount == "" {
+		return nil, nil, "", ErrNotFound
+	}
+
 	t := &r.topLevelDir
-	pos := 1
 
 	for part := range pathParts(mount[1:]) {
 		child := t.children[part]
 		if child == nil {
-			return ErrNotFound
+			return nil, nil, "", ErrNotFound
 		}
 
-		pos += len(part)
-
 		switch child := child.(type) {
 		case *topLevelDir:
 			t = child
 		case *ruleOverlay:
-			return r.regenRulesFor(t, child, dirs, directoryRules, mount, part)
+			return t, child, part, nil
 		default:
-			return ErrNotFound
+			return nil, nil, "", ErrNotFound
 		}
 	}
 
-	return ErrNotFound
+	return nil, nil, "", ErrNotFound
 }
 
-func (r *RootDir) regenRulesFor(t *topLevelDir, child *ruleOverlay, dirs []string, //nolint:funlen
-	directoryRules map[string]*DirRules, mount, name string) error {
-	sm, wcs, err := generateStatemachineFor(mount, dirs, directoryRules)
-	if err != nil {
-		return err
-	}
-
+// processUpper applies the rules in the given state to the lower tree of the
+// given overlay, returning a new upper tree.
+func processUpper(child *ruleOverlay, sm State) (*tree.MemTree, error) {
 	var (
 		rd ruleProcessor
 		wg sync.WaitGroup
@@ -269,31 +414,54 @@ func (r *RootDir) regenRulesFor(t *topLevelDir, child *ruleOverlay, dirs []strin
 
 	wg.Add(1)
 
-	rd.process(child.lower, child.upper, sm.GetStateString(mount), &wg)
+	rd.process(child.lower, child.upper, sm, &wg)
 
 	var buf bytes.Buffer
 
-	if err = tree.Serialise(&buf, &rd); err != nil {
-		return err
+	if err := tree.Serialise(&buf, &rd); err != nil {
+		return nil, err
 	}
 
-	processed, err := tree.OpenMem(buf.Bytes())
-	if err != nil {
-		return err
+	return tree.OpenMem(buf.Bytes())
+}
+
+// PreviewRules returns the summary, with children, of the given directory as it
+// would be if the given rules were added to, and removed from, it. The RootDir
+// is not changed.
+func (r *RootDir) PreviewRules(dir *db.Directory, add, remove []*db.Rule) (*DirSummary, error) {
+	r.buildMu.Lock()
+	defer r.buildMu.Unlock()
+
+	directoryRules := r.cloneDirectoryRules()
+
+	if err := removeRules(directoryRules, dir, remove); err != nil {
+		return nil, err
 	}
 
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	if err := addRules(directoryRules, dir, add); err != nil {
+		return nil, err
+	}
 
-	child.upper = processed
-	r.directoryRules = directoryRules
-	r.wildcards[mount] = wcs.GetState(nil)
+	mount := r.GetMountPoint(dir.Path)
 
-	if err = t.setChild(name, child); err != nil {
-		return err
+	_, child, _, err := r.getOverlay(mount)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	sm, wcs, err := generateStatemachineFor(mount, []string{dir.Path}, directoryRules)
+	if err != nil {
+		return nil, err
+	}
+
+	processed, err := processUpper(child, sm.GetStateString(mount))
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &ruleOverlay{child.lower, processed}
+
+	return preview.Summary(strings.TrimPrefix(dir.Path, mount), wcs.GetStateString(mount))
 }
 
 // Summary returns a Dirsummary for the directory denoted by the given path.
@@ -538,20 +706,27 @@ func (r *RootDir) AddTree(file string) (string, error) { //nolint:funlen,unparam
 	}
 
 	r.mu.Lock()
-	defer r.mu.Unlock()
 
 	if err = createTopLevelDirs(processed, rootPath, &r.topLevelDir); err != nil {
-		return "", err
-	}
+		r.mu.Unlock()
 
-	if existing, ok := r.closers[rootPath]; ok {
-		existing()
+		return "", err
 	}
 
+	existing, ok := r.closers[rootPath]
 	r.closers[rootPath] = closer
-
 	r.wildcards[rootPath] = wcs.GetState(nil)
 
+	r.mu.Unlock()
+
+	if ok {
+		// Wait for any walks of the old tree DB, which only hold treeMu, to
+		// finish before closing it.
+		r.treeMu.Lock()
+		existing()
+		r.treeMu.Unlock()
+	}
+
 	return rootPath, nil
 }
 
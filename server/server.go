@@ -26,13 +26,18 @@
 package server
 
 import (
+	"cmp"
+	"context"
+	"crypto/tls"
 	"errors"
 	"log"
 	"log/slog"
 	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"slices"
+	"syscall"
 	"time"
 
 	"github.com/wtsi-hgi/backup-plans/backend"
@@ -46,26 +51,72 @@ var dbCheckTime = time.Minute //nolint:gochecknoglobals
 
 var ErrNoTrees = errors.New("no tree dbs specified")
 
+const (
+	defaultReadHeaderTimeout = 10 * time.Second
+	defaultReadTimeout       = time.Minute
+	defaultWriteTimeout      = 5 * time.Minute
+	defaultIdleTimeout       = 2 * time.Minute
+	defaultShutdownTimeout   = 30 * time.Second
+)
+
+// Options configures the HTTP server.
+//
+// If CertFile and KeyFile are set, the server will use TLS; the certificate is
+// reloaded when either file changes.
+//
+// Any unset timeout is given a default value: 10s for ReadHeaderTimeout, 1m
+// for ReadTimeout, 5m for WriteTimeout, 2m for IdleTimeout, and 30s for
+// ShutdownTimeout, which is how long to wait for open connections to finish
+// when shutting down.
+type Options struct {
+	Addr              string
+	CertFile, KeyFile string
+
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	ShutdownTimeout   time.Duration
+}
+
 // Start creates and start a new server after loading the trees given.
-func Start(listen string, d *db.DB, getUser func(*http.Request) string,
+//
+// Once serving, on receiving a SIGTERM or SIGINT, the server stops accepting
+// connections, waits for open connections to finish, stops all caches, and
+// returns. Signals received while the trees are loading terminate the process
+// as normal.
+func Start(opts Options, d *db.DB, getUser func(*http.Request) string,
 	logout http.Handler, config *config.Config, initialTrees ...string) error {
-	l, err := net.Listen("tcp", listen) //nolint:noctx
+	l, err := net.Listen("tcp", opts.Addr) //nolint:noctx
 	if err != nil {
 		log.Fatalf("Failed to listen: %v", err)
 	}
 	defer l.Close()
 
-	return start(l, d, getUser, logout, config, initialTrees...)
+	return start(context.Background(), l, opts, d, getUser, logout, config, initialTrees...)
 }
 
-func start(listen net.Listener, d *db.DB, getUser func(*http.Request) string,
-	logout http.Handler, config *config.Config, initialTrees ...string) error {
+func start(ctx context.Context, listen net.Listener, opts Options, d *db.DB, //nolint:funlen
+	getUser func(*http.Request) string, logout http.Handler, config *config.Config, initialTrees ...string) error {
+	srv, err := newHTTPServer(opts)
+	if err != nil {
+		return err
+	}
+
 	b, err := backend.New(d, getUser, config)
 	if err != nil {
 		return err
 	}
 
-	err = loadTrees(initialTrees, b)
+	defer func() {
+		b.Stop()
+		config.Close()
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	err = loadTrees(ctx, initialTrees, b)
 	if err != nil {
 		return err
 	}
@@ -77,37 +128,142 @@ func start(listen net.Listener, d *db.DB, getUser func(*http.Request) string,
 		return err
 	}
 
-	return addHandlesAndListen(b, listen, logout)
+	srv.Handler = newMux(b, logout)
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	return serve(ctx, srv, listen, opts)
+}
+
+func newHTTPServer(opts Options) (*http.Server, error) {
+	srv := &http.Server{
+		ReadHeaderTimeout: cmp.Or(opts.ReadHeaderTimeout, defaultReadHeaderTimeout),
+		ReadTimeout:       cmp.Or(opts.ReadTimeout, defaultReadTimeout),
+		WriteTimeout:      cmp.Or(opts.WriteTimeout, defaultWriteTimeout),
+		IdleTimeout:       cmp.Or(opts.IdleTimeout, defaultIdleTimeout),
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+	}
+
+	if opts.CertFile == "" && opts.KeyFile == "" {
+		return srv, nil
+	}
+
+	certs, err := newCertReloader(opts.CertFile, opts.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	srv.TLSConfig = &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: certs.GetCertificate,
+	}
+
+	return srv, nil
+}
+
+// Handler returns a handler serving the API of the given backend, for use
+// without a listener, such as by the plan command when working directly with a
+// plan database.
+func Handler(b *backend.Server) http.Handler {
+	return newMux(b, http.NotFoundHandler())
+}
+
+func newMux(b *backend.Server, logout http.Handler) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.Handle("GET /api/whoami", http.HandlerFunc(b.WhoAmI))
+	mux.Handle("GET /api/tree", http.HandlerFunc(b.Tree))
+	mux.Handle("GET /api/files", http.HandlerFunc(b.Files))
+	mux.Handle("GET /api/explain", http.HandlerFunc(b.Explain))
+	mux.Handle("GET /api/search", http.HandlerFunc(b.Search))
+	mux.Handle("POST /api/dir/claim", http.HandlerFunc(b.ClaimDir))
+	mux.Handle("POST /api/dir/pass", http.HandlerFunc(b.PassDirClaim))
+	mux.Handle("POST /api/dir/revoke", http.HandlerFunc(b.RevokeDirClaim))
+	mux.Handle("POST /api/rules/create", http.HandlerFunc(b.CreateRule))
+	mux.Handle("POST /api/rules/update", http.HandlerFunc(b.UpdateRule))
+	mux.Handle("POST /api/rules/remove", http.HandlerFunc(b.RemoveRule))
+	mux.Handle("POST /api/rules/bulk", http.HandlerFunc(b.BulkRules))
+	mux.Handle("POST /api/rules/preview", http.HandlerFunc(b.PreviewRule))
+	mux.Handle("GET /api/templates", http.HandlerFunc(b.Templates))
+	mux.Handle("POST /api/templates/create", http.HandlerFunc(b.CreateTemplate))
+	mux.Handle("POST /api/templates/update", http.HandlerFunc(b.UpdateTemplate))
+	mux.Handle("POST /api/templates/remove", http.HandlerFunc(b.RemoveTemplate))
+	mux.Handle("POST /api/templates/apply", http.HandlerFunc(b.ApplyTemplate))
+	mux.Handle("POST /api/templates/sync", http.HandlerFunc(b.SyncTemplate))
+	mux.Handle("GET /api/report/summary", http.HandlerFunc(b.Summary))
+	mux.Handle("GET /api/report/summary.json", http.HandlerFunc(b.SummaryJSON))
+	mux.Handle("GET /api/report/summary.csv", http.HandlerFunc(b.SummaryCSV))
+	mux.Handle("GET /api/report/summary.ods", http.HandlerFunc(b.SummaryODS))
+	mux.Handle("GET /api/report/history", http.HandlerFunc(b.ReportHistory))
+	mux.Handle("GET /api/report/unplanned", http.HandlerFunc(b.Unplanned))
+	mux.Handle("GET /api/setExists", http.HandlerFunc(b.SetExists))
+	mux.Handle("POST /api/dir/setdetails", http.HandlerFunc(b.SetDirDetails))
+	mux.Handle("GET /api/usergroups", http.HandlerFunc(b.UserGroups))
+	mux.Handle("GET /api/mainprogrammes", http.HandlerFunc(b.GetMainProgrammes))
+	mux.Handle("POST /api/claimstats", http.HandlerFunc(b.ClaimStats))
+	mux.Handle("GET /api/claimstats.json", http.HandlerFunc(b.ClaimStatsJSON))
+	mux.Handle("GET /api/claimstats.csv", http.HandlerFunc(b.ClaimStatsCSV))
+	mux.Handle("GET /api/claimstats.ods", http.HandlerFunc(b.ClaimStatsODS))
+	mux.Handle("GET /api/config/status", http.HandlerFunc(b.ConfigStatus))
+	mux.Handle("POST /api/admin/reassign", http.HandlerFunc(b.AdminReassign))
+	mux.Handle("POST /api/admin/rules/remove", http.HandlerFunc(b.AdminRemoveRule))
+	mux.Handle("POST /api/admin/unfreeze", http.HandlerFunc(b.AdminUnfreeze))
+	mux.Handle("POST /api/admin/revoke", http.HandlerFunc(b.AdminRevoke))
+	mux.Handle("GET /api/admin/audit", http.HandlerFunc(b.AdminAudit))
+	mux.Handle("GET /api/openapi.json", http.HandlerFunc(serveOpenAPI))
+	mux.Handle("GET /", frontend.Index)
+	mux.Handle("GET /logout", logout)
+
+	return mux
 }
 
-func addHandlesAndListen(b *backend.Server, listen net.Listener, logout http.Handler) error {
-	http.Handle("GET /api/whoami", http.HandlerFunc(b.WhoAmI))
-	http.Handle("GET /api/tree", http.HandlerFunc(b.Tree))
-	http.Handle("POST /api/dir/claim", http.HandlerFunc(b.ClaimDir))
-	http.Handle("POST /api/dir/pass", http.HandlerFunc(b.PassDirClaim))
-	http.Handle("POST /api/dir/revoke", http.HandlerFunc(b.RevokeDirClaim))
-	http.Handle("POST /api/rules/create", http.HandlerFunc(b.CreateRule))
-	http.Handle("POST /api/rules/update", http.HandlerFunc(b.UpdateRule))
-	http.Handle("POST /api/rules/remove", http.HandlerFunc(b.RemoveRule))
-	http.Handle("GET /api/report/summary", http.HandlerFunc(b.Summary))
-	http.Handle("GET /api/setExists", http.HandlerFunc(b.SetExists))
-	http.Handle("POST /api/dir/setdetails", http.HandlerFunc(b.SetDirDetails))
-	http.Handle("GET /api/usergroups", http.HandlerFunc(b.UserGroups))
-	http.Handle("GET /api/mainprogrammes", http.HandlerFunc(b.GetMainProgrammes))
-	http.Handle("POST /api/claimstats", http.HandlerFunc(b.ClaimStats))
-	http.Handle("GET /", frontend.Index)
-	http.Handle("GET /logout", logout)
+// serve serves HTTP, or HTTPS if TLS is configured, on the given listener until
+// the context is cancelled, at which point the server is shut down gracefully.
+func serve(ctx context.Context, srv *http.Server, listen net.Listener, opts Options) error {
+	errCh := make(chan error, 1)
 
 	slog.Info("Serving...")
 
-	return http.Serve(listen, nil) //nolint:gosec
+	go func() {
+		if srv.TLSConfig != nil {
+			errCh <- srv.ServeTLS(listen, "", "")
+		} else {
+			errCh <- srv.Serve(listen)
+		}
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down...")
+
+	sctx, cancel := context.WithTimeout(context.Background(), cmp.Or(opts.ShutdownTimeout, defaultShutdownTimeout))
+	defer cancel()
+
+	if err := srv.Shutdown(sctx); err != nil {
+		return err
+	}
+
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
 }
 
-func loadTrees(initialTrees []string, b *backend.Server) error {
-	if len(initialTrees) != 1 {
-		loadDBs(b, initialTrees)
-	} else if len(initialTrees) == 0 {
+func loadTrees(ctx context.Context, initialTrees []string, b *backend.Server) error {
+	switch len(initialTrees) {
+	case 0:
 		return ErrNoTrees
+	case 1:
+	default:
+		loadDBs(b, initialTrees)
+
+		return nil
 	}
 
 	path := initialTrees[0]
@@ -130,23 +286,39 @@ func loadTrees(initialTrees []string, b *backend.Server) error {
 
 	loadDBs(b, treePaths)
 
-	go timerLoop(path, b, treePaths)
+	go timerLoop(ctx, path, b, treePaths)
 
 	return nil
 }
 
+// loadDBs loads the given trees and then, if any of them loaded, stores a single
+// snapshot of the report totals covering all of them.
 func loadDBs(b *backend.Server, trees []string) {
+	var loaded bool
+
 	for _, db := range trees {
-		loadDB(b, db)
+		loaded = loadDB(b, db) || loaded
+	}
+
+	if !loaded {
+		return
+	}
+
+	if err := b.SnapshotReport(); err != nil {
+		slog.Error("Error storing report snapshot", "err", err)
 	}
 }
 
-func loadDB(b *backend.Server, db string) {
+func loadDB(b *backend.Server, db string) bool {
 	slog.Info("Loading Tree", "db", db)
 
 	if err := b.AddTree(db); err != nil {
 		slog.Error("Error loading db", "db", db, "err", err)
+
+		return false
 	}
+
+	return true
 }
 
 // getTreePaths will, for a given dir, return a slice of filepaths to all
@@ -167,10 +339,14 @@ func getTreePaths(path string) ([]string, error) {
 }
 
 // timerLoop will, given a path to a directory, check for and load all new trees
-// in the directory.
-func timerLoop(path string, b *backend.Server, treePaths []string) {
+// in the directory, until the context is cancelled.
+func timerLoop(ctx context.Context, path string, b *backend.Server, treePaths []string) {
 	for {
-		time.Sleep(dbCheckTime)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(dbCheckTime):
+		}
 
 		newPaths, err := getTreePaths(path)
 		if err != nil {
@@ -179,14 +355,18 @@ func timerLoop(path string, b *backend.Server, treePaths []string) {
 			continue
 		}
 
+		var toLoad []string
+
 		for _, path := range newPaths {
-			if slices.Contains(treePaths, path) {
-				continue
+			if !slices.Contains(treePaths, path) {
+				toLoad = append(toLoad, path)
 			}
+		}
 
-			loadDB(b, path)
+		if len(toLoad) > 0 {
+			loadDBs(b, toLoad)
 
-			treePaths = append(treePaths, path)
+			treePaths = append(treePaths, toLoad...)
 		}
 	}
 }
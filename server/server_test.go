@@ -27,18 +27,34 @@
 package server
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"io"
+	"math/big"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/user"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/wtsi-hgi/backup-plans/backend"
 	"github.com/wtsi-hgi/backup-plans/internal/config"
 	"github.com/wtsi-hgi/backup-plans/internal/directories"
 	"github.com/wtsi-hgi/backup-plans/internal/testdb"
@@ -64,6 +80,33 @@ func TestSeverDBUpdate(t *testing.T) {
 		writeDB(t, dirA, tmp, "001_somePath")
 		writeDB(t, dirB, tmp, "001_otherPath")
 
+		Convey("A report snapshot is only stored when a tree loads", func() {
+			b, err := backend.New(testdb.CreateTestDatabase(t), func(*http.Request) string { return u.Username },
+				config.NewConfig(t, nil, nil, []string{"/some/path/"}, 0, nil))
+			So(err, ShouldBeNil)
+
+			Reset(b.Stop)
+
+			history := func() []backend.ReportSnapshot {
+				w := httptest.NewRecorder()
+
+				b.ReportHistory(w, httptest.NewRequest(http.MethodGet, "/api/report/history", nil))
+				So(w.Code, ShouldEqual, http.StatusOK)
+
+				var snapshots []backend.ReportSnapshot
+
+				So(json.NewDecoder(w.Body).Decode(&snapshots), ShouldBeNil)
+
+				return snapshots
+			}
+
+			loadDBs(b, []string{filepath.Join(tmp, "missing", "tree.db")})
+			So(history(), ShouldBeEmpty)
+
+			loadDBs(b, []string{filepath.Join(tmp, "missing", "tree.db"), filepath.Join(tmp, "001_somePath", "tree.db")})
+			So(history(), ShouldHaveLength, 1)
+		})
+
 		Convey("You can create a server", func() {
 			l, err := net.Listen("tcp", ":0") //nolint:gosec,noctx
 			So(err, ShouldBeNil)
@@ -71,10 +114,15 @@ func TestSeverDBUpdate(t *testing.T) {
 			tdb := testdb.CreateTestDatabase(t)
 			errCh := make(chan error)
 			dbCheckTime = time.Second
-			cfg := config.NewConfig(t, nil, nil, nil, 0, nil)
+			cfg := config.NewConfig(t, nil, nil, []string{"/some/path/", "/some/other/path/"}, 0, nil)
+
+			ctx, cancel := context.WithCancel(context.Background())
+
+			Reset(cancel)
 
 			go func() {
-				errCh <- start(l, tdb, func(*http.Request) string { return u.Username }, http.NotFoundHandler(), cfg, tmp)
+				errCh <- start(ctx, l, Options{}, tdb, func(*http.Request) string { return u.Username },
+					http.NotFoundHandler(), cfg, tmp)
 			}()
 
 			baseURL := fmt.Sprintf("http://127.0.0.1:%d/", l.Addr().(*net.TCPAddr).Port) //nolint:errcheck,forcetypeassert
@@ -95,6 +143,19 @@ func TestSeverDBUpdate(t *testing.T) {
 
 			tick.Stop()
 
+			Convey("A single report snapshot covers all of the initial trees", func() {
+				r, err := http.Get(baseURL + "api/report/history") //nolint:noctx
+				So(err, ShouldBeNil)
+
+				var snapshots []backend.ReportSnapshot
+
+				So(json.NewDecoder(r.Body).Decode(&snapshots), ShouldBeNil)
+				So(r.Body.Close(), ShouldBeNil)
+				So(len(snapshots), ShouldEqual, 1)
+				So(snapshots[0].Roots, ShouldContainKey, "/some/path/")
+				So(snapshots[0].Roots, ShouldContainKey, "/some/other/path/")
+			})
+
 			Convey("You can update a database", func() {
 				r, err := http.Get(baseURL + "api/tree?dir=/some/path/myData/") //nolint:noctx
 				So(err, ShouldBeNil)
@@ -129,6 +190,147 @@ func TestSeverDBUpdate(t *testing.T) {
 	})
 }
 
+func TestServerTLS(t *testing.T) {
+	Convey("Given TLS certificate and key files", t, func() {
+		u, err := user.Current()
+		So(err, ShouldBeNil)
+
+		tmp := t.TempDir()
+		certFile := filepath.Join(tmp, "cert.pem")
+		keyFile := filepath.Join(tmp, "key.pem")
+
+		writeCert(t, certFile, keyFile, 1)
+
+		trees := t.TempDir()
+
+		writeDB(t, directories.NewRoot("/some/path/", 1000), trees, "001_somePath")
+
+		certCheckInterval = 0
+
+		Convey("You can start multiple TLS servers and shut them down gracefully", func() {
+			for range 2 {
+				l, err := net.Listen("tcp", "127.0.0.1:0") //nolint:noctx
+				So(err, ShouldBeNil)
+
+				ctx, cancel := context.WithCancel(context.Background())
+				errCh := make(chan error, 1)
+
+				go func() {
+					errCh <- start(ctx, l, Options{CertFile: certFile, KeyFile: keyFile}, testdb.CreateTestDatabase(t),
+						func(*http.Request) string { return u.Username }, http.NotFoundHandler(),
+						config.NewConfig(t, nil, nil, nil, 0, nil), trees)
+				}()
+
+				client := &http.Client{Transport: &http.Transport{
+					TLSClientConfig:   &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+					DisableKeepAlives: true,
+				}}
+				url := "https://" + l.Addr().String() + "/api/whoami"
+
+				var resp *http.Response
+
+				for range 100 {
+					if resp, err = client.Get(url); err == nil { //nolint:noctx
+						break
+					}
+
+					time.Sleep(100 * time.Millisecond)
+				}
+
+				So(err, ShouldBeNil)
+				So(resp.StatusCode, ShouldEqual, http.StatusOK)
+				So(resp.TLS.PeerCertificates[0].SerialNumber.Int64(), ShouldEqual, 1)
+				So(resp.Body.Close(), ShouldBeNil)
+
+				writeCert(t, certFile, keyFile, 2)
+				So(os.Chtimes(certFile, time.Now(), time.Now().Add(time.Minute)), ShouldBeNil)
+
+				resp, err = client.Get(url) //nolint:noctx
+				So(err, ShouldBeNil)
+				So(resp.TLS.PeerCertificates[0].SerialNumber.Int64(), ShouldEqual, 2)
+				So(resp.Body.Close(), ShouldBeNil)
+
+				writeCert(t, certFile, keyFile, 1)
+
+				cancel()
+
+				So(<-errCh, ShouldBeNil)
+			}
+		})
+	})
+}
+
+func TestOpenAPI(t *testing.T) {
+	Convey("The OpenAPI document describes every route", t, func() {
+		var doc struct {
+			Paths map[string]map[string]json.RawMessage `json:"paths"`
+		}
+
+		So(json.Unmarshal(openAPI, &doc), ShouldBeNil)
+
+		documented := make(map[string]bool)
+
+		for path, methods := range doc.Paths {
+			for method := range methods {
+				documented[strings.ToUpper(method)+" "+path] = true
+			}
+		}
+
+		f, err := parser.ParseFile(token.NewFileSet(), "server.go", nil, 0)
+		So(err, ShouldBeNil)
+
+		routes := make(map[string]bool)
+
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+
+			if sel, ok := call.Fun.(*ast.SelectorExpr); !ok || sel.Sel.Name != "Handle" {
+				return true
+			}
+
+			if lit, ok := call.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				pattern, err := strconv.Unquote(lit.Value)
+				So(err, ShouldBeNil)
+
+				routes[pattern] = true
+			}
+
+			return true
+		})
+
+		So(routes, ShouldNotBeEmpty)
+		So(documented, ShouldResemble, routes)
+	})
+}
+
+func writeCert(t *testing.T, certFile, keyFile string, serial int64) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	So(err, ShouldBeNil)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	So(err, ShouldBeNil)
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	So(err, ShouldBeNil)
+
+	So(os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600), ShouldBeNil)
+	So(os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600), ShouldBeNil)
+}
+
 func writeDB(t *testing.T, root *directories.Root, base, path string) {
 	t.Helper()
 
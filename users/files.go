@@ -0,0 +1,329 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package users
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrInvalidLine = errors.New("invalid line")
+
+const (
+	passwdFields = 7
+	groupFields  = 4
+)
+
+type snapshot struct {
+	users     map[uint32]string
+	uids      map[string]uint32
+	groups    map[uint32]string
+	userGroup map[string][]uint32
+}
+
+func newSnapshot() *snapshot {
+	return &snapshot{
+		users:     make(map[uint32]string),
+		uids:      make(map[string]uint32),
+		groups:    make(map[uint32]string),
+		userGroup: make(map[string][]uint32),
+	}
+}
+
+func (s *snapshot) addUser(name string, uid uint32) {
+	s.users[uid] = name
+	s.uids[name] = uid
+}
+
+func (s *snapshot) addUserGroup(name string, gid uint32) {
+	for _, g := range s.userGroup[name] {
+		if g == gid {
+			return
+		}
+	}
+
+	s.userGroup[name] = append(s.userGroup[name], gid)
+}
+
+// watched is a snapshot parsed from a set of files, which is reparsed when the
+// modification time of any of the files changes.
+type watched struct {
+	paths []string
+	parse func(files [][]byte) (*snapshot, error)
+
+	mu     sync.Mutex
+	mtimes []time.Time
+	snap   *snapshot
+}
+
+func (w *watched) get() (*snapshot, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	mtimes := make([]time.Time, len(w.paths))
+
+	for n, path := range w.paths {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return w.stale(err)
+		}
+
+		mtimes[n] = fi.ModTime()
+	}
+
+	if w.snap != nil && slices.EqualFunc(mtimes, w.mtimes, time.Time.Equal) {
+		return w.snap, nil
+	}
+
+	files := make([][]byte, len(w.paths))
+
+	for n, path := range w.paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return w.stale(err)
+		}
+
+		files[n] = data
+	}
+
+	snap, err := w.parse(files)
+	if err != nil {
+		return w.stale(err)
+	}
+
+	w.snap = snap
+	w.mtimes = mtimes
+
+	return snap, nil
+}
+
+// stale returns the previously loaded snapshot, if there is one, when the files
+// can no longer be read.
+func (w *watched) stale(err error) (*snapshot, error) {
+	if w.snap == nil {
+		return nil, err
+	}
+
+	slog.Warn("error reloading identity files", "paths", w.paths, "err", err)
+
+	return w.snap, nil
+}
+
+// Username implements Provider.
+func (w *watched) Username(uid uint32) (string, error) {
+	snap, err := w.get()
+	if err != nil {
+		return "", err
+	}
+
+	name, ok := snap.users[uid]
+	if !ok {
+		return "", fmt.Errorf("%w: uid %d", ErrNotFound, uid)
+	}
+
+	return name, nil
+}
+
+// Group implements Provider.
+func (w *watched) Group(gid uint32) (string, error) {
+	snap, err := w.get()
+	if err != nil {
+		return "", err
+	}
+
+	name, ok := snap.groups[gid]
+	if !ok {
+		return "", fmt.Errorf("%w: gid %d", ErrNotFound, gid)
+	}
+
+	return name, nil
+}
+
+// IDs implements Provider.
+func (w *watched) IDs(username string) (uint32, []uint32, error) {
+	snap, err := w.get()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	uid, ok := snap.uids[username]
+	if !ok {
+		return 0, nil, fmt.Errorf("%w: user %s", ErrNotFound, username)
+	}
+
+	return uid, append([]uint32{}, snap.userGroup[username]...), nil
+}
+
+// Names implements Lister.
+func (w *watched) Names() (map[uint32]string, map[uint32]string, error) {
+	snap, err := w.get()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return maps.Clone(snap.users), maps.Clone(snap.groups), nil
+}
+
+// Files is a Provider that reads users and groups from files in the passwd(5)
+// and group(5) formats.
+type Files struct {
+	watched
+}
+
+// NewFiles returns a Files provider for the given passwd and group files.
+func NewFiles(passwd, group string) *Files {
+	return &Files{watched{paths: []string{passwd, group}, parse: parsePasswdGroup}}
+}
+
+func parsePasswdGroup(files [][]byte) (*snapshot, error) {
+	snap := newSnapshot()
+
+	if err := eachLine(files[0], passwdFields, func(fields []string) error {
+		uid, err := strconv.ParseUint(fields[2], 10, 32)
+		if err != nil {
+			return err
+		}
+
+		gid, err := strconv.ParseUint(fields[3], 10, 32)
+		if err != nil {
+			return err
+		}
+
+		snap.addUser(fields[0], uint32(uid))
+		snap.addUserGroup(fields[0], uint32(gid))
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("passwd: %w", err)
+	}
+
+	if err := eachLine(files[1], groupFields, func(fields []string) error {
+		gid, err := strconv.ParseUint(fields[2], 10, 32)
+		if err != nil {
+			return err
+		}
+
+		snap.groups[uint32(gid)] = fields[0]
+
+		for member := range strings.SplitSeq(fields[3], ",") {
+			if _, ok := snap.uids[member]; ok {
+				snap.addUserGroup(member, uint32(gid))
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("group: %w", err)
+	}
+
+	return snap, nil
+}
+
+func eachLine(data []byte, numFields int, fn func([]string) error) error {
+	s := bufio.NewScanner(bytes.NewReader(data))
+
+	for line := 1; s.Scan(); line++ {
+		text := strings.TrimSpace(s.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, ":")
+		if len(fields) != numFields {
+			return fmt.Errorf("%w %d", ErrInvalidLine, line)
+		}
+
+		if err := fn(fields); err != nil {
+			return fmt.Errorf("%w %d: %w", ErrInvalidLine, line, err)
+		}
+	}
+
+	return s.Err()
+}
+
+// JSONMapping is the format of the file read by the JSON provider.
+//
+//	{
+//		"users": [{"name": "user", "uid": 1000, "gids": [1000, 2000]}],
+//		"groups": [{"name": "group", "gid": 2000}]
+//	}
+type JSONMapping struct {
+	Users []struct {
+		Name string
+		UID  uint32
+		GIDs []uint32
+	}
+	Groups []struct {
+		Name string
+		GID  uint32
+	}
+}
+
+// JSON is a Provider that reads users and groups from a file in the
+// JSONMapping format.
+type JSON struct {
+	watched
+}
+
+// NewJSON returns a JSON provider for the given file.
+func NewJSON(path string) *JSON {
+	return &JSON{watched{paths: []string{path}, parse: parseJSONMapping}}
+}
+
+func parseJSONMapping(files [][]byte) (*snapshot, error) {
+	var mapping JSONMapping
+
+	if err := json.Unmarshal(files[0], &mapping); err != nil {
+		return nil, err
+	}
+
+	snap := newSnapshot()
+
+	for _, u := range mapping.Users {
+		snap.addUser(u.Name, u.UID)
+
+		for _, gid := range u.GIDs {
+			snap.addUserGroup(u.Name, gid)
+		}
+	}
+
+	for _, g := range mapping.Groups {
+		snap.groups[g.GID] = g.Name
+	}
+
+	return snap, nil
+}
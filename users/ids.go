@@ -26,8 +26,6 @@
 package users
 
 import (
-	"os/user"
-	"strconv"
 	"time"
 )
 
@@ -47,46 +45,22 @@ func GetIDs(username string) (uint32, []uint32) {
 		return gc.uid, gc.groups
 	}
 
-	uid, gids := getUserData(username)
-	if gids == nil {
-		return 0, nil
-	}
-
-	userGroupsCache.Set(username, groups{
-		expiry: time.Now().Add(time.Hour),
-		uid:    uid,
-		groups: gids,
-	})
-
-	return uid, gids
-}
-
-func getUserData(username string) (uint32, []uint32) {
-	u, err := user.Lookup(username)
-	if err != nil {
-		return 0, nil
-	}
+	uid, gids, err := getProvider().IDs(username)
+	if err != nil || gids == nil {
+		ttl := negativeTTL()
 
-	uid, err := strconv.ParseUint(u.Uid, 10, 32)
-	if err != nil {
-		return 0, nil
-	}
+		userGroupsCache.setFor(username, groups{expiry: time.Now().Add(ttl)}, ttl)
 
-	gids, err := u.GroupIds()
-	if err != nil {
 		return 0, nil
 	}
 
-	gs := make([]uint32, 0, len(gids))
-
-	for _, gid := range gids {
-		g, err := strconv.ParseUint(gid, 10, 32)
-		if err != nil {
-			return 0, nil
-		}
+	ttl := positiveTTL()
 
-		gs = append(gs, uint32(g))
-	}
+	userGroupsCache.setFor(username, groups{
+		expiry: time.Now().Add(ttl),
+		uid:    uid,
+		groups: gids,
+	}, ttl)
 
-	return uint32(uid), gs
+	return uid, gids
 }
@@ -0,0 +1,274 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package users
+
+import (
+	"errors"
+	"fmt"
+	"os/user"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Identity sources that can be selected in a Config.
+const (
+	SourceOS    = "os"
+	SourceFiles = "files"
+	SourceJSON  = "json"
+)
+
+const (
+	defaultPasswd      = "/etc/passwd"
+	defaultGroup       = "/etc/group"
+	defaultPositiveTTL = time.Hour
+	defaultNegativeTTL = time.Minute
+)
+
+var (
+	ErrInvalidSource = errors.New("invalid identity source")
+	ErrNotFound      = errors.New("identity not found")
+)
+
+// Config selects the Provider used to resolve users and groups.
+//
+// Source is one of "os" (the default), which uses NSS via the os/user package;
+// "files", which reads the Passwd and Group files (defaulting to /etc/passwd and
+// /etc/group); or "json", which reads the JSON file in the JSONMapping format.
+// The files are reloaded when they change.
+//
+// PositiveTTL and NegativeTTL are the number of seconds that found and missing
+// users and groups are cached for, defaulting to an hour and a minute.
+type Config struct {
+	Source      string
+	Passwd      string
+	Group       string
+	JSON        string
+	PositiveTTL uint64
+	NegativeTTL uint64
+}
+
+// Provider resolves users and groups.
+type Provider interface {
+	// Username returns the name of the user with the given UID.
+	Username(uid uint32) (string, error)
+
+	// Group returns the name of the group with the given GID.
+	Group(gid uint32) (string, error)
+
+	// IDs returns the UID and GIDs of the named user.
+	IDs(username string) (uint32, []uint32, error)
+}
+
+// Lister is a Provider that can list all of its users and groups, which allows
+// their names to be preloaded in bulk.
+type Lister interface {
+	Provider
+
+	// Names returns the names of all users and groups, keyed by ID.
+	Names() (users map[uint32]string, groups map[uint32]string, err error)
+}
+
+var (
+	providerMu    sync.RWMutex        //nolint:gochecknoglobals
+	provider      Provider     = OS{} //nolint:gochecknoglobals
+	currentConfig Config              //nolint:gochecknoglobals
+	positiveNanos atomic.Int64        //nolint:gochecknoglobals
+	negativeNanos atomic.Int64        //nolint:gochecknoglobals
+)
+
+func init() { //nolint:gochecknoinits
+	positiveNanos.Store(int64(defaultPositiveTTL))
+	negativeNanos.Store(int64(defaultNegativeTTL))
+}
+
+func positiveTTL() time.Duration {
+	return time.Duration(positiveNanos.Load())
+}
+
+func negativeTTL() time.Duration {
+	return time.Duration(negativeNanos.Load())
+}
+
+func getProvider() Provider { //nolint:ireturn
+	providerMu.RLock()
+	defer providerMu.RUnlock()
+
+	return provider
+}
+
+// Configure sets the Provider used by Username, Group and GetIDs, along with the
+// cache TTLs, clearing the caches. If the provider is a Lister, all user and
+// group names are preloaded.
+//
+// Configuring with the current config does nothing.
+func Configure(c Config) error {
+	apply, err := Prepare(c)
+	if err != nil {
+		return err
+	}
+
+	apply()
+
+	return nil
+}
+
+// Prepare creates the Provider for the given config, preloading the names from
+// a Lister, and returns a function that will make it the Provider used by
+// Username, Group and GetIDs, as Configure does.
+//
+// This allows the config to be checked before any changes are made.
+func Prepare(c Config) (func(), error) {
+	providerMu.RLock()
+	unchanged := c == currentConfig
+	providerMu.RUnlock()
+
+	if unchanged {
+		return func() {}, nil
+	}
+
+	p, err := newProvider(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var names, groupNames map[uint32]string
+
+	if l, ok := p.(Lister); ok {
+		if names, groupNames, err = l.Names(); err != nil {
+			return nil, err
+		}
+	}
+
+	return func() { setProvider(c, p, names, groupNames) }, nil
+}
+
+func setProvider(c Config, p Provider, names, groupNames map[uint32]string) {
+	providerMu.Lock()
+	defer providerMu.Unlock()
+
+	positiveNanos.Store(int64(ttlOrDefault(c.PositiveTTL, defaultPositiveTTL)))
+	negativeNanos.Store(int64(ttlOrDefault(c.NegativeTTL, defaultNegativeTTL)))
+
+	provider = p
+	currentConfig = c
+
+	userCache.reset()
+	groupCache.reset()
+	userGroupsCache.reset()
+
+	for uid, name := range names {
+		userCache.Set(uid, name)
+	}
+
+	for gid, name := range groupNames {
+		groupCache.Set(gid, name)
+	}
+}
+
+func newProvider(c Config) (Provider, error) { //nolint:ireturn
+	switch c.Source {
+	case "", SourceOS:
+		return OS{}, nil
+	case SourceFiles:
+		return NewFiles(orDefault(c.Passwd, defaultPasswd), orDefault(c.Group, defaultGroup)), nil
+	case SourceJSON:
+		return NewJSON(c.JSON), nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrInvalidSource, c.Source)
+}
+
+func orDefault(path, def string) string {
+	if path == "" {
+		return def
+	}
+
+	return path
+}
+
+func ttlOrDefault(seconds uint64, def time.Duration) time.Duration {
+	if seconds == 0 {
+		return def
+	}
+
+	return time.Duration(seconds) * time.Second //nolint:gosec
+}
+
+// OS is a Provider that uses the os/user package.
+type OS struct{}
+
+// Username implements Provider.
+func (OS) Username(uid uint32) (string, error) {
+	u, err := user.LookupId(strconv.FormatUint(uint64(uid), 10))
+	if err != nil {
+		return "", err
+	}
+
+	return u.Username, nil
+}
+
+// Group implements Provider.
+func (OS) Group(gid uint32) (string, error) {
+	g, err := user.LookupGroupId(strconv.FormatUint(uint64(gid), 10))
+	if err != nil {
+		return "", err
+	}
+
+	return g.Name, nil
+}
+
+// IDs implements Provider.
+func (OS) IDs(username string) (uint32, []uint32, error) {
+	u, err := user.Lookup(username)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	gids, err := u.GroupIds()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	gs := make([]uint32, 0, len(gids))
+
+	for _, gid := range gids {
+		g, err := strconv.ParseUint(gid, 10, 32)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		gs = append(gs, uint32(g))
+	}
+
+	return uint32(uid), gs, nil
+}
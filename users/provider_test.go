@@ -0,0 +1,164 @@
+/*******************************************************************************
+ * Copyright (c) 2026 Genome Research Ltd.
+ *
+ * Author: Michael Woolnough <mw31@sanger.ac.uk>
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining
+ * a copy of this software and associated documentation files (the
+ * "Software"), to deal in the Software without restriction, including
+ * without limitation the rights to use, copy, modify, merge, publish,
+ * distribute, sublicense, and/or sell copies of the Software, and to
+ * permit persons to whom the Software is furnished to do so, subject to
+ * the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+ * EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+ * MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT.
+ * IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY
+ * CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT,
+ * TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN CONNECTION WITH THE
+ * SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+ ******************************************************************************/
+
+package users
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestProviders(t *testing.T) {
+	Convey("Given passwd and group files", t, func() {
+		dir := t.TempDir()
+		passwd := filepath.Join(dir, "passwd")
+		group := filepath.Join(dir, "group")
+
+		writeFile(t, passwd, "# comment\nuserA:x:1001:2001::/home/userA:/bin/sh\nuserB:x:1002:2002::/:/bin/false\n")
+		writeFile(t, group, "groupA:x:2001:\ngroupB:x:2002:userA\ngroupC:x:2003:userA,userB\n")
+
+		p := NewFiles(passwd, group)
+
+		Convey("You can look up users and groups", func() {
+			name, err := p.Username(1001)
+			So(err, ShouldBeNil)
+			So(name, ShouldEqual, "userA")
+
+			name, err = p.Group(2003)
+			So(err, ShouldBeNil)
+			So(name, ShouldEqual, "groupC")
+
+			uid, gids, err := p.IDs("userA")
+			So(err, ShouldBeNil)
+			So(uid, ShouldEqual, 1001)
+			So(gids, ShouldResemble, []uint32{2001, 2002, 2003})
+
+			_, err = p.Username(1003)
+			So(err, ShouldWrap, ErrNotFound)
+
+			_, _, err = p.IDs("userC")
+			So(err, ShouldWrap, ErrNotFound)
+		})
+
+		Convey("Changes to the files are seen", func() {
+			_, err := p.Username(1001)
+			So(err, ShouldBeNil)
+
+			writeFile(t, passwd, "userC:x:1003:2001::/:/bin/sh\n")
+			So(os.Chtimes(passwd, time.Time{}, time.Now().Add(time.Minute)), ShouldBeNil)
+
+			name, err := p.Username(1003)
+			So(err, ShouldBeNil)
+			So(name, ShouldEqual, "userC")
+
+			_, err = p.Username(1001)
+			So(err, ShouldWrap, ErrNotFound)
+		})
+
+		Convey("The last good files are used if they become invalid", func() {
+			_, err := p.Username(1001)
+			So(err, ShouldBeNil)
+
+			writeFile(t, passwd, "invalid\n")
+			So(os.Chtimes(passwd, time.Time{}, time.Now().Add(time.Minute)), ShouldBeNil)
+
+			name, err := p.Username(1001)
+			So(err, ShouldBeNil)
+			So(name, ShouldEqual, "userA")
+		})
+
+		Convey("Configuring the files source preloads all names and caches missing lookups", func() {
+			So(Configure(Config{Source: SourceFiles, Passwd: passwd, Group: group, NegativeTTL: 3600}), ShouldBeNil)
+
+			Reset(func() { So(Configure(Config{}), ShouldBeNil) })
+
+			u, ok := userCache.Get(1002)
+			So(ok, ShouldBeTrue)
+			So(u, ShouldEqual, "userB")
+
+			g, ok := groupCache.Get(2002)
+			So(ok, ShouldBeTrue)
+			So(g, ShouldEqual, "groupB")
+
+			uid, gids := GetIDs("userB")
+			So(uid, ShouldEqual, 1002)
+			So(gids, ShouldResemble, []uint32{2002, 2003})
+
+			So(Username(1004), ShouldEqual, "1004")
+
+			writeFile(t, passwd, "userD:x:1004:2001::/:/bin/sh\n")
+			So(os.Chtimes(passwd, time.Time{}, time.Now().Add(time.Minute)), ShouldBeNil)
+
+			So(Username(1004), ShouldEqual, "1004")
+		})
+	})
+
+	Convey("Given a JSON mapping file", t, func() {
+		path := filepath.Join(t.TempDir(), "ids.json")
+
+		writeFile(t, path, `{
+	"users": [{"name": "userA", "uid": 1001, "gids": [2001, 2002]}],
+	"groups": [{"name": "groupA", "gid": 2001}, {"name": "groupB", "gid": 2002}]
+}`)
+
+		p := NewJSON(path)
+
+		Convey("You can look up users and groups", func() {
+			name, err := p.Username(1001)
+			So(err, ShouldBeNil)
+			So(name, ShouldEqual, "userA")
+
+			name, err = p.Group(2002)
+			So(err, ShouldBeNil)
+			So(name, ShouldEqual, "groupB")
+
+			uid, gids, err := p.IDs("userA")
+			So(err, ShouldBeNil)
+			So(uid, ShouldEqual, 1001)
+			So(gids, ShouldResemble, []uint32{2001, 2002})
+
+			users, groups, err := p.Names()
+			So(err, ShouldBeNil)
+			So(users, ShouldResemble, map[uint32]string{1001: "userA"})
+			So(groups, ShouldResemble, map[uint32]string{2001: "groupA", 2002: "groupB"})
+		})
+	})
+
+	Convey("Configuring an invalid source or unreadable files fails", t, func() {
+		So(Configure(Config{Source: "ldap"}), ShouldWrap, ErrInvalidSource)
+		So(Configure(Config{Source: SourceJSON, JSON: "/non/existent"}), ShouldNotBeNil)
+		So(getProvider(), ShouldResemble, OS{})
+	})
+}
+
+func writeFile(t *testing.T, path, contents string) {
+	t.Helper()
+
+	So(os.WriteFile(path, []byte(contents), 0600), ShouldBeNil)
+}
@@ -26,35 +26,57 @@
 package users
 
 import (
-	"os/user"
 	"strconv"
 	"sync"
+	"time"
 )
 
+type entry[V any] struct {
+	value  V
+	expiry time.Time
+}
+
 type muMap[K comparable, V any] struct {
 	mu sync.RWMutex
-	m  map[K]V
+	m  map[K]entry[V]
 }
 
 func (m *muMap[K, V]) Get(key K) (V, bool) { //nolint:ireturn,nolintlint
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	v, ok := m.m[key]
+	e, ok := m.m[key]
+	if !ok || e.expiry.Before(time.Now()) {
+		var v V
 
-	return v, ok
+		return v, false
+	}
+
+	return e.value, true
 }
 
+// Set stores the value for the key until the positive TTL expires.
 func (m *muMap[K, V]) Set(key K, value V) {
+	m.setFor(key, value, positiveTTL())
+}
+
+func (m *muMap[K, V]) setFor(key K, value V, ttl time.Duration) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.m[key] = value
+	m.m[key] = entry[V]{value: value, expiry: time.Now().Add(ttl)}
+}
+
+func (m *muMap[K, V]) reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	clear(m.m)
 }
 
 func makeMuMap[K comparable, V any]() *muMap[K, V] {
 	return &muMap[K, V]{
-		m: make(map[K]V),
+		m: make(map[K]entry[V]),
 	}
 }
 
@@ -71,14 +93,18 @@ func Username(uid uint32) string {
 		return u
 	}
 
-	u, err := user.LookupId(strconv.FormatUint(uint64(uid), 10))
+	u, err := getProvider().Username(uid)
 	if err != nil {
-		return strconv.FormatUint(uint64(uid), 10)
+		u = strconv.FormatUint(uint64(uid), 10)
+
+		userCache.setFor(uid, u, negativeTTL())
+
+		return u
 	}
 
-	userCache.Set(uid, u.Username)
+	userCache.Set(uid, u)
 
-	return u.Username
+	return u
 }
 
 // Group returns the group name assigned to the given GID.
@@ -89,12 +115,16 @@ func Group(gid uint32) string {
 		return g
 	}
 
-	g, err := user.LookupGroupId(strconv.FormatUint(uint64(gid), 10))
+	g, err := getProvider().Group(gid)
 	if err != nil {
-		return strconv.FormatUint(uint64(gid), 10)
+		g = strconv.FormatUint(uint64(gid), 10)
+
+		groupCache.setFor(gid, g, negativeTTL())
+
+		return g
 	}
 
-	groupCache.Set(gid, g.Name)
+	groupCache.Set(gid, g)
 
-	return g.Name
+	return g
 }
@@ -59,6 +59,19 @@ func (c *Config) client() (*gas.ClientCLI, error) {
 	)
 }
 
+// Check creates a client from the config and, if connect is true, makes a query
+// of the server to check it can be reached.
+func (c *Config) Check(connect bool) error {
+	client, err := c.client()
+	if err != nil || !connect {
+		return err
+	}
+
+	_, _, err = server.GetWhereDataIs(client, "/", "", "", "", 0, "0")
+
+	return err
+}
+
 type Client struct {
 	mu     sync.RWMutex
 	client *gas.ClientCLI
@@ -81,18 +94,31 @@ func New(d time.Duration, cfg Config) (*Client, error) {
 
 // UpdateConfig updates the WRStat client to use the new config specified.
 func (c *Client) UpdateConfig(cfg Config) error {
-	client, err := cfg.client()
+	apply, err := c.PrepareConfig(cfg)
 	if err != nil {
 		return err
 	}
 
-	c.mu.Lock()
-	c.client = client
-	c.mu.Unlock()
+	apply()
 
 	return nil
 }
 
+// PrepareConfig creates a client for the new config specified, returning a
+// function that will update the WRStat client to use it.
+func (c *Client) PrepareConfig(cfg Config) (func(), error) {
+	client, err := cfg.client()
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		c.mu.Lock()
+		c.client = client
+		c.mu.Unlock()
+	}, nil
+}
+
 func (c *Client) getWRStatModTime(path string) (time.Time, error) {
 	c.mu.RLock()
 	client := c.client